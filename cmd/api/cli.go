@@ -0,0 +1,63 @@
+package main
+
+import (
+	"blockchain-asset-api/internal/service"
+	"blockchain-asset-api/internal/util"
+	"flag"
+	"fmt"
+)
+
+// 执行命令行子命令，返回进程退出码
+//
+//	gaps     -from N -to M   列出缺失的区块区段
+//	backfill -from N -to M   回填缺失的区块区段
+func runCommand(args []string) int {
+	switch args[0] {
+	case "gaps":
+		return runGaps(args[1:], false)
+	case "backfill":
+		return runGaps(args[1:], true)
+	default:
+		fmt.Printf("未知命令: %s\n可用命令: gaps, backfill\n", args[0])
+		return 2
+	}
+}
+
+// 查找缺失区块，backfill 为 true 时通过扫描器回填
+func runGaps(args []string, backfill bool) int {
+	fs := flag.NewFlagSet("gaps", flag.ContinueOnError)
+	from := fs.Int64("from", -1, "起始区块号（不指定则为数据库中最早的区块）")
+	to := fs.Int64("to", 0, "结束区块号（不指定则为数据库中最新区块）")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	gaps, err := service.FindBlockGaps(*from, *to)
+	if err != nil {
+		util.Log.Errorf("查询缺失区块失败: %v", err)
+		return 1
+	}
+
+	for _, gap := range gaps {
+		fmt.Printf("%d-%d\t%d\n", gap.From, gap.To, gap.To-gap.From+1)
+	}
+	fmt.Printf("共 %d 个区段，缺失 %d 个区块\n", len(gaps), service.CountGapBlocks(gaps))
+
+	if !backfill || len(gaps) == 0 {
+		return 0
+	}
+
+	scanner := service.NewBlockScanner()
+	defer scanner.Close()
+	if err := scanner.StartBackfill(gaps); err != nil {
+		util.Log.Errorf("启动区块回填失败: %v", err)
+		return 1
+	}
+	scanner.Wait(service.ScanSlotRange)
+
+	if status := scanner.Status(service.ScanSlotRange); status.State != service.ScanStateCompleted {
+		util.Log.Errorf("区块回填未完成: state=%s, err=%s", status.State, status.LastError)
+		return 1
+	}
+	return 0
+}
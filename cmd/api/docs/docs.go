@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/address/{addr}/balance": {
+        "/abi/{address}": {
             "get": {
-                "description": "根据以太坊地址查询ETH余额",
+                "description": "查询合约登记的 ABI",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,14 +25,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "balance"
+                    "contract"
                 ],
-                "summary": "查询ETH余额",
+                "summary": "查询合约 ABI",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "以太坊地址",
-                        "name": "addr",
+                        "description": "合约地址",
+                        "name": "address",
                         "in": "path",
                         "required": true
                     }
@@ -41,30 +41,61 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handler.Response"
                         }
+                    }
+                }
+            }
+        },
+        "/address/{addr}/approvals": {
+            "get": {
+                "description": "分页查询地址当前有效的 ERC20 授权和 ApprovalForAll 授权，按最近授权的区块号倒序。每个授权通过 eth_call 查询当前的 allowance / isApprovedForAll，并标记无限授权",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "address"
+                ],
+                "summary": "查询地址的代币授权",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "以太坊地址",
+                        "name": "addr",
+                        "in": "path",
+                        "required": true
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handler.Response"
                         }
                     }
                 }
             }
         },
-        "/address/{addr}/tokens": {
+        "/address/{addr}/balance": {
             "get": {
-                "description": "根据地址和合约地址查询ERC20代币余额",
+                "description": "根据以太坊地址查询ETH余额",
                 "consumes": [
                     "application/json"
                 ],
@@ -74,7 +105,7 @@ const docTemplate = `{
                 "tags": [
                     "balance"
                 ],
-                "summary": "查询ERC20代币余额",
+                "summary": "查询ETH余额",
                 "parameters": [
                     {
                         "type": "string",
@@ -83,12 +114,17 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "integer",
+                        "description": "查询该区块时的余额（需要归档节点）",
+                        "name": "block",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "ERC20合约地址",
-                        "name": "contract",
-                        "in": "query",
-                        "required": true
+                        "description": "查询该时刻的余额：Unix 秒、RFC3339 或 2006-01-02（UTC），与 block 二选一",
+                        "name": "timestamp",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -116,9 +152,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/block/{blocknum}": {
+        "/address/{addr}/internal-transactions": {
             "get": {
-                "description": "根据区块号查询区块详细信息",
+                "description": "分页查询地址作为发送方或接收方的内部交易（合约调用中的 ETH 转移、合约创建和自毁），需要开启 scanner.traceMode",
                 "consumes": [
                     "application/json"
                 ],
@@ -126,46 +162,97 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "block"
+                    "transaction"
                 ],
-                "summary": "查询区块信息",
+                "summary": "查询地址的内部交易",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "区块号",
-                        "name": "blocknum",
+                        "description": "以太坊地址",
+                        "name": "addr",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handler.Response"
                         }
+                    }
+                }
+            }
+        },
+        "/address/{addr}/nft-transfers": {
+            "get": {
+                "description": "分页查询地址作为发送方或接收方的 ERC-721 / ERC-1155 转移记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nft"
+                ],
+                "summary": "查询地址的NFT转移记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "以太坊地址",
+                        "name": "addr",
+                        "in": "path",
+                        "required": true
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    {
+                        "type": "string",
+                        "description": "NFT合约地址",
+                        "name": "contract",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handler.Response"
                         }
                     }
                 }
             }
         },
-        "/scan": {
+        "/address/{addr}/portfolio": {
             "get": {
-                "description": "从指定区块开始扫描并将数据存储到数据库",
+                "description": "通过 Multicall3 在一次 eth_call 中查询 ETH 余额和多个ERC20代币余额，未指定 tokens 时使用地址在已索引转移记录中出现过的代币",
                 "consumes": [
                     "application/json"
                 ],
@@ -173,14 +260,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scan"
+                    "balance"
                 ],
-                "summary": "扫描区块",
+                "summary": "查询地址的代币组合",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "起始区块号（不指定则从数据库最新区块+1开始）",
-                        "name": "from_block",
+                        "type": "string",
+                        "description": "以太坊地址",
+                        "name": "addr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "逗号分隔的ERC20合约地址",
+                        "name": "tokens",
                         "in": "query"
                     }
                 ],
@@ -188,23 +282,73 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.Portfolio"
+                                        }
+                                    }
+                                }
+                            ]
                         }
+                    }
+                }
+            }
+        },
+        "/address/{addr}/swaps": {
+            "get": {
+                "description": "分页查询地址参与的 Uniswap V2 / V3 兑换（地址为交易发起方、调用池子的地址或接收方），按区块号倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "address"
+                ],
+                "summary": "查询地址的 DEX 兑换",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "以太坊地址",
+                        "name": "addr",
+                        "in": "path",
+                        "required": true
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handler.Response"
                         }
                     }
                 }
             }
         },
-        "/transaction/{txhash}": {
+        "/address/{addr}/tokens": {
             "get": {
-                "description": "根据交易哈希查询交易详细信息",
+                "description": "根据地址和合约地址查询ERC20代币余额",
                 "consumes": [
                     "application/json"
                 ],
@@ -212,16 +356,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "balance"
                 ],
-                "summary": "查询交易详情",
+                "summary": "查询ERC20代币余额",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "交易哈希",
-                        "name": "txhash",
+                        "description": "以太坊地址",
+                        "name": "addr",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ERC20合约地址",
+                        "name": "contract",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "查询该区块时的余额（需要归档节点）",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询该时刻的余额：Unix 秒、RFC3339 或 2006-01-02（UTC），与 block 二选一",
+                        "name": "timestamp",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -248,6 +411,1720 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/address/{addr}/withdrawals": {
+            "get": {
+                "description": "分页查询地址收到的信标链提款（上海升级后），提款不经过交易直接增加地址余额",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "address"
+                ],
+                "summary": "查询地址的信标链提款",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "以太坊地址",
+                        "name": "addr",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/abi/{address}": {
+            "post": {
+                "description": "登记（或覆盖）合约的 ABI，之后扫描到的该合约事件日志按 ABI 解码，已保存的日志在后台重新解码",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contract"
+                ],
+                "summary": "登记合约 ABI",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "合约地址",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "合约名称和 ABI（JSON 数组）",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RegisterABIRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan": {
+            "post": {
+                "description": "从指定区块开始扫描并将数据存储到数据库，同一时间只允许一个区间扫描或回填任务；follow=true 时启动跟随链头的扫描，可与区间扫描同时运行",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "扫描区块",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "起始区块号（不指定则从数据库最新区块+1开始）",
+                        "name": "from_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束区块号（不指定则扫描到已确认的最新区块），可与 from_block 配合重新索引指定区间",
+                        "name": "to_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "为 true 时启动跟随链头的扫描，忽略 from_block / to_block",
+                        "name": "follow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan/backfill": {
+            "post": {
+                "description": "查找指定区间内缺失的区块区段，并通过扫描器只回填这些区段",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "回填缺失的区块",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "起始区块号（不指定则为数据库中最早的区块，数据库为空时必须指定）",
+                        "name": "from_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束区块号（不指定则为数据库中最新区块）",
+                        "name": "to_block",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan/failed": {
+            "get": {
+                "description": "分页查询重试队列中的失败区块，status=stuck 时只返回超过最大重试次数的区块",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "查询扫描失败的区块",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "状态：pending / stuck",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan/gaps": {
+            "get": {
+                "description": "对比 blocks 表与指定区间，返回缺失的区块区段",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "查询缺失的区块",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "起始区块号（不指定则为数据库中最早的区块，数据库为空时必须指定）",
+                        "name": "from_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束区块号（不指定则为数据库中最新区块）",
+                        "name": "to_block",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan/pause": {
+            "post": {
+                "description": "已抓取的区块提交完后暂停，不再分发新区块",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "暂停扫描任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "range",
+                        "description": "任务槽：range / follow",
+                        "name": "job",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan/reorgs": {
+            "get": {
+                "description": "分页查询扫描过程中发现并回滚的链重组，按发现时间倒序，total 为累计发生的链重组次数",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "查询链重组事件",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan/resume": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "恢复扫描任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "range",
+                        "description": "任务槽：range / follow",
+                        "name": "job",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan/status": {
+            "get": {
+                "description": "返回当前（或最近一次）扫描任务的当前区块、目标区块、每秒区块数、预计剩余时间和最近错误",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "查询扫描任务状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "range",
+                        "description": "任务槽：range（区间扫描和回填）/ follow（跟随链头）",
+                        "name": "job",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ScanStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/scan/stop": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scan"
+                ],
+                "summary": "停止扫描任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "range",
+                        "description": "任务槽：range / follow",
+                        "name": "job",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/block/by-time": {
+            "get": {
+                "description": "查询时间戳对应的区块，区间已索引时查询 blocks 表，否则通过区块头二分查找",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "block"
+                ],
+                "summary": "按时间戳查询区块",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "时间：Unix 秒、RFC3339 或 2006-01-02（UTC）",
+                        "name": "ts",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "before：不晚于 ts 的最后一个区块（默认）；after：不早于 ts 的第一个区块",
+                        "name": "closest",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.BlockByTime"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/block/{blocknum}": {
+            "get": {
+                "description": "根据区块号查询区块详细信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "block"
+                ],
+                "summary": "查询区块信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "区块号",
+                        "name": "blocknum",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/block/{blocknum}/withdrawals": {
+            "get": {
+                "description": "按提款序号返回已索引区块中的信标链提款",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "block"
+                ],
+                "summary": "查询区块的信标链提款",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "区块号",
+                        "name": "blocknum",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/contract/{address}": {
+            "get": {
+                "description": "查询已索引合约的部署地址、创建交易、区块号、字节码哈希和检测到的代币标准（erc20 / erc721 / erc1155）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contract"
+                ],
+                "summary": "查询合约信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "合约地址",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/contract/{address}/events": {
+            "get": {
+                "description": "查询合约的事件日志，可按事件名、topic 和 indexed 参数（args[参数名]=值）筛选",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contract"
+                ],
+                "summary": "查询合约的事件日志",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "合约地址",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件名，如 Transfer",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "按 indexed 参数筛选，如 args[from]=0x...",
+                        "name": "args[name]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "起始区块号",
+                        "name": "from_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束区块号",
+                        "name": "to_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/logs": {
+            "get": {
+                "description": "按合约、事件名、topic 和 indexed 参数查询事件日志，合约登记了 ABI 的日志带有解码后的事件名和参数。\n按参数筛选使用 args[参数名]=值，需要同时指定 address 和 event；string / bytes 参数按 keccak256 匹配",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contract"
+                ],
+                "summary": "查询事件日志",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "合约地址",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件名，如 Transfer",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "topic0（事件签名哈希）",
+                        "name": "topic0",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "topic1",
+                        "name": "topic1",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "topic2",
+                        "name": "topic2",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "topic3",
+                        "name": "topic3",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "按 indexed 参数筛选，如 args[from]=0x...",
+                        "name": "args[name]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "起始区块号",
+                        "name": "from_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "结束区块号",
+                        "name": "to_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/nft/{contract}/tokens/{token_id}/transfers": {
+            "get": {
+                "description": "分页查询指定合约和代币 ID 的转移记录，即该 NFT 的持有历史",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nft"
+                ],
+                "summary": "查询单个NFT的转移记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "NFT合约地址",
+                        "name": "contract",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "代币 ID（十进制或 0x 开头的十六进制）",
+                        "name": "token_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/nft/{contract}/transfers": {
+            "get": {
+                "description": "分页查询 NFT 合约的转移记录，可按代币 ID 筛选",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "nft"
+                ],
+                "summary": "查询NFT合约的转移记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "NFT合约地址",
+                        "name": "contract",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "代币 ID（十进制或 0x 开头的十六进制）",
+                        "name": "token_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/pool/{address}": {
+            "get": {
+                "description": "查询已索引的交易池：协议版本、代币对、V3 手续费率，V2 池带有最近一次 Sync 事件同步的储备量",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dex"
+                ],
+                "summary": "查询 DEX 交易池",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "交易池地址",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PoolInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/pool/{address}/liquidity": {
+            "get": {
+                "description": "分页查询交易池的添加流动性（mint）、移除流动性（burn）和 V2 储备量同步（sync）事件，按区块号倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dex"
+                ],
+                "summary": "查询交易池的流动性事件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "交易池地址",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件类型：mint / burn / sync",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/pool/{address}/swaps": {
+            "get": {
+                "description": "分页查询交易池的兑换记录，按区块号倒序",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dex"
+                ],
+                "summary": "查询交易池的兑换记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "交易池地址",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/transaction/{txhash}": {
+            "get": {
+                "description": "根据交易哈希查询交易详细信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "查询交易详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "交易哈希",
+                        "name": "txhash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/transaction/{txhash}/internal": {
+            "get": {
+                "description": "按调用顺序返回已索引交易中的内部交易，需要开启 scanner.traceMode",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "查询交易的内部交易",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "交易哈希",
+                        "name": "txhash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/transaction/{txhash}/transfers": {
+            "get": {
+                "description": "返回已索引交易中每条 Transfer 日志对应的ERC20转移记录，以及NFT转移记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "查询交易内的全部代币转移",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "交易哈希",
+                        "name": "txhash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/handler.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.TransactionTransfers"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "get": {
+                "description": "获取扫描到的交易列表，支持分页和筛选。按地址筛选且未指定交易类型时，withdrawals 中同时返回地址收到的信标链提款，提款按 withdrawal_page 单独分页",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "获取交易列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "交易类型或交易标签：eth_transfer / erc20_transfer / nft_transfer / contract_call / weth_wrap / weth_unwrap / token_mint / token_burn / approval / nft_mint",
+                        "name": "tx_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "地址筛选",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "区块号",
+                        "name": "block_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "提款页码",
+                        "name": "withdrawal_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TransactionListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handler.RegisterABIRequest": {
+            "type": "object",
+            "required": [
+                "abi"
+            ],
+            "properties": {
+                "abi": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "0: 成功，非0: 失败",
+                    "type": "integer"
+                },
+                "data": {},
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TransactionListResponse": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "pages": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.TransactionResponse"
+                    }
+                },
+                "withdrawal_page": {
+                    "type": "integer"
+                },
+                "withdrawal_pages": {
+                    "type": "integer"
+                },
+                "withdrawal_total": {
+                    "type": "integer"
+                },
+                "withdrawals": {
+                    "description": "按地址筛选且未指定交易类型时，地址收到的信标链提款（按 withdrawal_page 单独分页，每页数量与交易列表相同），没有提款时省略",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Withdrawal"
+                    }
+                }
+            }
+        },
+        "handler.TransactionResponse": {
+            "type": "object",
+            "properties": {
+                "access_list": {
+                    "description": "EIP-2930 访问列表",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "blob_gas_price": {
+                    "type": "string"
+                },
+                "blob_gas_used": {
+                    "type": "integer"
+                },
+                "blob_hashes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "block_number": {
+                    "type": "integer"
+                },
+                "burnt_fees": {
+                    "type": "string"
+                },
+                "confirmations": {
+                    "description": "当前确认数",
+                    "type": "integer"
+                },
+                "contract_address": {
+                    "description": "合约创建交易部署的合约地址",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "effective_gas_price": {
+                    "description": "回执中实际成交的 gas 单价",
+                    "type": "string"
+                },
+                "envelope_type": {
+                    "type": "integer"
+                },
+                "erc20_amount": {
+                    "type": "string"
+                },
+                "erc20_formatted_amount": {
+                    "description": "按小数位数换算后的ERC20转账金额和代币符号",
+                    "type": "string"
+                },
+                "erc20_symbol": {
+                    "type": "string"
+                },
+                "erc20_transfers": {
+                    "description": "交易内全部ERC20转移记录",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ERC20Transfer"
+                    }
+                },
+                "fee": {
+                    "description": "实际支付的手续费（含 blob 费用）和其中销毁的部分",
+                    "type": "string"
+                },
+                "from_address": {
+                    "type": "string"
+                },
+                "gas_limit": {
+                    "type": "integer"
+                },
+                "gas_price": {
+                    "description": "legacy 和 access list 交易签名的 gasPrice",
+                    "type": "string"
+                },
+                "gas_used": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "labels": {
+                    "description": "交易标签，如 weth_wrap、token_mint、approval、nft_mint",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max_fee_per_blob_gas": {
+                    "description": "blob 交易（类型 3）的 blob gas 字段",
+                    "type": "string"
+                },
+                "max_fee_per_gas": {
+                    "description": "EIP-1559 及之后的交易类型签名的费用上限和小费上限",
+                    "type": "string"
+                },
+                "max_priority_fee_per_gas": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "to_address": {
+                    "type": "string"
+                },
+                "tx_hash": {
+                    "type": "string"
+                },
+                "tx_type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ERC20Transfer": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "block_number": {
+                    "type": "integer"
+                },
+                "contract_address": {
+                    "type": "string"
+                },
+                "decimals": {
+                    "type": "integer"
+                },
+                "formatted_amount": {
+                    "type": "string"
+                },
+                "from_address": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "log_index": {
+                    "type": "integer"
+                },
+                "symbol": {
+                    "description": "代币元数据，查询时根据 tokens 表填充",
+                    "type": "string"
+                },
+                "to_address": {
+                    "type": "string"
+                },
+                "tx_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.NFTTransfer": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "batch_index": {
+                    "type": "integer"
+                },
+                "block_number": {
+                    "type": "integer"
+                },
+                "contract_address": {
+                    "type": "string"
+                },
+                "from_address": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "log_index": {
+                    "type": "integer"
+                },
+                "operator": {
+                    "type": "string"
+                },
+                "standard": {
+                    "type": "string"
+                },
+                "to_address": {
+                    "type": "string"
+                },
+                "token_id": {
+                    "type": "string"
+                },
+                "tx_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Withdrawal": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "string"
+                },
+                "block_number": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "validator_index": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.BlockByTime": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer"
+                },
+                "block_timestamp": {
+                    "type": "integer"
+                },
+                "closest": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "查询的时间戳（Unix 秒）",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.PoolInfo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "fee": {
+                    "type": "integer"
+                },
+                "protocol": {
+                    "type": "string"
+                },
+                "reserve0": {
+                    "type": "string"
+                },
+                "reserve1": {
+                    "type": "string"
+                },
+                "reserve_block": {
+                    "type": "integer"
+                },
+                "token0": {
+                    "type": "string"
+                },
+                "token0_symbol": {
+                    "type": "string"
+                },
+                "token1": {
+                    "type": "string"
+                },
+                "token1_symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Portfolio": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "eth_balance": {
+                    "type": "string"
+                },
+                "failed_tokens": {
+                    "description": "balanceOf 调用失败的合约（非 ERC20 或已自毁）",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tokens": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PortfolioToken"
+                    }
+                }
+            }
+        },
+        "service.PortfolioToken": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "最小单位的原始余额",
+                    "type": "string"
+                },
+                "contract_address": {
+                    "type": "string"
+                },
+                "decimals": {
+                    "type": "integer"
+                },
+                "formatted_balance": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ScanStatus": {
+            "type": "object",
+            "properties": {
+                "blocks_per_second": {
+                    "type": "number"
+                },
+                "current_block": {
+                    "description": "最近处理的区块号",
+                    "type": "integer"
+                },
+                "eta_seconds": {
+                    "description": "预计剩余秒数，-1 表示未知",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "from_block": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "string"
+                },
+                "scanned_blocks": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "target_block": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.TransactionTransfers": {
+            "type": "object",
+            "properties": {
+                "erc20_transfers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ERC20Transfer"
+                    }
+                },
+                "nft_transfers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NFTTransfer"
+                    }
+                },
+                "tx_hash": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -3,6 +3,9 @@
 // @description 区块链资产查询API
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
 package main
 
 import (
@@ -11,15 +14,26 @@ import (
 	"blockchain-asset-api/internal/handler"
 	"blockchain-asset-api/internal/repository"
 	"blockchain-asset-api/internal/util"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"golang.org/x/time/rate"
+	"io"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -56,6 +70,90 @@ func rateLimitMiddleware() gin.HandlerFunc {
 	}
 }
 
+// HMAC 签名校验时读取的请求体上限，足够容纳较大的合约 ABI
+const maxAdminBodySize = 4 << 20
+
+// 管理接口鉴权：支持静态令牌或 HMAC-SHA256 签名，未配置任何凭证时拒绝所有请求
+func adminAuthMiddleware() gin.HandlerFunc {
+	token := config.Cfg.Admin.Token
+	secret := config.Cfg.Admin.HMACSecret
+	if token == "" && secret == "" {
+		util.Log.Warn("未配置 admin.token 或 admin.hmacSecret，管理接口将拒绝所有请求")
+	}
+
+	return func(c *gin.Context) {
+		reason := checkAdminAuth(c, token, secret)
+		if reason == "" {
+			c.Next()
+			return
+		}
+
+		util.Log.Warnf("管理接口鉴权失败: ip=%s, method=%s, path=%s, reason=%s",
+			c.ClientIP(), c.Request.Method, c.Request.URL.RequestURI(), reason)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "未授权的管理请求",
+		})
+		c.Abort()
+	}
+}
+
+// 校验管理请求的凭证，通过时返回空字符串，否则返回失败原因
+func checkAdminAuth(c *gin.Context, token, secret string) string {
+	// 1. 静态令牌
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		if token == "" {
+			return "未启用令牌鉴权"
+		}
+		provided := strings.TrimPrefix(auth, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return "令牌无效"
+		}
+		return ""
+	}
+
+	// 2. HMAC 签名
+	signature := c.GetHeader("X-Admin-Signature")
+	if signature == "" {
+		return "缺少鉴权信息"
+	}
+	if secret == "" {
+		return "未启用 HMAC 鉴权"
+	}
+
+	timestamp := c.GetHeader("X-Admin-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "时间戳无效"
+	}
+	skew := time.Since(time.Unix(ts, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > config.Cfg.Admin.MaxSkew {
+		return "时间戳超出允许范围"
+	}
+
+	// 读取请求体参与签名后放回，供后续处理器使用；限制大小，避免任意调用方让服务缓存超大请求体
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxAdminBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "请求体过大"
+		}
+		return "读取请求体失败"
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + c.Request.Method + "\n" + c.Request.URL.RequestURI() + "\n"))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+		return "签名无效"
+	}
+	return ""
+}
+
 func main() {
 	// 1. 初始化配置
 	config.Init()
@@ -74,6 +172,17 @@ func main() {
 		util.Log.Fatalf("初始化 MySQL 失败: %v", err)
 	}
 
+	// 带子命令时按命令行工具运行，不启动 HTTP 服务
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	// 启动跟随链头扫描和失败区块重试
+	if config.Cfg.Scanner.Follow {
+		handler.StartFollowScan()
+	}
+	handler.StartScanRetrier()
+
 	// 4. 初始化 Gin 引擎
 	r := gin.Default()
 
@@ -110,37 +219,115 @@ func main() {
 		//查询ERC20代币余额
 		v1.GET("/address/:addr/tokens", GetErc20BalanceHandler)
 
+		// 查询地址的代币组合（Multicall3 批量查询）
+		v1.GET("/address/:addr/portfolio", handler.GetPortfolioHandler)
+
 		// 查询交易详情
 		v1.GET("/transaction/:txhash", GetTransactionHandler)
 
+		// 查询交易内的全部代币转移
+		v1.GET("/transaction/:txhash/transfers", handler.GetTransactionTransfersHandler)
+
+		// 查询内部交易（需要开启追踪模式）：按地址或交易
+		v1.GET("/address/:addr/internal-transactions", handler.GetAddressInternalTransactionsHandler)
+		v1.GET("/transaction/:txhash/internal", handler.GetTransactionInternalTransactionsHandler)
+
 		// 查询区块信息
 		v1.GET("/block/:blocknum", GetBlockHandler)
 
-		// 扫块
-		v1.GET("/scan", ScanBlock)
+		// 按时间戳查询区块
+		v1.GET("/block/by-time", handler.GetBlockByTimeHandler)
+
+		// 查询信标链提款：按地址或区块
+		v1.GET("/address/:addr/withdrawals", handler.GetAddressWithdrawalsHandler)
+		v1.GET("/block/:blocknum/withdrawals", handler.GetBlockWithdrawalsHandler)
 
 		// 获取交易列表
 		v1.GET("/transactions", handler.GetTransactionsHandler)
 
+		// 查询合约信息
+		v1.GET("/contract/:address", handler.GetContractHandler)
+
+		// 查询事件日志：全部合约或单个合约，登记了 ABI 的合约带解码结果
+		v1.GET("/logs", handler.GetLogsHandler)
+		v1.GET("/contract/:address/events", handler.GetContractEventsHandler)
+		v1.GET("/abi/:address", handler.GetABIHandler)
+
+		// 查询NFT转移记录：按地址、合约或代币 ID
+		v1.GET("/address/:addr/nft-transfers", handler.GetAddressNFTTransfersHandler)
+		v1.GET("/nft/:contract/transfers", handler.GetContractNFTTransfersHandler)
+		v1.GET("/nft/:contract/tokens/:token_id/transfers", handler.GetTokenNFTTransfersHandler)
+
+		// 查询 DEX 兑换和交易池：按地址或交易池
+		v1.GET("/address/:addr/swaps", handler.GetAddressSwapsHandler)
+		v1.GET("/pool/:address", handler.GetPoolHandler)
+		v1.GET("/pool/:address/swaps", handler.GetPoolSwapsHandler)
+		v1.GET("/pool/:address/liquidity", handler.GetPoolLiquidityHandler)
+
+		// 查询地址的代币授权，附带实时额度
+		v1.GET("/address/:addr/approvals", handler.GetAddressApprovalsHandler)
+
+	}
+
+	// 管理接口：需要鉴权，触发操作的接口只接受非 GET 请求
+	admin := v1.Group("/admin", adminAuthMiddleware())
+	{
+		// 扫块任务：启动、查询状态、停止、暂停、恢复
+		admin.POST("/scan", ScanBlock)
+		admin.GET("/scan/status", handler.GetScanStatusHandler)
+		admin.POST("/scan/stop", handler.StopScanHandler)
+		admin.POST("/scan/pause", handler.PauseScanHandler)
+		admin.POST("/scan/resume", handler.ResumeScanHandler)
+
+		// 查询扫描失败的区块和链重组事件
+		admin.GET("/scan/failed", handler.GetFailedBlocksHandler)
+		admin.GET("/scan/reorgs", handler.GetReorgEventsHandler)
+
+		// 查询缺失的区块、回填缺失区块
+		admin.GET("/scan/gaps", handler.GetBlockGapsHandler)
+		admin.POST("/scan/backfill", handler.BackfillHandler)
+
+		// 登记合约 ABI
+		admin.POST("/abi/:address", handler.RegisterABIHandler)
 	}
 
 	// 7. 启动服务
+	srv := &http.Server{Addr: config.Cfg.Server.Port, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			util.Log.Fatalf("服务启动失败: %v", err)
+		}
+	}()
 	util.Log.Infof("服务启动成功，监听端口: %s", config.Cfg.Server.Port)
-	if err := r.Run(config.Cfg.Server.Port); err != nil {
-		util.Log.Fatalf("服务启动失败: %v", err)
+
+	// 8. 收到退出信号后停止接收请求，并停止扫描任务和失败区块重试
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	util.Log.Info("正在关闭服务...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Cfg.Server.Timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		util.Log.Errorf("关闭 HTTP 服务失败: %v", err)
 	}
+	handler.CloseScanner()
+	util.Log.Info("服务已关闭")
 }
 
 // ScanBlockHandler godoc
 // @Summary 扫描区块
-// @Description 从指定区块开始扫描并将数据存储到数据库
+// @Description 从指定区块开始扫描并将数据存储到数据库，同一时间只允许一个区间扫描或回填任务；follow=true 时启动跟随链头的扫描，可与区间扫描同时运行
 // @Tags scan
 // @Accept json
 // @Produce json
 // @Param from_block query int false "起始区块号（不指定则从数据库最新区块+1开始）"
-// @Success 200 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /scan [get]
+// @Param to_block query int false "结束区块号（不指定则扫描到已确认的最新区块），可与 from_block 配合重新索引指定区间"
+// @Param follow query bool false "为 true 时启动跟随链头的扫描，忽略 from_block / to_block"
+// @Success 200 {object} handler.Response
+// @Failure 401 {object} map[string]interface{}
+// @Security AdminToken
+// @Router /admin/scan [post]
 func ScanBlock(c *gin.Context) {
 	handler.ScanBlock(c)
 }
@@ -180,6 +367,8 @@ func GetTransactionHandler(c *gin.Context) {
 // @Produce json
 // @Param addr path string true "以太坊地址"
 // @Param contract query string true "ERC20合约地址"
+// @Param block query int false "查询该区块时的余额（需要归档节点）"
+// @Param timestamp query string false "查询该时刻的余额：Unix 秒、RFC3339 或 2006-01-02（UTC），与 block 二选一"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
@@ -194,6 +383,8 @@ func GetErc20BalanceHandler(c *gin.Context) {
 // @accept json
 // @Produce json
 // @Param addr path string true "以太坊地址"
+// @Param block query int false "查询该区块时的余额（需要归档节点）"
+// @Param timestamp query string false "查询该时刻的余额：Unix 秒、RFC3339 或 2006-01-02（UTC），与 block 二选一"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
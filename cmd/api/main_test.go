@@ -0,0 +1,126 @@
+package main
+
+import (
+	"blockchain-asset-api/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 按管理接口的签名规则计算 HMAC-SHA256 签名
+func signAdminRequest(secret, timestamp, method, uri, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + uri + "\n"))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestCheckAdminAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Cfg.Admin.MaxSkew = 5 * time.Minute
+
+	const (
+		token  = "admin-token"
+		secret = "admin-secret"
+		uri    = "/api/v1/admin/abi/0x01"
+		body   = `{"name":"Token","abi":[]}`
+	)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+
+	cases := []struct {
+		name          string
+		token, secret string
+		body          string
+		headers       map[string]string
+		wantOK        bool
+		wantReason    string
+	}{
+		{
+			name: "有效令牌", token: token, secret: secret, body: body,
+			headers: map[string]string{"Authorization": "Bearer " + token},
+			wantOK:  true,
+		},
+		{
+			name: "错误令牌", token: token, secret: secret, body: body,
+			headers: map[string]string{"Authorization": "Bearer wrong"},
+		},
+		{
+			name: "有效签名", token: token, secret: secret, body: body,
+			headers: map[string]string{
+				"X-Admin-Timestamp": now,
+				"X-Admin-Signature": signAdminRequest(secret, now, http.MethodPost, uri, body),
+			},
+			wantOK: true,
+		},
+		{
+			name: "时间戳超出允许范围", token: token, secret: secret, body: body,
+			headers: map[string]string{
+				"X-Admin-Timestamp": stale,
+				"X-Admin-Signature": signAdminRequest(secret, stale, http.MethodPost, uri, body),
+			},
+		},
+		{
+			name: "请求体被篡改", token: token, secret: secret, body: `{"name":"Evil","abi":[]}`,
+			headers: map[string]string{
+				"X-Admin-Timestamp": now,
+				"X-Admin-Signature": signAdminRequest(secret, now, http.MethodPost, uri, body),
+			},
+		},
+		{
+			name: "请求体过大", token: token, secret: secret, body: strings.Repeat("a", maxAdminBodySize+1),
+			headers: map[string]string{
+				"X-Admin-Timestamp": now,
+				"X-Admin-Signature": signAdminRequest(secret, now, http.MethodPost, uri, strings.Repeat("a", maxAdminBodySize+1)),
+			},
+			wantReason: "请求体过大",
+		},
+		{
+			name: "未配置凭证时拒绝令牌", body: body,
+			headers: map[string]string{"Authorization": "Bearer "},
+		},
+		{
+			name: "未配置凭证时拒绝签名", body: body,
+			headers: map[string]string{
+				"X-Admin-Timestamp": now,
+				"X-Admin-Signature": signAdminRequest("", now, http.MethodPost, uri, body),
+			},
+		},
+		{
+			name: "缺少鉴权信息", token: token, secret: secret, body: body,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, uri, strings.NewReader(tc.body))
+			for k, v := range tc.headers {
+				c.Request.Header.Set(k, v)
+			}
+
+			reason := checkAdminAuth(c, tc.token, tc.secret)
+			if ok := reason == ""; ok != tc.wantOK {
+				t.Fatalf("鉴权结果为 %q，期望通过=%v", reason, tc.wantOK)
+			}
+			if tc.wantReason != "" && reason != tc.wantReason {
+				t.Errorf("鉴权失败原因为 %q，期望 %q", reason, tc.wantReason)
+			}
+			// 签名校验通过后请求体仍可被后续处理器读取
+			if tc.wantOK {
+				if got, _ := io.ReadAll(c.Request.Body); string(got) != tc.body {
+					t.Errorf("鉴权后请求体为 %q，期望 %q", got, tc.body)
+				}
+			}
+		})
+	}
+}
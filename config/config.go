@@ -1,16 +1,19 @@
 package config
 
 import (
+	"fmt"
 	"github.com/spf13/viper"
 	"log"
 	"time"
 )
 
 type Config struct {
-	Server ServerConfig
-	Eth    EthConfig
-	Redis  RedisConfig
-	MySQL  MySQLConfig
+	Server  ServerConfig
+	Eth     EthConfig
+	Redis   RedisConfig
+	MySQL   MySQLConfig
+	Scanner ScannerConfig
+	Admin   AdminConfig
 }
 
 type ServerConfig struct {
@@ -19,7 +22,10 @@ type ServerConfig struct {
 }
 
 type EthConfig struct {
-	NodeURL string // 本地 GETH 节点：http://localhost:8545 或 Infura：https://mainnet.infura.io/v3/your-api-key
+	NodeURL            string // 本地 GETH 节点：http://localhost:8545 或 Infura：https://mainnet.infura.io/v3/your-api-key
+	Multicall3         string // Multicall3 合约地址，用于批量查询代币余额
+	MulticallBatchSize int    // 每次 aggregate3 调用的子调用数量上限，代币较多时分多次 eth_call 查询
+	WETH               string // WETH 合约地址，该合约的 Deposit / Withdrawal 事件标记为包装 / 解包 ETH
 }
 
 type RedisConfig struct {
@@ -33,6 +39,34 @@ type MySQLConfig struct {
 	DSN string // 格式：user:password@tcp(127.0.0.1:3306)/dbname?charset=utf8mb4&parseTime=True&loc=Local
 }
 
+type ScannerConfig struct {
+	MaxReorgDepth int64         // 链重组回溯的最大深度，超过则停止扫描等待人工处理
+	Follow        bool          // 启动后持续跟随链头扫描
+	PollInterval  time.Duration // HTTP 节点轮询最新区块的间隔
+	MaxLag        int64         // 跟随模式下允许数据库落后链头的区块数
+	Confirmations int64         // 区块达到该确认数后才写入数据库
+	HeadTag       string        // 链头标签：latest / safe / finalized
+	Workers       int           // 并行抓取区块的 worker 数
+	QueueSize     int           // 已抓取未提交的区块上限
+	RPCRate       float64       // 每秒允许的 RPC 请求数，0 表示不限速
+	RPCBurst      int           // RPC 请求突发上限
+	// 节点不支持 eth_getBlockReceipts 时，每个批量 eth_getTransactionReceipt 请求包含的交易数
+	ReceiptBatchSize int
+	RetryInterval    time.Duration // 检查失败区块重试队列的间隔
+	RetryBaseDelay   time.Duration // 失败区块首次重试的等待时间，之后按指数退避
+	RetryMaxDelay    time.Duration // 失败区块重试等待时间上限
+	MaxRetries       int           // 超过该重试次数的区块标记为 stuck
+	// 内部交易追踪：空表示关闭；debug 使用 debug_traceBlockByNumber（callTracer）；
+	// trace 使用 trace_block；auto 优先 trace_block，节点不支持时改用 debug
+	TraceMode string
+}
+
+type AdminConfig struct {
+	Token      string        // 管理接口静态令牌，请求头 Authorization: Bearer <token>
+	HMACSecret string        // 管理接口 HMAC-SHA256 签名密钥
+	MaxSkew    time.Duration // HMAC 签名时间戳允许的偏差
+}
+
 var Cfg Config
 
 // 初始化配置（读取本地 config.yaml，若不存在则用默认值）
@@ -45,11 +79,33 @@ func Init() {
 	viper.SetDefault("server.port", ":8080")
 	viper.SetDefault("server.timeout", 10*time.Second)
 	viper.SetDefault("eth.nodeURL", "http://localhost:8545")
+	viper.SetDefault("eth.multicall3", "0xcA11bde05977b3631167028862bE2a173976CA11")
+	viper.SetDefault("eth.multicallBatchSize", 200)
+	viper.SetDefault("eth.weth", "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
 	viper.SetDefault("redis.addr", "127.0.0.1:6379")
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
 	viper.SetDefault("redis.expire", 5*time.Minute)
 	viper.SetDefault("mysql.dsn", "root:123456@tcp(127.0.0.1:3306)/blockchain_asset?charset=utf8mb4&parseTime=True&loc=Local")
+	viper.SetDefault("scanner.maxReorgDepth", 64)
+	viper.SetDefault("scanner.follow", false)
+	viper.SetDefault("scanner.pollInterval", 12*time.Second)
+	viper.SetDefault("scanner.maxLag", 0)
+	viper.SetDefault("scanner.confirmations", 0)
+	viper.SetDefault("scanner.headTag", "latest")
+	viper.SetDefault("scanner.workers", 4)
+	viper.SetDefault("scanner.queueSize", 32)
+	viper.SetDefault("scanner.rpcRate", 20)
+	viper.SetDefault("scanner.rpcBurst", 20)
+	viper.SetDefault("scanner.receiptBatchSize", 100)
+	viper.SetDefault("scanner.retryInterval", 30*time.Second)
+	viper.SetDefault("scanner.retryBaseDelay", 10*time.Second)
+	viper.SetDefault("scanner.retryMaxDelay", time.Hour)
+	viper.SetDefault("scanner.maxRetries", 10)
+	viper.SetDefault("scanner.traceMode", "")
+	viper.SetDefault("admin.token", "")
+	viper.SetDefault("admin.hmacSecret", "")
+	viper.SetDefault("admin.maxSkew", 5*time.Minute)
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Printf("警告：未找到配置文件，使用默认配置: %v", err)
@@ -58,4 +114,24 @@ func Init() {
 	if err := viper.Unmarshal(&Cfg); err != nil {
 		log.Fatalf("配置解析失败: %v", err)
 	}
+	if err := Cfg.validate(); err != nil {
+		log.Fatalf("配置无效: %v", err)
+	}
+}
+
+// 校验启动后无法在运行中纠正的配置，例如 time.NewTicker 在间隔不大于 0 时会 panic
+func (c *Config) validate() error {
+	if c.Scanner.PollInterval <= 0 {
+		return fmt.Errorf("scanner.pollInterval 必须大于 0，当前为 %s", c.Scanner.PollInterval)
+	}
+	if c.Scanner.RetryInterval <= 0 {
+		return fmt.Errorf("scanner.retryInterval 必须大于 0，当前为 %s", c.Scanner.RetryInterval)
+	}
+	// 取值与 service.TraceMode* 一致，未知模式会让每个有交易的区块都扫描失败
+	switch c.Scanner.TraceMode {
+	case "", "debug", "trace", "auto":
+	default:
+		return fmt.Errorf("scanner.traceMode 无效: %q，可选值为空、debug、trace 或 auto", c.Scanner.TraceMode)
+	}
+	return nil
 }
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// 默认配置下有效的配置
+func validConfig() Config {
+	return Config{Scanner: ScannerConfig{
+		PollInterval:  12 * time.Second,
+		RetryInterval: 30 * time.Second,
+	}}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(c *Config)
+		want   string // 期望错误信息包含的配置项，为空表示有效
+	}{
+		{"默认配置", func(c *Config) {}, ""},
+		{"轮询间隔为 0", func(c *Config) { c.Scanner.PollInterval = 0 }, "scanner.pollInterval"},
+		{"轮询间隔为负数", func(c *Config) { c.Scanner.PollInterval = -time.Second }, "scanner.pollInterval"},
+		{"重试检查间隔为 0", func(c *Config) { c.Scanner.RetryInterval = 0 }, "scanner.retryInterval"},
+		{"追踪模式 auto", func(c *Config) { c.Scanner.TraceMode = "auto" }, ""},
+		{"未知追踪模式", func(c *Config) { c.Scanner.TraceMode = "parity" }, "scanner.traceMode"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := validConfig()
+			tc.modify(&c)
+			err := c.validate()
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("有效配置校验失败: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("校验结果为 %v，期望报告 %s", err, tc.want)
+			}
+		})
+	}
+}
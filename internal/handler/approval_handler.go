@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"blockchain-asset-api/internal/service"
+	"blockchain-asset-api/internal/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAddressApprovalsHandler godoc
+// @Summary 查询地址的代币授权
+// @Description 分页查询地址当前有效的 ERC20 授权和 ApprovalForAll 授权，按最近授权的区块号倒序。每个授权通过 eth_call 查询当前的 allowance / isApprovedForAll，并标记无限授权
+// @Tags address
+// @Accept json
+// @Produce json
+// @Param addr path string true "以太坊地址"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /address/{addr}/approvals [get]
+func GetAddressApprovalsHandler(c *gin.Context) {
+	address := c.Param("addr")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的以太坊地址")
+		return
+	}
+	page, size := parsePage(c)
+
+	approvals, total, err := service.GetAddressApprovals(c.Request.Context(), address, page, size)
+	if err != nil {
+		util.Log.Errorf("查询地址授权失败: address=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"approvals": approvals,
+		"total":     total,
+		"page":      page,
+	})
+}
@@ -3,8 +3,15 @@ package handler
 import (
 	"blockchain-asset-api/internal/service"
 	"blockchain-asset-api/internal/util"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
+	"math/big"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Response 统一响应格式
@@ -31,6 +38,19 @@ func fail(c *gin.Context, code int, message string) {
 	})
 }
 
+// 解析 page / size 分页参数，无效时使用默认值
+func parsePage(c *gin.Context) (page, size int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err = strconv.Atoi(c.DefaultQuery("size", "10"))
+	if err != nil || size < 1 || size > 100 {
+		size = 10
+	}
+	return page, size
+}
+
 // 查询ETH余额
 func GetEthBalanceHandler(c *gin.Context) {
 	address := c.Param("addr")
@@ -39,14 +59,23 @@ func GetEthBalanceHandler(c *gin.Context) {
 		return
 	}
 
-	balance, err := service.GetEthBalance(address)
+	blockNumber, ok := parseBalanceBlock(c)
+	if !ok {
+		return
+	}
+
+	balance, err := service.GetEthBalance(address, blockNumber)
 	if err != nil {
 		util.Log.Errorf("查询 ETH 余额失败: address=%s, err=%v", address, err)
-		fail(c, 500, err.Error())
+		failBalance(c, err)
 		return
 	}
 
-	success(c, gin.H{"address": address, "eth_balance": balance})
+	resp := gin.H{"address": address, "eth_balance": balance}
+	if blockNumber != nil {
+		resp["block_number"] = blockNumber.Int64()
+	}
+	success(c, resp)
 }
 
 // 查询ERC20代币余额
@@ -58,18 +87,146 @@ func GetErc20BalanceHandler(c *gin.Context) {
 		return
 	}
 
-	balance, err := service.GetErc20Balance(address, contractAddress)
+	blockNumber, ok := parseBalanceBlock(c)
+	if !ok {
+		return
+	}
+
+	balance, err := service.GetErc20Balance(address, contractAddress, blockNumber)
 	if err != nil {
 		util.Log.Errorf("查询 ERC20 余额失败: address=%s, contract=%s, err=%v", address, contractAddress, err)
+		failBalance(c, err)
+		return
+	}
+
+	resp := gin.H{
+		"address":           address,
+		"contract_address":  contractAddress,
+		"token_balance":     balance.Balance,
+		"formatted_balance": balance.FormattedBalance,
+		"name":              balance.Name,
+		"symbol":            balance.Symbol,
+		"decimals":          balance.Decimals,
+	}
+	if blockNumber != nil {
+		resp["block_number"] = blockNumber.Int64()
+	}
+	success(c, resp)
+}
+
+// 解析余额查询的 block / timestamp 参数，返回 nil 表示最新区块；参数无效时已写入失败响应
+func parseBalanceBlock(c *gin.Context) (*big.Int, bool) {
+	blockStr := c.Query("block")
+	tsStr := c.Query("timestamp")
+	if blockStr != "" && tsStr != "" {
+		fail(c, 400, "block 和 timestamp 只能指定一个")
+		return nil, false
+	}
+
+	if blockStr != "" {
+		number, err := strconv.ParseInt(blockStr, 10, 64)
+		if err != nil || number < 0 {
+			fail(c, 400, "无效的区块号")
+			return nil, false
+		}
+		return big.NewInt(number), true
+	}
+
+	if tsStr != "" {
+		ts, err := parseTimestamp(tsStr)
+		if err != nil {
+			fail(c, 400, err.Error())
+			return nil, false
+		}
+		number, err := service.BlockAtTime(ts)
+		if errors.Is(err, service.ErrBeforeGenesis) {
+			fail(c, 400, err.Error())
+			return nil, false
+		}
+		if err != nil {
+			util.Log.Errorf("查询时间戳对应区块失败: ts=%d, err=%v", ts, err)
+			fail(c, 500, err.Error())
+			return nil, false
+		}
+		return big.NewInt(number), true
+	}
+
+	return nil, true
+}
+
+// 解析时间参数：Unix 秒、RFC3339 或 UTC 日期（2006-01-02）
+func parseTimestamp(s string) (int64, error) {
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if ts < 0 {
+			return 0, fmt.Errorf("无效的时间戳: %s", s)
+		}
+		return ts, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix(), nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Unix(), nil
+	}
+	return 0, fmt.Errorf("无效的时间戳: %s（支持 Unix 秒、RFC3339 或 2006-01-02）", s)
+}
+
+// 余额查询失败响应，节点已裁剪历史状态时返回 410
+func failBalance(c *gin.Context, err error) {
+	if errors.Is(err, util.ErrStateUnavailable) {
+		fail(c, 410, err.Error())
+		return
+	}
+	fail(c, 500, err.Error())
+}
+
+// 单次代币组合查询允许指定的代币数上限
+const maxPortfolioTokens = 200
+
+// GetPortfolioHandler godoc
+// @Summary 查询地址的代币组合
+// @Description 通过 Multicall3 在一次 eth_call 中查询 ETH 余额和多个ERC20代币余额，未指定 tokens 时使用地址在已索引转移记录中出现过的代币
+// @Tags balance
+// @Accept json
+// @Produce json
+// @Param addr path string true "以太坊地址"
+// @Param tokens query string false "逗号分隔的ERC20合约地址"
+// @Success 200 {object} Response{data=service.Portfolio}
+// @Router /address/{addr}/portfolio [get]
+func GetPortfolioHandler(c *gin.Context) {
+	address := c.Param("addr")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的以太坊地址")
+		return
+	}
+
+	var contracts []string
+	if tokens := c.Query("tokens"); tokens != "" {
+		for _, token := range strings.Split(tokens, ",") {
+			token = strings.TrimSpace(token)
+			if token == "" {
+				continue
+			}
+			if !common.IsHexAddress(token) {
+				fail(c, 400, "无效的合约地址: "+token)
+				return
+			}
+			contracts = append(contracts, token)
+		}
+		if len(contracts) > maxPortfolioTokens {
+			fail(c, 400, "代币数量超过上限 "+strconv.Itoa(maxPortfolioTokens))
+			return
+		}
+	}
+
+	portfolio, err := service.GetPortfolio(address, contracts)
+	if err != nil {
+		util.Log.Errorf("查询代币组合失败: address=%s, err=%v", address, err)
 		fail(c, 500, err.Error())
 		return
 	}
 
-	success(c, gin.H{
-		"address":          address,
-		"contract_address": contractAddress,
-		"token_balance":    balance,
-	})
+	success(c, portfolio)
 }
 
 // 查询交易详情
@@ -107,3 +264,44 @@ func GetBlockHandler(c *gin.Context) {
 
 	success(c, blockInfo)
 }
+
+// GetBlockByTimeHandler godoc
+// @Summary 按时间戳查询区块
+// @Description 查询时间戳对应的区块，区间已索引时查询 blocks 表，否则通过区块头二分查找
+// @Tags block
+// @Accept json
+// @Produce json
+// @Param ts query string true "时间：Unix 秒、RFC3339 或 2006-01-02（UTC）"
+// @Param closest query string false "before：不晚于 ts 的最后一个区块（默认）；after：不早于 ts 的第一个区块"
+// @Success 200 {object} Response{data=service.BlockByTime}
+// @Router /block/by-time [get]
+func GetBlockByTimeHandler(c *gin.Context) {
+	tsStr := c.Query("ts")
+	if tsStr == "" {
+		fail(c, 400, "ts 不能为空")
+		return
+	}
+	ts, err := parseTimestamp(tsStr)
+	if err != nil {
+		fail(c, 400, err.Error())
+		return
+	}
+	closest := c.DefaultQuery("closest", service.ClosestBefore)
+	if closest != service.ClosestBefore && closest != service.ClosestAfter {
+		fail(c, 400, "closest 只支持 before 或 after")
+		return
+	}
+
+	result, err := service.GetBlockByTime(ts, closest)
+	if errors.Is(err, service.ErrBeforeGenesis) || errors.Is(err, service.ErrAfterHead) {
+		fail(c, 400, err.Error())
+		return
+	}
+	if err != nil {
+		util.Log.Errorf("按时间戳查询区块失败: ts=%d, closest=%s, err=%v", ts, closest, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, result)
+}
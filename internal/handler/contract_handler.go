@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"blockchain-asset-api/internal/service"
+	"blockchain-asset-api/internal/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// GetContractHandler godoc
+// @Summary 查询合约信息
+// @Description 查询已索引合约的部署地址、创建交易、区块号、字节码哈希和检测到的代币标准（erc20 / erc721 / erc1155）
+// @Tags contract
+// @Accept json
+// @Produce json
+// @Param address path string true "合约地址"
+// @Success 200 {object} Response
+// @Router /contract/{address} [get]
+func GetContractHandler(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的合约地址")
+		return
+	}
+
+	contract, err := service.GetContract(address)
+	if err != nil {
+		util.Log.Errorf("查询合约失败: address=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+	if contract == nil {
+		fail(c, 404, "合约未索引")
+		return
+	}
+
+	success(c, contract)
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"blockchain-asset-api/internal/service"
+	"blockchain-asset-api/internal/util"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAddressSwapsHandler godoc
+// @Summary 查询地址的 DEX 兑换
+// @Description 分页查询地址参与的 Uniswap V2 / V3 兑换（地址为交易发起方、调用池子的地址或接收方），按区块号倒序
+// @Tags address
+// @Accept json
+// @Produce json
+// @Param addr path string true "以太坊地址"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /address/{addr}/swaps [get]
+func GetAddressSwapsHandler(c *gin.Context) {
+	address := c.Param("addr")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的以太坊地址")
+		return
+	}
+	page, size := parsePage(c)
+
+	swaps, total, err := service.GetAddressSwaps(address, page, size)
+	if err != nil {
+		util.Log.Errorf("查询地址兑换记录失败: address=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"swaps": swaps,
+		"total": total,
+		"page":  page,
+	})
+}
+
+// GetPoolHandler godoc
+// @Summary 查询 DEX 交易池
+// @Description 查询已索引的交易池：协议版本、代币对、V3 手续费率，V2 池带有最近一次 Sync 事件同步的储备量
+// @Tags dex
+// @Accept json
+// @Produce json
+// @Param address path string true "交易池地址"
+// @Success 200 {object} Response{data=service.PoolInfo}
+// @Router /pool/{address} [get]
+func GetPoolHandler(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的交易池地址")
+		return
+	}
+
+	pool, err := service.GetPool(address)
+	if err != nil {
+		util.Log.Errorf("查询交易池失败: pool=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+	if pool == nil {
+		fail(c, 404, "交易池未索引")
+		return
+	}
+
+	success(c, pool)
+}
+
+// GetPoolSwapsHandler godoc
+// @Summary 查询交易池的兑换记录
+// @Description 分页查询交易池的兑换记录，按区块号倒序
+// @Tags dex
+// @Accept json
+// @Produce json
+// @Param address path string true "交易池地址"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /pool/{address}/swaps [get]
+func GetPoolSwapsHandler(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的交易池地址")
+		return
+	}
+	page, size := parsePage(c)
+
+	swaps, total, err := service.GetPoolSwaps(address, page, size)
+	if err != nil {
+		util.Log.Errorf("查询交易池兑换记录失败: pool=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"swaps": swaps,
+		"total": total,
+		"page":  page,
+	})
+}
+
+// GetPoolLiquidityHandler godoc
+// @Summary 查询交易池的流动性事件
+// @Description 分页查询交易池的添加流动性（mint）、移除流动性（burn）和 V2 储备量同步（sync）事件，按区块号倒序
+// @Tags dex
+// @Accept json
+// @Produce json
+// @Param address path string true "交易池地址"
+// @Param event query string false "事件类型：mint / burn / sync"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /pool/{address}/liquidity [get]
+func GetPoolLiquidityHandler(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的交易池地址")
+		return
+	}
+	page, size := parsePage(c)
+
+	events, total, err := service.GetPoolEvents(address, c.Query("event"), page, size)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPoolEvent) {
+			fail(c, 400, err.Error())
+			return
+		}
+		util.Log.Errorf("查询交易池流动性事件失败: pool=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"events": events,
+		"total":  total,
+		"page":   page,
+	})
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"blockchain-asset-api/internal/service"
+	"blockchain-asset-api/internal/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAddressInternalTransactionsHandler godoc
+// @Summary 查询地址的内部交易
+// @Description 分页查询地址作为发送方或接收方的内部交易（合约调用中的 ETH 转移、合约创建和自毁），需要开启 scanner.traceMode
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param addr path string true "以太坊地址"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /address/{addr}/internal-transactions [get]
+func GetAddressInternalTransactionsHandler(c *gin.Context) {
+	address := c.Param("addr")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的以太坊地址")
+		return
+	}
+	page, size := parsePage(c)
+
+	internals, total, err := service.GetAddressInternalTransactions(address, page, size)
+	if err != nil {
+		util.Log.Errorf("查询内部交易失败: address=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"internal_transactions": internals,
+		"total":                 total,
+		"page":                  page,
+	})
+}
+
+// GetTransactionInternalTransactionsHandler godoc
+// @Summary 查询交易的内部交易
+// @Description 按调用顺序返回已索引交易中的内部交易，需要开启 scanner.traceMode
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param txhash path string true "交易哈希"
+// @Success 200 {object} Response
+// @Router /transaction/{txhash}/internal [get]
+func GetTransactionInternalTransactionsHandler(c *gin.Context) {
+	txHash := c.Param("txhash")
+	if !isTxHash(txHash) {
+		fail(c, 400, "无效的交易哈希")
+		return
+	}
+
+	internals, err := service.GetTransactionInternalTransactions(txHash)
+	if err != nil {
+		util.Log.Errorf("查询交易内部交易失败: txHash=%s, err=%v", txHash, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{"tx_hash": common.HexToHash(txHash).Hex(), "internal_transactions": internals})
+}
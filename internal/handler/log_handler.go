@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"blockchain-asset-api/internal/service"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+// 登记合约 ABI 的请求体
+type RegisterABIRequest struct {
+	Name string          `json:"name"`
+	ABI  json.RawMessage `json:"abi" binding:"required" swaggertype:"array,object"`
+}
+
+// RegisterABIHandler godoc
+// @Summary 登记合约 ABI
+// @Description 登记（或覆盖）合约的 ABI，之后扫描到的该合约事件日志按 ABI 解码，已保存的日志在后台重新解码
+// @Tags contract
+// @Accept json
+// @Produce json
+// @Param address path string true "合约地址"
+// @Param body body RegisterABIRequest true "合约名称和 ABI（JSON 数组）"
+// @Success 200 {object} Response
+// @Security AdminToken
+// @Router /admin/abi/{address} [post]
+func RegisterABIHandler(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的合约地址")
+		return
+	}
+
+	var req RegisterABIRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fail(c, 400, "请求体需要包含 abi 字段")
+		return
+	}
+
+	contractABI, err := service.RegisterContractABI(address, req.Name, req.ABI)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidABI) {
+			fail(c, 400, err.Error())
+			return
+		}
+		util.Log.Errorf("登记合约 ABI 失败: address=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	// 已保存的日志可能很多，在后台按新 ABI 重新解码
+	go func() {
+		decoded, err := service.DecodeStoredLogs(context.Background(), address)
+		if err != nil {
+			util.Log.Errorf("重新解码合约 %s 的事件日志失败: %v", address, err)
+			return
+		}
+		util.Log.Infof("合约 %s 已保存的事件日志重新解码完成: 解码 %d 条", address, decoded)
+	}()
+
+	success(c, contractABI)
+}
+
+// GetABIHandler godoc
+// @Summary 查询合约 ABI
+// @Description 查询合约登记的 ABI
+// @Tags contract
+// @Accept json
+// @Produce json
+// @Param address path string true "合约地址"
+// @Success 200 {object} Response
+// @Router /abi/{address} [get]
+func GetABIHandler(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的合约地址")
+		return
+	}
+
+	contractABI, err := service.GetContractABI(address)
+	if err != nil {
+		util.Log.Errorf("查询合约 ABI 失败: address=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+	if contractABI == nil {
+		fail(c, 404, "合约未登记 ABI")
+		return
+	}
+
+	success(c, contractABI)
+}
+
+// GetLogsHandler godoc
+// @Summary 查询事件日志
+// @Description 按合约、事件名、topic 和 indexed 参数查询事件日志，合约登记了 ABI 的日志带有解码后的事件名和参数。
+// @Description 按参数筛选使用 args[参数名]=值，需要同时指定 address 和 event；string / bytes 参数按 keccak256 匹配
+// @Tags contract
+// @Accept json
+// @Produce json
+// @Param address query string false "合约地址"
+// @Param event query string false "事件名，如 Transfer"
+// @Param topic0 query string false "topic0（事件签名哈希）"
+// @Param topic1 query string false "topic1"
+// @Param topic2 query string false "topic2"
+// @Param topic3 query string false "topic3"
+// @Param args[name] query string false "按 indexed 参数筛选，如 args[from]=0x..."
+// @Param from_block query int false "起始区块号"
+// @Param to_block query int false "结束区块号"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /logs [get]
+func GetLogsHandler(c *gin.Context) {
+	query, errMsg := parseLogQuery(c, c.Query("address"))
+	if errMsg != "" {
+		fail(c, 400, errMsg)
+		return
+	}
+	getLogs(c, query)
+}
+
+// GetContractEventsHandler godoc
+// @Summary 查询合约的事件日志
+// @Description 查询合约的事件日志，可按事件名、topic 和 indexed 参数（args[参数名]=值）筛选
+// @Tags contract
+// @Accept json
+// @Produce json
+// @Param address path string true "合约地址"
+// @Param event query string false "事件名，如 Transfer"
+// @Param args[name] query string false "按 indexed 参数筛选，如 args[from]=0x..."
+// @Param from_block query int false "起始区块号"
+// @Param to_block query int false "结束区块号"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /contract/{address}/events [get]
+func GetContractEventsHandler(c *gin.Context) {
+	query, errMsg := parseLogQuery(c, c.Param("address"))
+	if errMsg != "" {
+		fail(c, 400, errMsg)
+		return
+	}
+	getLogs(c, query)
+}
+
+// 解析事件日志查询参数，address 为空表示不按合约筛选
+func parseLogQuery(c *gin.Context, address string) (service.LogQuery, string) {
+	query := service.LogQuery{
+		Address: address,
+		Event:   c.Query("event"),
+		Args:    c.QueryMap("args"),
+	}
+	if address != "" && !common.IsHexAddress(address) {
+		return query, "无效的合约地址"
+	}
+	for i := range query.Topics {
+		topic := c.Query(fmt.Sprintf("topic%d", i))
+		if topic != "" && !isTxHash(topic) {
+			return query, fmt.Sprintf("无效的 topic%d", i)
+		}
+		query.Topics[i] = topic
+	}
+	var ok bool
+	if query.FromBlock, ok = parseOptionalBlock(c, "from_block"); !ok {
+		return query, "无效的起始区块号"
+	}
+	if query.ToBlock, ok = parseOptionalBlock(c, "to_block"); !ok {
+		return query, "无效的结束区块号"
+	}
+	return query, ""
+}
+
+// 解析可选的区块号参数，未指定时返回 nil
+func parseOptionalBlock(c *gin.Context, name string) (*int64, bool) {
+	value := strings.TrimSpace(c.Query(name))
+	if value == "" {
+		return nil, true
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return nil, false
+	}
+	return &n, true
+}
+
+func getLogs(c *gin.Context, query service.LogQuery) {
+	page, size := parsePage(c)
+
+	logs, total, err := service.GetLogs(query, page, size)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLogQuery) {
+			fail(c, 400, err.Error())
+			return
+		}
+		util.Log.Errorf("查询事件日志失败: %+v, err=%v", query, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"logs":  logs,
+		"total": total,
+		"page":  page,
+	})
+}
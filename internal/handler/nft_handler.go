@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/service"
+	"blockchain-asset-api/internal/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"math/big"
+)
+
+// GetAddressNFTTransfersHandler godoc
+// @Summary 查询地址的NFT转移记录
+// @Description 分页查询地址作为发送方或接收方的 ERC-721 / ERC-1155 转移记录
+// @Tags nft
+// @Accept json
+// @Produce json
+// @Param addr path string true "以太坊地址"
+// @Param contract query string false "NFT合约地址"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /address/{addr}/nft-transfers [get]
+func GetAddressNFTTransfersHandler(c *gin.Context) {
+	address := c.Param("addr")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的以太坊地址")
+		return
+	}
+	filter := repository.NFTTransferFilter{Address: common.HexToAddress(address).Hex()}
+
+	if contract := c.Query("contract"); contract != "" {
+		if !common.IsHexAddress(contract) {
+			fail(c, 400, "无效的合约地址")
+			return
+		}
+		filter.Contract = common.HexToAddress(contract).Hex()
+	}
+
+	listNFTTransfers(c, filter)
+}
+
+// GetContractNFTTransfersHandler godoc
+// @Summary 查询NFT合约的转移记录
+// @Description 分页查询 NFT 合约的转移记录，可按代币 ID 筛选
+// @Tags nft
+// @Accept json
+// @Produce json
+// @Param contract path string true "NFT合约地址"
+// @Param token_id query string false "代币 ID（十进制或 0x 开头的十六进制）"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /nft/{contract}/transfers [get]
+func GetContractNFTTransfersHandler(c *gin.Context) {
+	contractNFTTransfers(c, c.Query("token_id"))
+}
+
+// GetTokenNFTTransfersHandler godoc
+// @Summary 查询单个NFT的转移记录
+// @Description 分页查询指定合约和代币 ID 的转移记录，即该 NFT 的持有历史
+// @Tags nft
+// @Accept json
+// @Produce json
+// @Param contract path string true "NFT合约地址"
+// @Param token_id path string true "代币 ID（十进制或 0x 开头的十六进制）"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /nft/{contract}/tokens/{token_id}/transfers [get]
+func GetTokenNFTTransfersHandler(c *gin.Context) {
+	contractNFTTransfers(c, c.Param("token_id"))
+}
+
+// 按合约和（可选的）代币 ID 查询转移记录
+func contractNFTTransfers(c *gin.Context, tokenID string) {
+	contract := c.Param("contract")
+	if !common.IsHexAddress(contract) {
+		fail(c, 400, "无效的合约地址")
+		return
+	}
+	filter := repository.NFTTransferFilter{Contract: common.HexToAddress(contract).Hex()}
+
+	if tokenID != "" {
+		// 代币 ID 按十进制字符串存储
+		id, ok := parseTokenID(tokenID)
+		if !ok {
+			fail(c, 400, "无效的代币 ID")
+			return
+		}
+		filter.TokenID = id.String()
+	}
+
+	listNFTTransfers(c, filter)
+}
+
+// 解析 uint256 代币 ID：默认按十进制解析（"010" 为 10），只有 0x 开头时按十六进制解析，不接受符号、下划线和其他进制前缀
+func parseTokenID(s string) (*big.Int, bool) {
+	base := 10
+	if len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		s, base = s[2:], 16
+	}
+	if s == "" || s[0] == '+' || s[0] == '-' {
+		return nil, false
+	}
+	id, ok := new(big.Int).SetString(s, base)
+	if !ok || id.BitLen() > 256 {
+		return nil, false
+	}
+	return id, true
+}
+
+// 分页查询NFT转移记录并返回
+func listNFTTransfers(c *gin.Context, filter repository.NFTTransferFilter) {
+	page, size := parsePage(c)
+
+	transfers, total, err := service.GetNFTTransfers(filter, page, size)
+	if err != nil {
+		util.Log.Errorf("查询NFT转移记录失败: filter=%+v, err=%v", filter, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"transfers": transfers,
+		"total":     total,
+		"page":      page,
+	})
+}
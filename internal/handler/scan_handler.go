@@ -1,26 +1,40 @@
 package handler
 
 import (
+	"blockchain-asset-api/internal/model"
 	"blockchain-asset-api/internal/service"
 	"blockchain-asset-api/internal/util"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"strconv"
+	"sync"
 )
 
-var scanner *service.BlockScanner
+var (
+	scanner     *service.BlockScanner
+	scannerOnce sync.Once
+)
 
 // 初始化扫描器
 func initScanner() {
-	if scanner == nil {
+	scannerOnce.Do(func() {
 		scanner = service.NewBlockScanner()
-	}
+	})
 }
 
-// 扫描区块
+// 扫描区块；follow=true 时启动持续跟随链头的扫描，与区间扫描和回填任务互不占用
 func ScanBlock(c *gin.Context) {
 	initScanner()
 
+	if c.Query("follow") == "true" {
+		if err := scanner.StartFollow(); err != nil {
+			fail(c, 409, err.Error())
+			return
+		}
+		success(c, gin.H{"message": "跟随扫描已启动"})
+		return
+	}
+
 	// 获取起始区块号参数
 	fromBlockStr := c.Query("from_block")
 	var fromBlock int64 = 0
@@ -29,22 +43,315 @@ func ScanBlock(c *gin.Context) {
 		var err error
 		fromBlock, err = strconv.ParseInt(fromBlockStr, 10, 64)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "无效的起始区块号",
-			})
+			fail(c, 400, "无效的起始区块号")
 			return
 		}
 	}
 
-	// 启动扫描（在goroutine中执行以避免阻塞HTTP请求）
-	go func() {
-		if err := scanner.StartScan(fromBlock); err != nil {
-			util.Log.Errorf("区块扫描失败: %v", err)
+	// 获取结束区块号参数（用于重新索引指定区间）
+	toBlockStr := c.Query("to_block")
+	var toBlock int64 = 0
+
+	if toBlockStr != "" {
+		var err error
+		toBlock, err = strconv.ParseInt(toBlockStr, 10, 64)
+		if err != nil || (toBlock > 0 && toBlock < fromBlock) {
+			fail(c, 400, "无效的结束区块号")
+			return
 		}
-	}()
+	}
+
+	// 启动扫描任务（在后台执行以避免阻塞HTTP请求），同一时间只允许一个区间扫描或回填任务
+	if err := scanner.StartScan(fromBlock, toBlock); err != nil {
+		fail(c, 409, err.Error())
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
+	success(c, gin.H{
 		"message":    "区块扫描已启动",
 		"from_block": fromBlock,
+		"to_block":   toBlock,
+	})
+}
+
+// 启动持续跟随链头的扫描（由 scanner.follow 配置开启）
+func StartFollowScan() {
+	initScanner()
+
+	if err := scanner.StartFollow(); err != nil {
+		util.Log.Errorf("启动跟随扫描失败: %v", err)
+	}
+}
+
+// 解析 job 查询参数：range（默认，区间扫描和回填）或 follow（跟随链头）
+func scanSlot(c *gin.Context) (string, bool) {
+	slot := c.DefaultQuery("job", service.ScanSlotRange)
+	if slot != service.ScanSlotRange && slot != service.ScanSlotFollow {
+		fail(c, 400, service.ErrScanSlot.Error())
+		return "", false
+	}
+	return slot, true
+}
+
+// GetScanStatusHandler godoc
+// @Summary 查询扫描任务状态
+// @Description 返回当前（或最近一次）扫描任务的当前区块、目标区块、每秒区块数、预计剩余时间和最近错误
+// @Tags scan
+// @Accept json
+// @Produce json
+// @Param job query string false "任务槽：range（区间扫描和回填）/ follow（跟随链头）" default(range)
+// @Success 200 {object} Response{data=service.ScanStatus}
+// @Security AdminToken
+// @Router /admin/scan/status [get]
+func GetScanStatusHandler(c *gin.Context) {
+	initScanner()
+
+	slot, ok := scanSlot(c)
+	if !ok {
+		return
+	}
+	success(c, scanner.Status(slot))
+}
+
+// StopScanHandler godoc
+// @Summary 停止扫描任务
+// @Tags scan
+// @Accept json
+// @Produce json
+// @Success 200 {object} Response
+// @Security AdminToken
+// @Param job query string false "任务槽：range / follow" default(range)
+// @Router /admin/scan/stop [post]
+func StopScanHandler(c *gin.Context) {
+	controlScan(c, (*service.BlockScanner).Stop, "扫描任务正在停止")
+}
+
+// PauseScanHandler godoc
+// @Summary 暂停扫描任务
+// @Description 已抓取的区块提交完后暂停，不再分发新区块
+// @Tags scan
+// @Accept json
+// @Produce json
+// @Success 200 {object} Response
+// @Security AdminToken
+// @Param job query string false "任务槽：range / follow" default(range)
+// @Router /admin/scan/pause [post]
+func PauseScanHandler(c *gin.Context) {
+	controlScan(c, (*service.BlockScanner).Pause, "扫描任务已暂停")
+}
+
+// ResumeScanHandler godoc
+// @Summary 恢复扫描任务
+// @Tags scan
+// @Accept json
+// @Produce json
+// @Success 200 {object} Response
+// @Security AdminToken
+// @Param job query string false "任务槽：range / follow" default(range)
+// @Router /admin/scan/resume [post]
+func ResumeScanHandler(c *gin.Context) {
+	controlScan(c, (*service.BlockScanner).Resume, "扫描任务已恢复")
+}
+
+// 执行扫描任务控制操作并返回最新状态
+func controlScan(c *gin.Context, action func(*service.BlockScanner, string) error, message string) {
+	initScanner()
+
+	slot, ok := scanSlot(c)
+	if !ok {
+		return
+	}
+	if err := action(scanner, slot); err != nil {
+		fail(c, 409, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"message": message,
+		"status":  scanner.Status(slot),
+	})
+}
+
+// 启动失败区块的后台重试
+func StartScanRetrier() {
+	initScanner()
+
+	go scanner.RunRetrier()
+}
+
+// 关闭扫描器：停止后台重试和全部扫描任务（服务退出时调用）
+func CloseScanner() {
+	initScanner()
+
+	scanner.Close()
+}
+
+// GetFailedBlocksHandler godoc
+// @Summary 查询扫描失败的区块
+// @Description 分页查询重试队列中的失败区块，status=stuck 时只返回超过最大重试次数的区块
+// @Tags scan
+// @Accept json
+// @Produce json
+// @Param status query string false "状态：pending / stuck"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Security AdminToken
+// @Router /admin/scan/failed [get]
+func GetFailedBlocksHandler(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != model.FailedBlockPending && status != model.FailedBlockStuck {
+		fail(c, 400, "无效的状态，可选值: pending / stuck")
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if err != nil || size < 1 || size > 100 {
+		size = 10
+	}
+
+	blocks, total, err := service.GetFailedBlocks(status, page, size)
+	if err != nil {
+		util.Log.Errorf("查询失败区块失败: %v", err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"blocks": blocks,
+		"total":  total,
+		"page":   page,
+	})
+}
+
+// GetReorgEventsHandler godoc
+// @Summary 查询链重组事件
+// @Description 分页查询扫描过程中发现并回滚的链重组，按发现时间倒序，total 为累计发生的链重组次数
+// @Tags scan
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Security AdminToken
+// @Router /admin/scan/reorgs [get]
+func GetReorgEventsHandler(c *gin.Context) {
+	page, size := parsePage(c)
+
+	events, total, err := service.GetReorgEvents(page, size)
+	if err != nil {
+		util.Log.Errorf("查询链重组事件失败: %v", err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"events": events,
+		"total":  total,
+		"page":   page,
+	})
+}
+
+// 解析 from_block / to_block 查询参数，参数无效时返回错误信息；未指定 from_block 时为 -1（数据库中最早的区块）
+func parseBlockRange(c *gin.Context) (from, to int64, errMsg string) {
+	from = -1
+	if s := c.Query("from_block"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, "无效的起始区块号"
+		}
+		from = n
+	}
+	if s := c.Query("to_block"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || (n > 0 && n < from) {
+			return 0, 0, "无效的结束区块号"
+		}
+		to = n
+	}
+	return from, to, ""
+}
+
+// GetBlockGapsHandler godoc
+// @Summary 查询缺失的区块
+// @Description 对比 blocks 表与指定区间，返回缺失的区块区段
+// @Tags scan
+// @Accept json
+// @Produce json
+// @Param from_block query int false "起始区块号（不指定则为数据库中最早的区块，数据库为空时必须指定）"
+// @Param to_block query int false "结束区块号（不指定则为数据库中最新区块）"
+// @Success 200 {object} Response
+// @Security AdminToken
+// @Router /admin/scan/gaps [get]
+func GetBlockGapsHandler(c *gin.Context) {
+	from, to, errMsg := parseBlockRange(c)
+	if errMsg != "" {
+		fail(c, 400, errMsg)
+		return
+	}
+
+	gaps, err := service.FindBlockGaps(from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrNoIndexedBlocks) {
+			fail(c, 400, err.Error())
+			return
+		}
+		util.Log.Errorf("查询缺失区块失败: from=%d, to=%d, err=%v", from, to, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"gaps":           gaps,
+		"missing_blocks": service.CountGapBlocks(gaps),
+	})
+}
+
+// BackfillHandler godoc
+// @Summary 回填缺失的区块
+// @Description 查找指定区间内缺失的区块区段，并通过扫描器只回填这些区段
+// @Tags scan
+// @Accept json
+// @Produce json
+// @Param from_block query int false "起始区块号（不指定则为数据库中最早的区块，数据库为空时必须指定）"
+// @Param to_block query int false "结束区块号（不指定则为数据库中最新区块）"
+// @Success 200 {object} Response
+// @Security AdminToken
+// @Router /admin/scan/backfill [post]
+func BackfillHandler(c *gin.Context) {
+	initScanner()
+
+	from, to, errMsg := parseBlockRange(c)
+	if errMsg != "" {
+		fail(c, 400, errMsg)
+		return
+	}
+
+	gaps, err := service.FindBlockGaps(from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrNoIndexedBlocks) {
+			fail(c, 400, err.Error())
+			return
+		}
+		util.Log.Errorf("查询缺失区块失败: from=%d, to=%d, err=%v", from, to, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	if len(gaps) > 0 {
+		if err := scanner.StartBackfill(gaps); err != nil {
+			fail(c, 409, err.Error())
+			return
+		}
+	}
+
+	success(c, gin.H{
+		"message":        "区块回填已启动",
+		"gaps":           gaps,
+		"missing_blocks": service.CountGapBlocks(gaps),
 	})
 }
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"blockchain-asset-api/internal/model"
 	"blockchain-asset-api/internal/service"
 	"blockchain-asset-api/internal/util"
+	"encoding/hex"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // TransactionListResponse 交易列表响应
@@ -14,6 +17,11 @@ type TransactionListResponse struct {
 	Total        int64                 `json:"total"`
 	Page         int                   `json:"page"`
 	Pages        int                   `json:"pages"`
+	// 按地址筛选且未指定交易类型时，地址收到的信标链提款（按 withdrawal_page 单独分页，每页数量与交易列表相同），没有提款时省略
+	Withdrawals     []model.Withdrawal `json:"withdrawals,omitempty"`
+	WithdrawalTotal int64              `json:"withdrawal_total,omitempty"`
+	WithdrawalPage  int                `json:"withdrawal_page,omitempty"`
+	WithdrawalPages int                `json:"withdrawal_pages,omitempty"`
 }
 
 // TransactionResponse 交易响应
@@ -25,25 +33,38 @@ type TransactionResponse struct {
 	ToAddress   string `json:"to_address"`
 	Value       string `json:"value"`
 	GasLimit    int64  `json:"gas_limit"`
-	GasPrice    string `json:"gas_price"`
 	GasUsed     *int64 `json:"gas_used"`
 	TxType      string `json:"tx_type"`
 	Status      string `json:"status"`
 	CreatedAt   string `json:"created_at"`
 	ERC20Amount string `json:"erc20_amount"`
+	// 交易类型、nonce 和费用字段（wei）
+	model.TxFees
+	// 合约创建交易部署的合约地址
+	ContractAddress string `json:"contract_address"`
+	// 交易标签，如 weth_wrap、token_mint、approval、nft_mint
+	Labels model.Labels `json:"labels"`
+	// 按小数位数换算后的ERC20转账金额和代币符号
+	ERC20FormattedAmount string `json:"erc20_formatted_amount"`
+	ERC20Symbol          string `json:"erc20_symbol"`
+	// 交易内全部ERC20转移记录
+	ERC20Transfers []model.ERC20Transfer `json:"erc20_transfers"`
+	// 当前确认数
+	Confirmations int64 `json:"confirmations"`
 }
 
 // GetTransactionsHandler godoc
 // @Summary 获取交易列表
-// @Description 获取扫描到的交易列表，支持分页和筛选
+// @Description 获取扫描到的交易列表，支持分页和筛选。按地址筛选且未指定交易类型时，withdrawals 中同时返回地址收到的信标链提款，提款按 withdrawal_page 单独分页
 // @Tags transaction
 // @Accept json
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param size query int false "每页数量" default(10)
-// @Param tx_type query string false "交易类型"
+// @Param tx_type query string false "交易类型或交易标签：eth_transfer / erc20_transfer / nft_transfer / contract_call / weth_wrap / weth_unwrap / token_mint / token_burn / approval / nft_mint"
 // @Param address query string false "地址筛选"
 // @Param block_number query int false "区块号"
+// @Param withdrawal_page query int false "提款页码" default(1)
 // @Success 200 {object} TransactionListResponse
 // @Failure 500 {object} map[string]interface{}
 // @Router /transactions [get]
@@ -54,12 +75,18 @@ func GetTransactionsHandler(c *gin.Context) {
 	txType := c.Query("tx_type")
 	address := c.Query("address")
 	blockNumberStr := c.Query("block_number")
+	withdrawalPageStr := c.DefaultQuery("withdrawal_page", "1")
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
 		page = 1
 	}
 
+	withdrawalPage, err := strconv.Atoi(withdrawalPageStr)
+	if err != nil || withdrawalPage < 1 {
+		withdrawalPage = 1
+	}
+
 	size, err := strconv.Atoi(sizeStr)
 	if err != nil || size < 1 || size > 100 {
 		size = 10
@@ -79,6 +106,12 @@ func GetTransactionsHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取交易列表失败"})
 		return
 	}
+	withdrawals, withdrawalTotal, err := service.GetTransactionWithdrawals(withdrawalPage, size, txType, address, blockNumber)
+	if err != nil {
+		util.Log.Errorf("获取地址提款失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取交易列表失败"})
+		return
+	}
 
 	// 转换为响应格式
 	var responseTxs []TransactionResponse
@@ -94,23 +127,72 @@ func GetTransactionsHandler(c *gin.Context) {
 			ToAddress:   tx.ToAddress,
 			Value:       tx.Value[:4],
 			GasLimit:    tx.GasLimit,
-			GasPrice:    tx.GasPrice,
 			GasUsed:     tx.GasUsed,
 			TxType:      tx.TxType,
 			Status:      tx.Status,
 			CreatedAt:   tx.CreatedAt.Format("2006-01-02 15:04:05"),
 			//判断不为空 保留4位小数
-			ERC20Amount: tx.ERC20Amount,
+			ERC20Amount:          tx.ERC20Amount,
+			ERC20FormattedAmount: tx.ERC20FormattedAmount,
+			ERC20Symbol:          tx.ERC20Symbol,
+			ERC20Transfers:       tx.ERC20Transfers,
+			Confirmations:        tx.Confirmations,
+			ContractAddress:      tx.ContractAddress,
+			TxFees:               tx.TxFees,
+			Labels:               tx.Labels,
 		}
 		responseTxs = append(responseTxs, responseTx)
 	}
 
 	pages := int((total + int64(size) - 1) / int64(size))
 
-	c.JSON(http.StatusOK, TransactionListResponse{
-		Transactions: responseTxs,
-		Total:        total,
-		Page:         page,
-		Pages:        pages,
-	})
+	response := TransactionListResponse{
+		Transactions:    responseTxs,
+		Total:           total,
+		Page:            page,
+		Pages:           pages,
+		Withdrawals:     withdrawals,
+		WithdrawalTotal: withdrawalTotal,
+	}
+	if withdrawalTotal > 0 {
+		response.WithdrawalPage = withdrawalPage
+		response.WithdrawalPages = int((withdrawalTotal + int64(size) - 1) / int64(size))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTransactionTransfersHandler godoc
+// @Summary 查询交易内的全部代币转移
+// @Description 返回已索引交易中每条 Transfer 日志对应的ERC20转移记录，以及NFT转移记录
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param txhash path string true "交易哈希"
+// @Success 200 {object} Response{data=service.TransactionTransfers}
+// @Router /transaction/{txhash}/transfers [get]
+func GetTransactionTransfersHandler(c *gin.Context) {
+	txHash := c.Param("txhash")
+	if !isTxHash(txHash) {
+		fail(c, 400, "无效的交易哈希")
+		return
+	}
+
+	transfers, err := service.GetTransactionTransfers(txHash)
+	if err != nil {
+		util.Log.Errorf("查询交易代币转移失败: txHash=%s, err=%v", txHash, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, transfers)
+}
+
+// 判断是否为 32 字节十六进制交易哈希（可省略 0x 前缀）
+func isTxHash(s string) bool {
+	s = strings.TrimPrefix(s, "0x")
+	if len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
 }
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"blockchain-asset-api/internal/service"
+	"blockchain-asset-api/internal/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// GetAddressWithdrawalsHandler godoc
+// @Summary 查询地址的信标链提款
+// @Description 分页查询地址收到的信标链提款（上海升级后），提款不经过交易直接增加地址余额
+// @Tags address
+// @Accept json
+// @Produce json
+// @Param addr path string true "以太坊地址"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} Response
+// @Router /address/{addr}/withdrawals [get]
+func GetAddressWithdrawalsHandler(c *gin.Context) {
+	address := c.Param("addr")
+	if !common.IsHexAddress(address) {
+		fail(c, 400, "无效的以太坊地址")
+		return
+	}
+	page, size := parsePage(c)
+
+	withdrawals, total, err := service.GetAddressWithdrawals(address, page, size)
+	if err != nil {
+		util.Log.Errorf("查询提款记录失败: address=%s, err=%v", address, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{
+		"withdrawals": withdrawals,
+		"total":       total,
+		"page":        page,
+	})
+}
+
+// GetBlockWithdrawalsHandler godoc
+// @Summary 查询区块的信标链提款
+// @Description 按提款序号返回已索引区块中的信标链提款
+// @Tags block
+// @Accept json
+// @Produce json
+// @Param blocknum path int true "区块号"
+// @Success 200 {object} Response
+// @Router /block/{blocknum}/withdrawals [get]
+func GetBlockWithdrawalsHandler(c *gin.Context) {
+	blockNumber, err := strconv.ParseInt(c.Param("blocknum"), 10, 64)
+	if err != nil || blockNumber < 0 {
+		fail(c, 400, "无效的区块号")
+		return
+	}
+
+	withdrawals, err := service.GetBlockWithdrawals(blockNumber)
+	if err != nil {
+		util.Log.Errorf("查询区块提款记录失败: block=%d, err=%v", blockNumber, err)
+		fail(c, 500, err.Error())
+		return
+	}
+
+	success(c, gin.H{"block_number": blockNumber, "withdrawals": withdrawals})
+}
@@ -23,12 +23,26 @@ type Block struct {
 	ID                int64     `gorm:"primaryKey;autoIncrement" json:"id"`
 	BlockNumber       int64     `gorm:"column:block_number;uniqueIndex" json:"block_number"`
 	BlockHash         string    `gorm:"column:block_hash;type:varchar(66)" json:"block_hash"`
-	Timestamp         time.Time `gorm:"column:timestamp" json:"timestamp"`
+	ParentHash        string    `gorm:"column:parent_hash;type:varchar(66)" json:"parent_hash"`
+	Timestamp         time.Time `gorm:"column:timestamp;index" json:"timestamp"`
 	TransactionsCount int       `gorm:"column:transactions_count" json:"transactions_count"`
 	GasUsed           int64     `gorm:"column:gas_used" json:"gas_used"`
 	GasLimit          int64     `gorm:"column:gas_limit" json:"gas_limit"`
 	Miner             string    `gorm:"column:miner;type:varchar(42)" json:"miner"`
 	CreatedAt         time.Time `gorm:"column:created_at" json:"-"`
+	// EIP-1559 基础费用（wei），伦敦升级前的区块为空
+	BaseFeePerGas *string `gorm:"column:base_fee_per_gas;type:decimal(65,0)" json:"base_fee_per_gas"`
+	// 区块内销毁的基础费用和 blob 费用总和（wei）
+	BurntFees *string `gorm:"column:burnt_fees;type:decimal(65,0)" json:"burnt_fees"`
+	// 区块内交易支付给出块者的小费总和（wei），即出块者的执行层奖励
+	PriorityFees *string `gorm:"column:priority_fees;type:decimal(65,0)" json:"priority_fees"`
+	// 坎昆升级后的 blob gas 用量和超额 blob gas，之前的区块为空
+	BlobGasUsed   *uint64 `gorm:"column:blob_gas_used" json:"blob_gas_used"`
+	ExcessBlobGas *uint64 `gorm:"column:excess_blob_gas" json:"excess_blob_gas"`
+	StateRoot     string  `gorm:"column:state_root;type:varchar(66)" json:"state_root"`
+	Size          uint64  `gorm:"column:size;comment:区块 RLP 编码字节数" json:"size"`
+	// 上海升级后的信标链提款数量
+	WithdrawalsCount int `gorm:"column:withdrawals_count" json:"withdrawals_count"`
 }
 
 // 表名
@@ -45,30 +59,398 @@ type Transaction struct {
 	ToAddress   string    `gorm:"column:to_address;type:varchar(42);index" json:"to_address"`
 	Value       string    `gorm:"column:value;type:decimal(65,30)" json:"value"`
 	GasLimit    int64     `gorm:"column:gas_limit" json:"gas_limit"`
-	GasPrice    string    `gorm:"column:gas_price;type:decimal(65,30)" json:"gas_price"`
 	GasUsed     *int64    `gorm:"column:gas_used" json:"gas_used"`
 	TxType      string    `gorm:"column:tx_type;type:varchar(20)" json:"tx_type"`
 	Status      string    `gorm:"column:status;type:varchar(10)" json:"status"`
 	CreatedAt   time.Time `gorm:"column:created_at" json:"-"`
+	// 交易类型、nonce 和费用字段
+	TxFees
+	// 合约创建交易部署的合约地址
+	ContractAddress string `gorm:"column:contract_address;type:varchar(42);index" json:"contract_address"`
+	// 根据事件日志识别的交易标签（WETH 存取、代币铸造 / 销毁、授权、NFT 铸造），可同时有多个
+	Labels Labels `gorm:"column:labels;type:varchar(255);comment:交易标签，逗号分隔" json:"labels"`
 	// 新增字段用于存储ERC20转账金额
 	ERC20Amount string `gorm:"-" json:"erc20_amount"`
+	// ERC20转账按小数位数换算后的金额和代币符号
+	ERC20FormattedAmount string `gorm:"-" json:"erc20_formatted_amount"`
+	ERC20Symbol          string `gorm:"-" json:"erc20_symbol"`
+	// 交易内全部ERC20转移记录，按日志序号排列
+	ERC20Transfers []ERC20Transfer `gorm:"-" json:"erc20_transfers,omitempty"`
+	// 当前确认数，查询时根据链头计算
+	Confirmations int64 `gorm:"-" json:"confirmations"`
 }
 
 func (Transaction) TableName() string {
 	return "transactions"
 }
 
-// ERC20代币转移模型
+// 交易信封类型（EIP-2718）
+const (
+	TxEnvelopeLegacy     = 0
+	TxEnvelopeAccessList = 1 // EIP-2930
+	TxEnvelopeDynamicFee = 2 // EIP-1559
+	TxEnvelopeBlob       = 3 // EIP-4844
+	TxEnvelopeSetCode    = 4 // EIP-7702
+)
+
+// 交易类型、nonce 和费用字段，金额单位均为 wei，不适用于该交易类型的字段为空
+type TxFees struct {
+	EnvelopeType uint8  `gorm:"column:envelope_type;comment:EIP-2718 交易类型" json:"envelope_type"`
+	Nonce        uint64 `gorm:"column:nonce" json:"nonce"`
+	// legacy 和 access list 交易签名的 gasPrice
+	GasPrice *string `gorm:"column:gas_price;type:decimal(65,0)" json:"gas_price"`
+	// EIP-1559 及之后的交易类型签名的费用上限和小费上限
+	MaxFeePerGas         *string `gorm:"column:max_fee_per_gas;type:decimal(65,0)" json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *string `gorm:"column:max_priority_fee_per_gas;type:decimal(65,0)" json:"max_priority_fee_per_gas"`
+	// 回执中实际成交的 gas 单价
+	EffectiveGasPrice *string `gorm:"column:effective_gas_price;type:decimal(65,0)" json:"effective_gas_price"`
+	// 实际支付的手续费（含 blob 费用）和其中销毁的部分
+	Fee       *string `gorm:"column:fee;type:decimal(65,0)" json:"fee"`
+	BurntFees *string `gorm:"column:burnt_fees;type:decimal(65,0)" json:"burnt_fees"`
+	// blob 交易（类型 3）的 blob gas 字段
+	MaxFeePerBlobGas *string  `gorm:"column:max_fee_per_blob_gas;type:decimal(65,0)" json:"max_fee_per_blob_gas"`
+	BlobGasUsed      *uint64  `gorm:"column:blob_gas_used" json:"blob_gas_used"`
+	BlobGasPrice     *string  `gorm:"column:blob_gas_price;type:decimal(65,0)" json:"blob_gas_price"`
+	BlobHashes       JSONText `gorm:"column:blob_hashes;type:text" json:"blob_hashes"`
+	// EIP-2930 访问列表
+	AccessList JSONText `gorm:"column:access_list;type:text" json:"access_list"`
+}
+
+// ERC20代币转移模型，每条 Transfer 日志一行
 type ERC20Transfer struct {
 	ID              int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	TxHash          string    `gorm:"column:tx_hash;type:varchar(66);index" json:"tx_hash"`
-	FromAddress     string    `gorm:"column:from_address;type:varchar(42)" json:"from_address"`
-	ToAddress       string    `gorm:"column:to_address;type:varchar(42)" json:"to_address"`
+	TxHash          string    `gorm:"column:tx_hash;type:varchar(66);uniqueIndex:idx_erc20_tx_log,priority:1" json:"tx_hash"`
+	LogIndex        uint      `gorm:"column:log_index;uniqueIndex:idx_erc20_tx_log,priority:2;comment:日志在区块中的序号" json:"log_index"`
+	BlockNumber     int64     `gorm:"column:block_number;index" json:"block_number"`
+	FromAddress     string    `gorm:"column:from_address;type:varchar(42);index" json:"from_address"`
+	ToAddress       string    `gorm:"column:to_address;type:varchar(42);index" json:"to_address"`
 	ContractAddress string    `gorm:"column:contract_address;type:varchar(42);index" json:"contract_address"`
 	Amount          string    `gorm:"column:amount;type:decimal(65,30)" json:"amount"`
 	CreatedAt       time.Time `gorm:"column:created_at" json:"-"`
+	// 代币元数据，查询时根据 tokens 表填充
+	Symbol          string `gorm:"-" json:"symbol,omitempty"`
+	Decimals        *int   `gorm:"-" json:"decimals,omitempty"`
+	FormattedAmount string `gorm:"-" json:"formatted_amount,omitempty"`
 }
 
 func (ERC20Transfer) TableName() string {
 	return "erc20_transfers"
 }
+
+// 代币元数据模型，合约首次出现时通过 eth_call 查询并保存
+type Token struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	ContractAddress string    `gorm:"column:contract_address;type:varchar(42);uniqueIndex" json:"contract_address"`
+	Name            string    `gorm:"column:name;type:varchar(128)" json:"name"`
+	Symbol          string    `gorm:"column:symbol;type:varchar(64)" json:"symbol"`
+	Decimals        *int      `gorm:"column:decimals;comment:为空表示合约未实现 decimals" json:"decimals"`
+	TotalSupply     string    `gorm:"column:total_supply;type:varchar(78)" json:"total_supply"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Token) TableName() string {
+	return "tokens"
+}
+
+// 代币名称和符号的列长度（字符数），合约返回的元数据超长时保存前截断
+const (
+	TokenNameMaxLen   = 128
+	TokenSymbolMaxLen = 64
+)
+
+// NFT 标准
+const (
+	NFTStandardERC721  = "erc721"
+	NFTStandardERC1155 = "erc1155"
+)
+
+// NFT转移模型（ERC-721 Transfer、ERC-1155 TransferSingle / TransferBatch）
+type NFTTransfer struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TxHash          string    `gorm:"column:tx_hash;type:varchar(66);index" json:"tx_hash"`
+	LogIndex        uint      `gorm:"column:log_index" json:"log_index"`
+	BatchIndex      int       `gorm:"column:batch_index;comment:TransferBatch 中的序号" json:"batch_index"`
+	BlockNumber     int64     `gorm:"column:block_number;index" json:"block_number"`
+	Standard        string    `gorm:"column:standard;type:varchar(8)" json:"standard"`
+	ContractAddress string    `gorm:"column:contract_address;type:varchar(42);index:idx_nft_contract_token,priority:1" json:"contract_address"`
+	TokenID         string    `gorm:"column:token_id;type:varchar(78);index:idx_nft_contract_token,priority:2" json:"token_id"`
+	Operator        string    `gorm:"column:operator;type:varchar(42);comment:ERC-1155 操作方" json:"operator"`
+	FromAddress     string    `gorm:"column:from_address;type:varchar(42);index" json:"from_address"`
+	ToAddress       string    `gorm:"column:to_address;type:varchar(42);index" json:"to_address"`
+	Amount          string    `gorm:"column:amount;type:varchar(78)" json:"amount"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"-"`
+}
+
+func (NFTTransfer) TableName() string {
+	return "nft_transfers"
+}
+
+// 合约模型，记录合约创建交易（追踪模式下包括合约内部创建的合约）
+type Contract struct {
+	ID             int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	Address        string    `gorm:"column:address;type:varchar(42);uniqueIndex" json:"address"`
+	Deployer       string    `gorm:"column:deployer;type:varchar(42);index;comment:部署地址，内部创建时为工厂合约" json:"deployer"`
+	CreationTxHash string    `gorm:"column:creation_tx_hash;type:varchar(66);index" json:"creation_tx_hash"`
+	BlockNumber    int64     `gorm:"column:block_number;index" json:"block_number"`
+	BytecodeHash   string    `gorm:"column:bytecode_hash;type:varchar(66);comment:运行时字节码的 keccak256，为空表示尚未检测" json:"bytecode_hash"`
+	CodeSize       int       `gorm:"column:code_size" json:"code_size"`
+	Standards      string    `gorm:"column:standards;type:varchar(64);comment:检测到的代币标准，逗号分隔" json:"standards"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (Contract) TableName() string {
+	return "contracts"
+}
+
+// 信标链提款模型（上海升级后），提款不经过交易直接增加地址余额
+type Withdrawal struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	WithdrawalIndex uint64    `gorm:"column:withdrawal_index;uniqueIndex;comment:信标链全局提款序号" json:"index"`
+	ValidatorIndex  uint64    `gorm:"column:validator_index;index" json:"validator_index"`
+	BlockNumber     int64     `gorm:"column:block_number;index" json:"block_number"`
+	Address         string    `gorm:"column:address;type:varchar(42);index" json:"address"`
+	Amount          string    `gorm:"column:amount;type:decimal(65,30);comment:提款金额（ETH）" json:"amount"`
+	CreatedAt       time.Time `gorm:"column:created_at" json:"-"`
+}
+
+func (Withdrawal) TableName() string {
+	return "withdrawals"
+}
+
+// 事件日志模型，保存回执中的全部日志；合约登记 ABI 后按 ABI 解码事件名和参数
+type Log struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	TxHash      string `gorm:"column:tx_hash;type:varchar(66);uniqueIndex:idx_log_tx_index,priority:1" json:"tx_hash"`
+	LogIndex    uint   `gorm:"column:log_index;uniqueIndex:idx_log_tx_index,priority:2;comment:日志在区块中的序号" json:"log_index"`
+	BlockNumber int64  `gorm:"column:block_number;index" json:"block_number"`
+	Address     string `gorm:"column:address;type:varchar(42);index:idx_log_address_event,priority:1" json:"address"`
+	Topic0      string `gorm:"column:topic0;type:varchar(66);index" json:"topic0"`
+	Topic1      string `gorm:"column:topic1;type:varchar(66);index" json:"topic1"`
+	Topic2      string `gorm:"column:topic2;type:varchar(66);index" json:"topic2"`
+	Topic3      string `gorm:"column:topic3;type:varchar(66);index" json:"topic3"`
+	Data        string `gorm:"column:data;type:mediumtext;comment:十六进制日志数据" json:"data"`
+	// 按 ABI 解码的事件名、规范化签名和参数，未登记 ABI 或无法解码时为空
+	EventName      string    `gorm:"column:event_name;type:varchar(128);index;index:idx_log_address_event,priority:2" json:"event_name"`
+	EventSignature string    `gorm:"column:event_signature;type:varchar(512)" json:"event_signature"`
+	Args           JSONText  `gorm:"column:args;type:json" json:"args"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"-"`
+}
+
+func (Log) TableName() string {
+	return "logs"
+}
+
+// 合约 ABI 登记，扫描器按 ABI 解码该合约的事件日志
+type ContractABI struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	Address   string    `gorm:"column:address;type:varchar(42);uniqueIndex" json:"address"`
+	Name      string    `gorm:"column:name;type:varchar(128);comment:合约名称" json:"name"`
+	ABI       JSONText  `gorm:"column:abi;type:json" json:"abi"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (ContractABI) TableName() string {
+	return "contract_abis"
+}
+
+// 内部交易类型
+const (
+	InternalTxCall         = "call"
+	InternalTxCreate       = "create"
+	InternalTxCreate2      = "create2"
+	InternalTxSelfDestruct = "selfdestruct"
+)
+
+// 内部交易模型（追踪模式下记录合约调用中的 ETH 转移、合约创建和自毁）
+type InternalTransaction struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TxHash       string    `gorm:"column:tx_hash;type:varchar(66);uniqueIndex:idx_internal_tx_trace,priority:1" json:"tx_hash"`
+	TraceAddress string    `gorm:"column:trace_address;type:varchar(255);uniqueIndex:idx_internal_tx_trace,priority:2;comment:调用路径，如 0.1" json:"trace_address"`
+	BlockNumber  int64     `gorm:"column:block_number;index" json:"block_number"`
+	Type         string    `gorm:"column:type;type:varchar(16)" json:"type"`
+	FromAddress  string    `gorm:"column:from_address;type:varchar(42);index" json:"from_address"`
+	ToAddress    string    `gorm:"column:to_address;type:varchar(42);index;comment:create 为新合约地址，selfdestruct 为接收余额的地址" json:"to_address"`
+	Value        string    `gorm:"column:value;type:decimal(65,30)" json:"value"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"-"`
+}
+
+func (InternalTransaction) TableName() string {
+	return "internal_transactions"
+}
+
+// DEX 协议，兼容 Uniswap 事件的分叉（如 SushiSwap）按对应版本记录
+const (
+	DexUniswapV2 = "uniswap_v2"
+	DexUniswapV3 = "uniswap_v3"
+)
+
+// DEX 交易池，首次出现兑换或流动性事件时通过 eth_call 查询代币对
+type DexPool struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	Address   string    `gorm:"column:address;type:varchar(42);uniqueIndex" json:"address"`
+	Protocol  string    `gorm:"column:protocol;type:varchar(16)" json:"protocol"`
+	Token0    string    `gorm:"column:token0;type:varchar(42);index" json:"token0"`
+	Token1    string    `gorm:"column:token1;type:varchar(42);index" json:"token1"`
+	Fee       *int      `gorm:"column:fee;comment:V3 手续费率（百万分之一），V2 为空" json:"fee"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (DexPool) TableName() string {
+	return "dex_pools"
+}
+
+// DEX 兑换记录，按池子的 token0 / token1 变化归一为卖出和买入的代币，金额为代币最小单位
+type DexSwap struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TxHash      string    `gorm:"column:tx_hash;type:varchar(66);uniqueIndex:idx_dex_swap_tx_log,priority:1" json:"tx_hash"`
+	LogIndex    uint      `gorm:"column:log_index;uniqueIndex:idx_dex_swap_tx_log,priority:2;comment:日志在区块中的序号" json:"log_index"`
+	BlockNumber int64     `gorm:"column:block_number;index" json:"block_number"`
+	Protocol    string    `gorm:"column:protocol;type:varchar(16)" json:"protocol"`
+	Pool        string    `gorm:"column:pool;type:varchar(42);index" json:"pool"`
+	TxFrom      string    `gorm:"column:tx_from;type:varchar(42);index;comment:交易发起方" json:"tx_from"`
+	Sender      string    `gorm:"column:sender;type:varchar(42);index;comment:调用池子的地址，通常为路由合约" json:"sender"`
+	Recipient   string    `gorm:"column:recipient;type:varchar(42);index" json:"recipient"`
+	TokenIn     string    `gorm:"column:token_in;type:varchar(42);index" json:"token_in"`
+	TokenOut    string    `gorm:"column:token_out;type:varchar(42);index" json:"token_out"`
+	AmountIn    string    `gorm:"column:amount_in;type:decimal(65,0)" json:"amount_in"`
+	AmountOut   string    `gorm:"column:amount_out;type:decimal(65,0)" json:"amount_out"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"-"`
+	// 代币元数据，查询时根据 tokens 表填充
+	TokenInSymbol      string `gorm:"-" json:"token_in_symbol,omitempty"`
+	TokenOutSymbol     string `gorm:"-" json:"token_out_symbol,omitempty"`
+	FormattedAmountIn  string `gorm:"-" json:"formatted_amount_in,omitempty"`
+	FormattedAmountOut string `gorm:"-" json:"formatted_amount_out,omitempty"`
+}
+
+func (DexSwap) TableName() string {
+	return "dex_swaps"
+}
+
+// DEX 池子事件类型
+const (
+	DexEventMint = "mint" // 添加流动性
+	DexEventBurn = "burn" // 移除流动性
+	DexEventSync = "sync" // V2 储备量同步
+)
+
+// DEX 池子的流动性事件，Sync 事件的 Amount0 / Amount1 为同步后的储备量，金额为代币最小单位
+type DexPoolEvent struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	TxHash      string `gorm:"column:tx_hash;type:varchar(66);uniqueIndex:idx_dex_event_tx_log,priority:1" json:"tx_hash"`
+	LogIndex    uint   `gorm:"column:log_index;uniqueIndex:idx_dex_event_tx_log,priority:2;comment:日志在区块中的序号" json:"log_index"`
+	BlockNumber int64  `gorm:"column:block_number;index" json:"block_number"`
+	Protocol    string `gorm:"column:protocol;type:varchar(16)" json:"protocol"`
+	Pool        string `gorm:"column:pool;type:varchar(42);index:idx_dex_event_pool,priority:1" json:"pool"`
+	Event       string `gorm:"column:event;type:varchar(8);index:idx_dex_event_pool,priority:2" json:"event"`
+	Owner       string `gorm:"column:owner;type:varchar(42);index;comment:V2 为事件中的 sender，V3 为头寸所有者" json:"owner,omitempty"`
+	Recipient   string `gorm:"column:recipient;type:varchar(42);comment:V2 Burn 取回代币的地址" json:"recipient,omitempty"`
+	Amount0     string `gorm:"column:amount0;type:decimal(65,0)" json:"amount0"`
+	Amount1     string `gorm:"column:amount1;type:decimal(65,0)" json:"amount1"`
+	// V3 头寸的流动性数量和价格区间
+	Liquidity *string   `gorm:"column:liquidity;type:decimal(65,0)" json:"liquidity,omitempty"`
+	TickLower *int      `gorm:"column:tick_lower" json:"tick_lower,omitempty"`
+	TickUpper *int      `gorm:"column:tick_upper" json:"tick_upper,omitempty"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"-"`
+}
+
+func (DexPoolEvent) TableName() string {
+	return "dex_pool_events"
+}
+
+// 代币授权类型
+const (
+	ApprovalKindERC20  = "erc20"   // ERC20 Approval，授权额度
+	ApprovalKindForAll = "for_all" // ERC-721 / ERC-1155 ApprovalForAll，授权操作持有的全部代币
+)
+
+// 代币授权事件（ERC20 Approval 和 ApprovalForAll），ERC-721 单个代币的 Approval 不记录
+type ApprovalEvent struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	TxHash      string `gorm:"column:tx_hash;type:varchar(66);uniqueIndex:idx_approval_event_tx_log,priority:1" json:"tx_hash"`
+	LogIndex    uint   `gorm:"column:log_index;uniqueIndex:idx_approval_event_tx_log,priority:2;comment:日志在区块中的序号" json:"log_index"`
+	BlockNumber int64  `gorm:"column:block_number;index" json:"block_number"`
+	Kind        string `gorm:"column:kind;type:varchar(8)" json:"kind"`
+	Owner       string `gorm:"column:owner;type:varchar(42);index:idx_approval_event_key,priority:1" json:"owner"`
+	Spender     string `gorm:"column:spender;type:varchar(42);index:idx_approval_event_key,priority:2;comment:ApprovalForAll 为 operator" json:"spender"`
+	Token       string `gorm:"column:token;type:varchar(42);index:idx_approval_event_key,priority:3" json:"token"`
+	// ERC20 授权额度（代币最小单位），ApprovalForAll 为空；无限授权常为 uint256 最大值，超出 decimal(65) 范围，以十进制文本保存
+	Amount    *string   `gorm:"column:amount;type:varchar(78)" json:"amount"`
+	Approved  bool      `gorm:"column:approved;comment:额度大于 0 或 ApprovalForAll 为 true" json:"approved"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"-"`
+}
+
+func (ApprovalEvent) TableName() string {
+	return "approval_events"
+}
+
+// 每个 (owner, spender, token) 当前的授权状态，取最近一次授权事件；
+// 重复扫描和链重组回滚后按剩余的授权事件重新计算
+type TokenApproval struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	Owner       string    `gorm:"column:owner;type:varchar(42);uniqueIndex:idx_token_approval,priority:1" json:"owner"`
+	Spender     string    `gorm:"column:spender;type:varchar(42);uniqueIndex:idx_token_approval,priority:2" json:"spender"`
+	Token       string    `gorm:"column:token;type:varchar(42);uniqueIndex:idx_token_approval,priority:3" json:"token"`
+	Kind        string    `gorm:"column:kind;type:varchar(8)" json:"kind"`
+	Amount      *string   `gorm:"column:amount;type:varchar(78)" json:"amount"`
+	Approved    bool      `gorm:"column:approved" json:"approved"`
+	BlockNumber int64     `gorm:"column:block_number;index;comment:最近一次授权事件的区块号" json:"block_number"`
+	TxHash      string    `gorm:"column:tx_hash;type:varchar(66)" json:"tx_hash"`
+	LogIndex    uint      `gorm:"column:log_index" json:"log_index"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"-"`
+}
+
+func (TokenApproval) TableName() string {
+	return "token_approvals"
+}
+
+// 链重组事件模型
+type ReorgEvent struct {
+	ID             int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	DetectedBlock  int64     `gorm:"column:detected_block;index;comment:发现父哈希不一致的区块号" json:"detected_block"`
+	CommonAncestor int64     `gorm:"column:common_ancestor;comment:共同祖先区块号" json:"common_ancestor"`
+	Depth          int64     `gorm:"column:depth;comment:回滚的区块数" json:"depth"`
+	OldHash        string    `gorm:"column:old_hash;type:varchar(66);comment:本地记录的区块哈希" json:"old_hash"`
+	NewHash        string    `gorm:"column:new_hash;type:varchar(66);comment:链上规范区块的父哈希" json:"new_hash"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+func (ReorgEvent) TableName() string {
+	return "reorg_events"
+}
+
+// 扫描进度模型（断点）
+type ScanProgress struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"column:name;type:varchar(32);uniqueIndex;comment:扫描任务名称" json:"name"`
+	LastBlock int64     `gorm:"column:last_block;comment:最后处理的区块号" json:"last_block"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (ScanProgress) TableName() string {
+	return "scan_progress"
+}
+
+// 扫描失败区块状态
+const (
+	FailedBlockPending = "pending" // 等待重试
+	FailedBlockStuck   = "stuck"   // 超过最大重试次数，需要人工处理
+)
+
+// 扫描失败区块模型（重试队列）
+type FailedBlock struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	BlockNumber int64     `gorm:"column:block_number;uniqueIndex" json:"block_number"`
+	RetryCount  int       `gorm:"column:retry_count;comment:已重试次数" json:"retry_count"`
+	LastError   string    `gorm:"column:last_error;type:text;comment:最近一次失败原因" json:"last_error"`
+	Status      string    `gorm:"column:status;type:varchar(16);index:idx_failed_status_next" json:"status"`
+	NextRetryAt time.Time `gorm:"column:next_retry_at;index:idx_failed_status_next;comment:下次重试时间" json:"next_retry_at"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+func (FailedBlock) TableName() string {
+	return "failed_blocks"
+}
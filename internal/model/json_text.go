@@ -0,0 +1,50 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// JSONText 以文本列保存的 JSON，接口中原样输出，为空时保存为 NULL、输出为 null
+type JSONText []byte
+
+// Value 实现 driver.Valuer
+func (j JSONText) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// Scan 实现 sql.Scanner
+func (j *JSONText) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSONText(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 JSONText", value)
+	}
+	return nil
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (j *JSONText) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*j = nil
+		return nil
+	}
+	*j = append((*j)[:0], data...)
+	return nil
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Labels 交易标签集合，以逗号分隔的文本列保存，接口中输出为字符串数组
+type Labels []string
+
+// Value 实现 driver.Valuer，没有标签时保存为空字符串
+func (l Labels) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+// Scan 实现 sql.Scanner
+func (l *Labels) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("无法将 %T 转换为 Labels", value)
+	}
+	if text == "" {
+		*l = Labels{}
+		return nil
+	}
+	*l = strings.Split(text, ",")
+	return nil
+}
+
+// Has 判断是否包含指定标签
+func (l Labels) Has(label string) bool {
+	for _, v := range l {
+		if v == label {
+			return true
+		}
+	}
+	return false
+}
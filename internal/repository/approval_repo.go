@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 授权状态的唯一键
+type approvalKey struct {
+	Owner   string
+	Spender string
+	Token   string
+}
+
+// 替换区块的授权事件，并重新计算本区块事件涉及的授权状态，以及此前由本区块事件决定的授权状态
+func saveApprovalEvents(tx *gorm.DB, blockNumber int64, events []*model.ApprovalEvent) error {
+	keys, err := approvalKeysWhere(tx, "block_number = ?", blockNumber)
+	if err != nil {
+		return fmt.Errorf("查询授权状态失败: %v", err)
+	}
+
+	if err := tx.Where("block_number = ?", blockNumber).Delete(&model.ApprovalEvent{}).Error; err != nil {
+		return fmt.Errorf("清理旧授权事件失败: %v", err)
+	}
+	if len(events) > 0 {
+		if err := tx.CreateInBatches(events, 100).Error; err != nil {
+			return fmt.Errorf("保存授权事件失败: %v", err)
+		}
+	}
+
+	for _, event := range events {
+		keys = append(keys, approvalKey{Owner: event.Owner, Spender: event.Spender, Token: event.Token})
+	}
+	if err := refreshApprovals(tx, keys); err != nil {
+		return fmt.Errorf("更新授权状态失败: %v", err)
+	}
+	return nil
+}
+
+// 删除 ancestor 之后的授权事件，由这些事件决定的授权状态回退到之前最近一次授权事件
+func rollbackApprovals(tx *gorm.DB, ancestor int64) error {
+	keys, err := approvalKeysWhere(tx, "block_number > ?", ancestor)
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("block_number > ?", ancestor).Delete(&model.ApprovalEvent{}).Error; err != nil {
+		return err
+	}
+	return refreshApprovals(tx, keys)
+}
+
+func approvalKeysWhere(tx *gorm.DB, query string, args ...interface{}) ([]approvalKey, error) {
+	var keys []approvalKey
+	err := tx.Model(&model.TokenApproval{}).Select("owner", "spender", "token").
+		Where(query, args...).Find(&keys).Error
+	return keys, err
+}
+
+// 按最近一次授权事件重写授权状态，没有剩余事件时删除
+func refreshApprovals(tx *gorm.DB, keys []approvalKey) error {
+	seen := make(map[approvalKey]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		where := tx.Where("owner = ? AND spender = ? AND token = ?", key.Owner, key.Spender, key.Token)
+		var event model.ApprovalEvent
+		err := where.Session(&gorm.Session{}).Order("block_number DESC, log_index DESC").First(&event).Error
+		if err == gorm.ErrRecordNotFound {
+			if err := where.Session(&gorm.Session{}).Delete(&model.TokenApproval{}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		approval := &model.TokenApproval{
+			Owner:       event.Owner,
+			Spender:     event.Spender,
+			Token:       event.Token,
+			Kind:        event.Kind,
+			Amount:      event.Amount,
+			Approved:    event.Approved,
+			BlockNumber: event.BlockNumber,
+			TxHash:      event.TxHash,
+			LogIndex:    event.LogIndex,
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "owner"}, {Name: "spender"}, {Name: "token"}},
+			DoUpdates: clause.AssignmentColumns([]string{"kind", "amount", "approved", "block_number", "tx_hash", "log_index", "updated_at"}),
+		}).Create(approval).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 分页查询地址当前有效的授权（额度大于 0 或 ApprovalForAll 为 true），按最近授权的区块号倒序
+func (r *BlockRepository) ListActiveApprovals(owner string, page, size int) ([]model.TokenApproval, int64, error) {
+	var approvals []model.TokenApproval
+	var total int64
+
+	query := r.db.Model(&model.TokenApproval{}).Where("owner = ? AND approved = ?", owner, true)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("block_number DESC, log_index DESC").
+		Offset(offset).Limit(size).Find(&approvals).Error; err != nil {
+		return nil, 0, err
+	}
+	return approvals, total, nil
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"blockchain-asset-api/internal/model"
+	"fmt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 type BlockRepository struct {
@@ -26,17 +29,309 @@ func (r *BlockRepository) GetLatestBlockNumber() (int64, error) {
 	return block.BlockNumber, nil
 }
 
-// 保存区块信息
-func (r *BlockRepository) SaveBlock(block *model.Block) error {
-	return r.db.Create(block).Error
+// 获取数据库中最早的区块号，没有区块时 ok 为 false
+func (r *BlockRepository) GetEarliestBlockNumber() (number int64, ok bool, err error) {
+	var block model.Block
+	err = r.db.Order("block_number").First(&block).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return block.BlockNumber, true, nil
 }
 
-// 保存交易信息
-func (r *BlockRepository) SaveTransaction(tx *model.Transaction) error {
-	return r.db.Create(tx).Error
+// 单个区块需要原子写入的全部数据
+type BlockData struct {
+	Block          *model.Block
+	Transactions   []*model.Transaction
+	ERC20Transfers []*model.ERC20Transfer
+	NFTTransfers   []*model.NFTTransfer
+	// 追踪模式关闭时为空
+	InternalTransactions []*model.InternalTransaction
+	Contracts            []*model.Contract
+	Withdrawals          []*model.Withdrawal
+	Logs                 []*model.Log
+	DexSwaps             []*model.DexSwap
+	DexPoolEvents        []*model.DexPoolEvent
+	ApprovalEvents       []*model.ApprovalEvent
 }
 
-// 保存ERC20转移记录
-func (r *BlockRepository) SaveERC20Transfer(transfer *model.ERC20Transfer) error {
-	return r.db.Create(transfer).Error
+// 在一个数据库事务中保存区块、交易、事件日志、ERC20和NFT转移记录、DEX兑换和流动性事件、授权事件、内部交易、新部署的合约以及信标链提款。
+// 区块和交易按唯一键覆盖写入，重复扫描同一区块时替换旧数据，并重新计算受影响的授权状态。
+func (r *BlockRepository) SaveBlockData(data *BlockData) error {
+	blockNumber := data.Block.BlockNumber
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "block_number"}},
+			UpdateAll: true,
+		}).Create(data.Block).Error; err != nil {
+			return fmt.Errorf("保存区块失败: %v", err)
+		}
+
+		// 清理该区块中已不存在的交易（重新扫描时链上数据可能已变化）
+		txHashes := make([]string, 0, len(data.Transactions))
+		for _, t := range data.Transactions {
+			txHashes = append(txHashes, t.TxHash)
+		}
+		stale := tx.Where("block_number = ?", blockNumber)
+		if len(txHashes) > 0 {
+			stale = stale.Where("tx_hash NOT IN ?", txHashes)
+		}
+		if err := stale.Delete(&model.Transaction{}).Error; err != nil {
+			return fmt.Errorf("清理旧交易失败: %v", err)
+		}
+
+		if len(data.Transactions) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "tx_hash"}},
+				UpdateAll: true,
+			}).CreateInBatches(data.Transactions, 100).Error; err != nil {
+				return fmt.Errorf("保存交易失败: %v", err)
+			}
+		}
+
+		// 事件日志先删除再写入
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.Log{}).Error; err != nil {
+			return fmt.Errorf("清理旧事件日志失败: %v", err)
+		}
+		if len(data.Logs) > 0 {
+			if err := tx.CreateInBatches(data.Logs, 100).Error; err != nil {
+				return fmt.Errorf("保存事件日志失败: %v", err)
+			}
+		}
+
+		// 先删除该区块旧的ERC20转移记录，再按 (tx_hash, log_index) 覆盖写入
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.ERC20Transfer{}).Error; err != nil {
+			return fmt.Errorf("清理旧ERC20转移记录失败: %v", err)
+		}
+		if len(data.ERC20Transfers) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "tx_hash"}, {Name: "log_index"}},
+				UpdateAll: true,
+			}).CreateInBatches(data.ERC20Transfers, 100).Error; err != nil {
+				return fmt.Errorf("保存ERC20转移记录失败: %v", err)
+			}
+		}
+
+		// NFT转移记录同样先删除再写入
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.NFTTransfer{}).Error; err != nil {
+			return fmt.Errorf("清理旧NFT转移记录失败: %v", err)
+		}
+		if len(data.NFTTransfers) > 0 {
+			if err := tx.CreateInBatches(data.NFTTransfers, 100).Error; err != nil {
+				return fmt.Errorf("保存NFT转移记录失败: %v", err)
+			}
+		}
+
+		// DEX兑换和流动性事件同样先删除再写入
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.DexSwap{}).Error; err != nil {
+			return fmt.Errorf("清理旧DEX兑换记录失败: %v", err)
+		}
+		if len(data.DexSwaps) > 0 {
+			if err := tx.CreateInBatches(data.DexSwaps, 100).Error; err != nil {
+				return fmt.Errorf("保存DEX兑换记录失败: %v", err)
+			}
+		}
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.DexPoolEvent{}).Error; err != nil {
+			return fmt.Errorf("清理旧DEX流动性事件失败: %v", err)
+		}
+		if len(data.DexPoolEvents) > 0 {
+			if err := tx.CreateInBatches(data.DexPoolEvents, 100).Error; err != nil {
+				return fmt.Errorf("保存DEX流动性事件失败: %v", err)
+			}
+		}
+
+		// 授权事件先删除再写入，之后按事件重新计算授权状态
+		if err := saveApprovalEvents(tx, blockNumber, data.ApprovalEvents); err != nil {
+			return err
+		}
+
+		// 内部交易同样先删除再写入
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.InternalTransaction{}).Error; err != nil {
+			return fmt.Errorf("清理旧内部交易失败: %v", err)
+		}
+		if len(data.InternalTransactions) > 0 {
+			if err := tx.CreateInBatches(data.InternalTransactions, 100).Error; err != nil {
+				return fmt.Errorf("保存内部交易失败: %v", err)
+			}
+		}
+
+		// 合约按地址覆盖写入（CREATE2 合约自毁后可在同一地址重新部署）
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.Contract{}).Error; err != nil {
+			return fmt.Errorf("清理旧合约记录失败: %v", err)
+		}
+		if len(data.Contracts) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "address"}},
+				UpdateAll: true,
+			}).CreateInBatches(data.Contracts, 100).Error; err != nil {
+				return fmt.Errorf("保存合约记录失败: %v", err)
+			}
+		}
+
+		// 提款按全局提款序号覆盖写入
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.Withdrawal{}).Error; err != nil {
+			return fmt.Errorf("清理旧提款记录失败: %v", err)
+		}
+		if len(data.Withdrawals) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "withdrawal_index"}},
+				UpdateAll: true,
+			}).CreateInBatches(data.Withdrawals, 100).Error; err != nil {
+				return fmt.Errorf("保存提款记录失败: %v", err)
+			}
+		}
+
+		// 区块保存成功后移出失败重试队列
+		if err := tx.Where("block_number = ?", blockNumber).Delete(&model.FailedBlock{}).Error; err != nil {
+			return fmt.Errorf("清理失败区块记录失败: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// 按区块号获取已保存的区块，不存在时返回 nil
+func (r *BlockRepository) GetBlockByNumber(blockNumber int64) (*model.Block, error) {
+	var block model.Block
+	err := r.db.Where("block_number = ?", blockNumber).First(&block).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &block, nil
+}
+
+// 获取时间戳不晚于 t 的最后一个已保存区块，不存在时返回 nil
+func (r *BlockRepository) FindBlockBefore(t time.Time) (*model.Block, error) {
+	return r.findBlock(r.db.Where("timestamp <= ?", t).Order("block_number DESC"))
+}
+
+// 获取时间戳不早于 t 的第一个已保存区块，不存在时返回 nil
+func (r *BlockRepository) FindBlockAfter(t time.Time) (*model.Block, error) {
+	return r.findBlock(r.db.Where("timestamp >= ?", t).Order("block_number"))
+}
+
+func (r *BlockRepository) findBlock(query *gorm.DB) (*model.Block, error) {
+	var block model.Block
+	err := query.First(&block).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &block, nil
+}
+
+// 回滚到指定区块：删除区块号大于 ancestor 的区块、交易、事件日志、转移记录、内部交易、合约和提款，并回退扫描断点
+func (r *BlockRepository) RollbackToBlock(ancestor int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.ScanProgress{}).Where("last_block > ?", ancestor).
+			Updates(map[string]interface{}{"last_block": ancestor, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		// 回滚区间会重新扫描，无需再重试
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.FailedBlock{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.ERC20Transfer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.NFTTransfer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.InternalTransaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.DexSwap{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.DexPoolEvent{}).Error; err != nil {
+			return err
+		}
+		if err := rollbackApprovals(tx, ancestor); err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.Contract{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.Withdrawal{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.Log{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("block_number > ?", ancestor).Delete(&model.Transaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("block_number > ?", ancestor).Delete(&model.Block{}).Error
+	})
+}
+
+// 保存链重组事件
+func (r *BlockRepository) SaveReorgEvent(event *model.ReorgEvent) error {
+	return r.db.Create(event).Error
+}
+
+// 分页查询链重组事件，按发现时间倒序，total 为累计发生的链重组次数
+func (r *BlockRepository) ListReorgEvents(page, size int) ([]model.ReorgEvent, int64, error) {
+	var events []model.ReorgEvent
+	var total int64
+
+	query := r.db.Model(&model.ReorgEvent{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("id DESC").Offset(offset).Limit(size).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// 区块号区间 [From, To]
+type BlockGap struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// 查找 [from, to] 区间内 blocks 表中缺失的区块号区段
+func (r *BlockRepository) FindGaps(from, to int64) ([]BlockGap, error) {
+	// 分段读取区块号，避免一次加载过大区间
+	const chunkSize = 10000
+
+	gaps := make([]BlockGap, 0)
+	expected := from
+	for start := from; start <= to; start += chunkSize {
+		end := start + chunkSize - 1
+		if end > to {
+			end = to
+		}
+
+		var numbers []int64
+		if err := r.db.Model(&model.Block{}).
+			Where("block_number BETWEEN ? AND ?", start, end).
+			Order("block_number").
+			Pluck("block_number", &numbers).Error; err != nil {
+			return nil, err
+		}
+
+		for _, n := range numbers {
+			if n > expected {
+				gaps = append(gaps, BlockGap{From: expected, To: n - 1})
+			}
+			expected = n + 1
+		}
+	}
+	if expected <= to {
+		gaps = append(gaps, BlockGap{From: expected, To: to})
+	}
+	return gaps, nil
 }
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/testutil"
+	"blockchain-asset-api/internal/util"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 连接进程内数据库并执行迁移
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	util.InitLog()
+	util.Log.SetOutput(io.Discard)
+
+	config.Cfg.MySQL.DSN = testutil.StartMySQL(t)
+	if err := InitMySQL(); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+}
+
+// 构造区块 number 的数据，每个交易哈希带一条 ERC20 转移记录
+func testBlockData(number int64, txHashes ...string) *BlockData {
+	data := &BlockData{Block: &model.Block{
+		BlockNumber: number,
+		BlockHash:   fmt.Sprintf("0x%064x", number),
+		Timestamp:   time.Unix(1700000000+number*12, 0),
+		CreatedAt:   time.Now(),
+	}}
+	for _, hash := range txHashes {
+		data.Transactions = append(data.Transactions, &model.Transaction{
+			TxHash:      hash,
+			BlockNumber: number,
+			Value:       "0",
+			CreatedAt:   time.Now(),
+		})
+		data.ERC20Transfers = append(data.ERC20Transfers, &model.ERC20Transfer{
+			TxHash:      hash,
+			BlockNumber: number,
+			Amount:      "1000",
+			CreatedAt:   time.Now(),
+		})
+	}
+	return data
+}
+
+// 统计 model 对应表中满足条件的行数
+func countRows(t *testing.T, model interface{}, query string, args ...interface{}) int64 {
+	t.Helper()
+
+	var count int64
+	if err := DB.Model(model).Where(query, args...).Count(&count).Error; err != nil {
+		t.Fatalf("统计行数失败: %v", err)
+	}
+	return count
+}
+
+func TestSaveBlockDataRollsBackOnFailure(t *testing.T) {
+	setupTestDB(t)
+	repo := NewBlockRepository()
+
+	data := testBlockData(10, "0xa1", "0xa2")
+	// 最后一条转移记录写入失败，之前写入的区块和交易也不能留下
+	data.ERC20Transfers[1].ToAddress = "0x" + strings.Repeat("f", 64)
+	if err := repo.SaveBlockData(data); err == nil {
+		t.Fatal("写入超长地址应当失败")
+	}
+
+	if n := countRows(t, &model.Block{}, "block_number = ?", 10); n != 0 {
+		t.Errorf("写入失败后残留 %d 个区块", n)
+	}
+	if n := countRows(t, &model.Transaction{}, "block_number = ?", 10); n != 0 {
+		t.Errorf("写入失败后残留 %d 笔交易", n)
+	}
+	if n := countRows(t, &model.ERC20Transfer{}, "block_number = ?", 10); n != 0 {
+		t.Errorf("写入失败后残留 %d 条转移记录", n)
+	}
+}
+
+func TestSaveBlockDataReplacesReindexedBlock(t *testing.T) {
+	setupTestDB(t)
+	repo := NewBlockRepository()
+
+	if err := repo.SaveBlockData(testBlockData(10, "0xa1", "0xa2")); err != nil {
+		t.Fatalf("保存区块失败: %v", err)
+	}
+	// 重新扫描时区块内只剩 0xa2，并新增了 0xa3
+	if err := repo.SaveBlockData(testBlockData(10, "0xa2", "0xa3")); err != nil {
+		t.Fatalf("重新保存区块失败: %v", err)
+	}
+
+	if n := countRows(t, &model.Block{}, "block_number = ?", 10); n != 1 {
+		t.Errorf("区块 10 有 %d 行，期望 1 行", n)
+	}
+	var hashes []string
+	DB.Model(&model.Transaction{}).Where("block_number = ?", 10).Order("tx_hash").Pluck("tx_hash", &hashes)
+	if strings.Join(hashes, ",") != "0xa2,0xa3" {
+		t.Errorf("区块 10 的交易为 %v，期望 [0xa2 0xa3]", hashes)
+	}
+	if n := countRows(t, &model.ERC20Transfer{}, "block_number = ?", 10); n != 2 {
+		t.Errorf("区块 10 有 %d 条转移记录，期望 2 条", n)
+	}
+}
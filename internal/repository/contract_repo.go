@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+// 按地址获取合约，不存在时返回 nil
+func (r *BlockRepository) GetContract(address string) (*model.Contract, error) {
+	var contract model.Contract
+	err := r.db.Where("address = ?", address).First(&contract).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// 更新合约的字节码哈希、字节码大小和检测到的代币标准
+func (r *BlockRepository) UpdateContractCode(address, bytecodeHash string, codeSize int, standards string) error {
+	return r.db.Model(&model.Contract{}).Where("address = ?", address).Updates(map[string]interface{}{
+		"bytecode_hash": bytecodeHash,
+		"code_size":     codeSize,
+		"standards":     standards,
+		"updated_at":    time.Now(),
+	}).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 按地址获取 DEX 交易池，不存在时返回 nil
+func (r *BlockRepository) GetDexPool(address string) (*model.DexPool, error) {
+	var pool model.DexPool
+	err := r.db.Where("address = ?", address).First(&pool).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pool, nil
+}
+
+// 保存 DEX 交易池，池子已存在时覆盖代币对和手续费率
+func (r *BlockRepository) SaveDexPool(pool *model.DexPool) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"protocol", "token0", "token1", "fee"}),
+	}).Create(pool).Error
+}
+
+// 分页查询地址参与的 DEX 兑换（交易发起方、调用方或接收方），按区块号倒序
+func (r *BlockRepository) ListSwapsByAddress(address string, page, size int) ([]model.DexSwap, int64, error) {
+	query := r.db.Model(&model.DexSwap{}).
+		Where("tx_from = ? OR sender = ? OR recipient = ?", address, address, address)
+	return listSwaps(query, page, size)
+}
+
+// 分页查询池子的 DEX 兑换，按区块号倒序
+func (r *BlockRepository) ListSwapsByPool(pool string, page, size int) ([]model.DexSwap, int64, error) {
+	return listSwaps(r.db.Model(&model.DexSwap{}).Where("pool = ?", pool), page, size)
+}
+
+func listSwaps(query *gorm.DB, page, size int) ([]model.DexSwap, int64, error) {
+	var swaps []model.DexSwap
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("block_number DESC, log_index DESC").
+		Offset(offset).Limit(size).Find(&swaps).Error; err != nil {
+		return nil, 0, err
+	}
+	return swaps, total, nil
+}
+
+// 分页查询池子的流动性事件，event 不为空时按事件类型筛选，按区块号倒序
+func (r *BlockRepository) ListPoolEvents(pool, event string, page, size int) ([]model.DexPoolEvent, int64, error) {
+	var events []model.DexPoolEvent
+	var total int64
+
+	query := r.db.Model(&model.DexPoolEvent{}).Where("pool = ?", pool)
+	if event != "" {
+		query = query.Where("event = ?", event)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("block_number DESC, log_index DESC").
+		Offset(offset).Limit(size).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// 池子最近一次 Sync 事件，没有时返回 nil
+func (r *BlockRepository) GetLatestPoolSync(pool string) (*model.DexPoolEvent, error) {
+	var event model.DexPoolEvent
+	err := r.db.Where("pool = ? AND event = ?", pool, model.DexEventSync).
+		Order("block_number DESC, log_index DESC").First(&event).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event, nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+)
+
+// 分页查询地址作为发送方或接收方的内部交易，按区块号倒序
+func (r *BlockRepository) ListInternalTransactionsByAddress(address string, page, size int) ([]model.InternalTransaction, int64, error) {
+	var internals []model.InternalTransaction
+	var total int64
+
+	query := r.db.Model(&model.InternalTransaction{}).Where("from_address = ? OR to_address = ?", address, address)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("block_number DESC, id DESC").
+		Offset(offset).Limit(size).Find(&internals).Error; err != nil {
+		return nil, 0, err
+	}
+	return internals, total, nil
+}
+
+// 查询交易的内部交易，按调用顺序排列
+func (r *BlockRepository) ListInternalTransactionsByTx(txHash string) ([]model.InternalTransaction, error) {
+	internals := make([]model.InternalTransaction, 0)
+	err := r.db.Where("tx_hash = ?", txHash).Order("id").Find(&internals).Error
+	return internals, err
+}
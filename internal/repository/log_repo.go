@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// 事件日志查询条件，空字段表示不筛选
+type LogFilter struct {
+	Address   string
+	EventName string
+	Topics    [4]string // topic0 ~ topic3
+	FromBlock *int64
+	ToBlock   *int64
+}
+
+// 分页查询事件日志，按区块号和日志序号倒序
+func (r *BlockRepository) ListLogs(filter LogFilter, page, size int) ([]model.Log, int64, error) {
+	var logs []model.Log
+	var total int64
+
+	query := r.db.Model(&model.Log{})
+	if filter.Address != "" {
+		query = query.Where("address = ?", filter.Address)
+	}
+	if filter.EventName != "" {
+		query = query.Where("event_name = ?", filter.EventName)
+	}
+	for i, topic := range filter.Topics {
+		if topic != "" {
+			query = query.Where(fmt.Sprintf("topic%d = ?", i), topic)
+		}
+	}
+	if filter.FromBlock != nil {
+		query = query.Where("block_number >= ?", *filter.FromBlock)
+	}
+	if filter.ToBlock != nil {
+		query = query.Where("block_number <= ?", *filter.ToBlock)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("block_number DESC, log_index DESC").
+		Offset(offset).Limit(size).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// 按 id 顺序分批读取合约的事件日志，返回 id 大于 afterID 的至多 limit 条
+func (r *BlockRepository) ListContractLogsAfter(address string, afterID int64, limit int) ([]model.Log, error) {
+	var logs []model.Log
+	err := r.db.Where("address = ? AND id > ?", address, afterID).Order("id").Limit(limit).Find(&logs).Error
+	return logs, err
+}
+
+// 更新事件日志的解码结果
+func (r *BlockRepository) UpdateLogDecoding(id int64, eventName, eventSignature string, args model.JSONText) error {
+	return r.db.Model(&model.Log{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"event_name":      eventName,
+		"event_signature": eventSignature,
+		"args":            args,
+	}).Error
+}
+
+// 按地址获取合约 ABI，未登记时返回 nil
+func (r *BlockRepository) GetContractABI(address string) (*model.ContractABI, error) {
+	var contractABI model.ContractABI
+	err := r.db.Where("address = ?", address).First(&contractABI).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &contractABI, nil
+}
+
+// 保存合约 ABI，已登记时覆盖
+func (r *BlockRepository) SaveContractABI(contractABI *model.ContractABI) error {
+	contractABI.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "abi", "updated_at"}),
+	}).Create(contractABI).Error
+}
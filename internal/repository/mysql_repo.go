@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
@@ -34,12 +35,34 @@ func InitMySQL() error {
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetConnMaxLifetime(30 * time.Minute)
 
+	if err := migrateGasPriceToWei(); err != nil {
+		return fmt.Errorf("迁移交易 gas_price 失败: %v", err)
+	}
+	if err := migrateLegacyERC20Transfers(); err != nil {
+		return fmt.Errorf("迁移ERC20转移记录失败: %v", err)
+	}
+
 	// 自动迁移数据库表
 	err = DB.AutoMigrate(
 		&model.QueryRecord{},
 		&model.Block{},
 		&model.Transaction{},
 		&model.ERC20Transfer{},
+		&model.NFTTransfer{},
+		&model.InternalTransaction{},
+		&model.DexPool{},
+		&model.DexSwap{},
+		&model.DexPoolEvent{},
+		&model.ApprovalEvent{},
+		&model.TokenApproval{},
+		&model.Contract{},
+		&model.Withdrawal{},
+		&model.Log{},
+		&model.ContractABI{},
+		&model.Token{},
+		&model.ReorgEvent{},
+		&model.ScanProgress{},
+		&model.FailedBlock{},
 	)
 	if err != nil {
 		return fmt.Errorf("数据库迁移失败: %v", err)
@@ -49,6 +72,86 @@ func InitMySQL() error {
 	return nil
 }
 
+// 旧版本的 transactions.gas_price 以 ETH 保存（decimal(65,30)），自动迁移改为 wei 的 decimal(65,0) 时小数部分会被截断，
+// 因此先把已有数据乘以 1e18 换算为 wei，再立即修改列类型。列的小数位数为 0 后不再执行
+func migrateGasPriceToWei() error {
+	migrator := DB.Migrator()
+	if !migrator.HasTable(&model.Transaction{}) {
+		return nil
+	}
+	columns, err := migrator.ColumnTypes(&model.Transaction{})
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Name() != "gas_price" {
+			continue
+		}
+		if _, scale, ok := column.DecimalSize(); !ok || scale == 0 {
+			return nil
+		}
+
+		// 旧数据由浮点数换算得到，乘以 1e18 后取整
+		err := DB.Exec("UPDATE transactions SET gas_price = ROUND(gas_price * 1000000000000000000) WHERE gas_price IS NOT NULL").Error
+		if err != nil {
+			return err
+		}
+		if err := migrator.AlterColumn(&model.Transaction{}, "GasPrice"); err != nil {
+			return err
+		}
+		util.Log.Info("已将交易 gas_price 从 ETH 换算为 wei")
+		return nil
+	}
+	return nil
+}
+
+// 旧版本的 erc20_transfers 没有 log_index 和 block_number 列，一笔交易的多条转移记录迁移后 log_index 均为 0，
+// 添加 (tx_hash, log_index) 唯一索引会因重复而失败，block_number 为 0 的记录在重新扫描时也不会被替换。
+// 因此删除这些记录，并把它们所在的区块加入失败区块队列，由重试任务重新扫描补全
+func migrateLegacyERC20Transfers() error {
+	migrator := DB.Migrator()
+	if !migrator.HasTable(&model.ERC20Transfer{}) || migrator.HasColumn(&model.ERC20Transfer{}, "LogIndex") {
+		return nil
+	}
+	if err := DB.AutoMigrate(&model.FailedBlock{}); err != nil {
+		return err
+	}
+
+	var blocks []int64
+	if migrator.HasTable(&model.Transaction{}) {
+		err := DB.Raw("SELECT DISTINCT t.block_number FROM erc20_transfers e JOIN transactions t ON t.tx_hash = e.tx_hash ORDER BY t.block_number").
+			Scan(&blocks).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		failed := make([]model.FailedBlock, 0, len(blocks))
+		for _, number := range blocks {
+			failed = append(failed, model.FailedBlock{
+				BlockNumber: number,
+				LastError:   "旧版本的ERC20转移记录已删除，等待重新扫描",
+				Status:      model.FailedBlockPending,
+				NextRetryAt: now,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			})
+		}
+		if len(failed) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(failed, 500).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DELETE FROM erc20_transfers").Error; err != nil {
+			return err
+		}
+		util.Log.Infof("已删除旧版本的ERC20转移记录，%d 个区块等待重新扫描", len(blocks))
+		return nil
+	})
+}
+
 // 保存查询记录
 func SaveQueryRecord(record model.QueryRecord) error {
 	err := DB.Create(&record).Error
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/testutil"
+	"blockchain-asset-api/internal/util"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"io"
+	"testing"
+)
+
+// 启动空数据库并返回连接，用于在迁移前按旧版本结构建表
+func openLegacyDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	util.InitLog()
+	util.Log.SetOutput(io.Discard)
+	config.Cfg.MySQL.DSN = testutil.StartMySQL(t)
+
+	db, err := gorm.Open(mysql.Open(config.Cfg.MySQL.DSN), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	return db
+}
+
+func TestMigrateGasPriceFromEthToWei(t *testing.T) {
+	// 旧版本的交易表，gas_price 以 ETH 保存
+	db := openLegacyDB(t)
+	err := db.Exec(`CREATE TABLE transactions (
+		id bigint AUTO_INCREMENT PRIMARY KEY,
+		tx_hash varchar(66),
+		block_number bigint,
+		gas_price decimal(65,30) NOT NULL
+	)`).Error
+	if err != nil {
+		t.Fatalf("创建旧交易表失败: %v", err)
+	}
+	err = db.Exec("INSERT INTO transactions (tx_hash, block_number, gas_price) VALUES ('0xa1', 1, 0.00000002), ('0xa2', 1, 0.000000001234567891)").Error
+	if err != nil {
+		t.Fatalf("写入旧交易失败: %v", err)
+	}
+
+	// 迁移只执行一次，重复初始化不会再次换算
+	for i := 0; i < 2; i++ {
+		if err := InitMySQL(); err != nil {
+			t.Fatalf("初始化数据库失败: %v", err)
+		}
+	}
+
+	var txs []model.Transaction
+	if err := DB.Order("tx_hash").Find(&txs).Error; err != nil {
+		t.Fatalf("查询交易失败: %v", err)
+	}
+	want := []string{"20000000000", "1234567891"}
+	if len(txs) != len(want) {
+		t.Fatalf("迁移后有 %d 笔交易，期望 %d 笔", len(txs), len(want))
+	}
+	for i, tx := range txs {
+		if tx.GasPrice == nil || *tx.GasPrice != want[i] {
+			t.Errorf("交易 %s 的 gas_price 为 %v，期望 %s wei", tx.TxHash, tx.GasPrice, want[i])
+		}
+	}
+}
+
+func TestMigrateLegacyERC20Transfers(t *testing.T) {
+	// 旧版本的转移记录表没有 log_index 和 block_number，一笔交易有多条转移记录
+	db := openLegacyDB(t)
+	statements := []string{
+		`CREATE TABLE transactions (
+			id bigint AUTO_INCREMENT PRIMARY KEY,
+			tx_hash varchar(66),
+			block_number bigint,
+			gas_price decimal(65,30) NOT NULL
+		)`,
+		`CREATE TABLE erc20_transfers (
+			id bigint AUTO_INCREMENT PRIMARY KEY,
+			tx_hash varchar(66),
+			from_address varchar(42),
+			to_address varchar(42),
+			contract_address varchar(42),
+			amount decimal(65,30),
+			created_at datetime
+		)`,
+		"INSERT INTO transactions (tx_hash, block_number, gas_price) VALUES ('0xa1', 7, 0), ('0xa2', 9, 0), ('0xa3', 9, 0)",
+		"INSERT INTO erc20_transfers (tx_hash, amount) VALUES ('0xa1', 1), ('0xa1', 2), ('0xa2', 3), ('0xa3', 4)",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("创建旧版本数据失败: %v", err)
+		}
+	}
+
+	if err := InitMySQL(); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+
+	if n := countRows(t, &model.ERC20Transfer{}, "1 = 1"); n != 0 {
+		t.Errorf("迁移后残留 %d 条旧转移记录", n)
+	}
+	blocks, total, err := NewBlockRepository().ListFailedBlocks(model.FailedBlockPending, 1, 10)
+	if err != nil || total != 2 || blocks[0].BlockNumber != 7 || blocks[1].BlockNumber != 9 {
+		t.Fatalf("待重新扫描的区块为 %+v（%v），期望区块 7 和 9", blocks, err)
+	}
+
+	// 迁移后按区块保存的转移记录正常写入
+	if err := NewBlockRepository().SaveBlockData(testBlockData(9, "0xa2")); err != nil {
+		t.Fatalf("保存区块失败: %v", err)
+	}
+	if n := countRows(t, &model.ERC20Transfer{}, "block_number = ?", 9); n != 1 {
+		t.Errorf("区块 9 有 %d 条转移记录，期望 1 条", n)
+	}
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+)
+
+// NFT转移记录查询条件，空字段不参与筛选
+type NFTTransferFilter struct {
+	Address  string // 发送方或接收方
+	Contract string // 合约地址
+	TokenID  string // 代币 ID（十进制）
+}
+
+// 分页查询NFT转移记录，按区块号和日志序号倒序
+func (r *BlockRepository) ListNFTTransfers(filter NFTTransferFilter, page, size int) ([]model.NFTTransfer, int64, error) {
+	var transfers []model.NFTTransfer
+	var total int64
+
+	query := r.db.Model(&model.NFTTransfer{})
+	if filter.Address != "" {
+		query = query.Where("from_address = ? OR to_address = ?", filter.Address, filter.Address)
+	}
+	if filter.Contract != "" {
+		query = query.Where("contract_address = ?", filter.Contract)
+	}
+	if filter.TokenID != "" {
+		query = query.Where("token_id = ?", filter.TokenID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("block_number DESC, log_index DESC, batch_index").
+		Offset(offset).Limit(size).Find(&transfers).Error; err != nil {
+		return nil, 0, err
+	}
+	return transfers, total, nil
+}
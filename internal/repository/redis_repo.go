@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-redis/redis/v8"
+	"math/big"
 	_ "time"
 )
 
@@ -29,28 +30,36 @@ func InitRedis() error {
 	return nil
 }
 
+// 余额缓存 key，指定区块时附加区块号（历史余额不会变化，与最新余额分开缓存）
+func balanceCacheKey(key string, blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return key
+	}
+	return fmt.Sprintf("%s:%s", key, blockNumber)
+}
+
 // 缓存 ETH 余额
-func SetEthBalanceCache(address, balance string) error {
-	key := fmt.Sprintf("eth:balance:%s", address)
+func SetEthBalanceCache(address string, blockNumber *big.Int, balance string) error {
+	key := balanceCacheKey(fmt.Sprintf("eth:balance:%s", address), blockNumber)
 	return RedisClient.Set(ctx, key, balance, config.Cfg.Redis.Expire).Err()
 }
 
 // 获取缓存的 ETH 余额
-func GetEthBalanceCache(address string) (string, error) {
-	key := fmt.Sprintf("eth:balance:%s", address)
+func GetEthBalanceCache(address string, blockNumber *big.Int) (string, error) {
+	key := balanceCacheKey(fmt.Sprintf("eth:balance:%s", address), blockNumber)
 	return RedisClient.Get(ctx, key).Result()
 }
 
 // 缓存 ERC20 代币余额
-func SetErc20BalanceCache(address, contractAddress, balance string) error {
-	key := fmt.Sprintf("erc20:balance:%s:%s", contractAddress, address)
+func SetErc20BalanceCache(address, contractAddress string, blockNumber *big.Int, balance string) error {
+	key := balanceCacheKey(fmt.Sprintf("erc20:balance:%s:%s", contractAddress, address), blockNumber)
 	// 设置缓存有效期
 	return RedisClient.Set(ctx, key, balance, config.Cfg.Redis.Expire).Err()
 }
 
 // 获取缓存的 ERC20 代币余额
-func GetErc20BalanceCache(address, contractAddress string) (string, error) {
-	key := fmt.Sprintf("erc20:balance:%s:%s", contractAddress, address)
+func GetErc20BalanceCache(address, contractAddress string, blockNumber *big.Int) (string, error) {
+	key := balanceCacheKey(fmt.Sprintf("erc20:balance:%s:%s", contractAddress, address), blockNumber)
 	return RedisClient.Get(ctx, key).Result()
 }
 
@@ -65,3 +74,23 @@ func GetBlockCache(blockNum string) (string, error) {
 	key := fmt.Sprintf("block:%s", blockNum)
 	return RedisClient.Get(ctx, key).Result()
 }
+
+// 缓存地址的代币组合，key 区分代币集合
+func SetPortfolioCache(key, portfolio string) error {
+	return RedisClient.Set(ctx, fmt.Sprintf("portfolio:%s", key), portfolio, config.Cfg.Redis.Expire).Err()
+}
+
+// 获取缓存的代币组合
+func GetPortfolioCache(key string) (string, error) {
+	return RedisClient.Get(ctx, fmt.Sprintf("portfolio:%s", key)).Result()
+}
+
+// 缓存时间戳对应的区块（序列化后存储），只缓存不会随新区块变化的结果
+func SetBlockByTimeCache(key, block string) error {
+	return RedisClient.Set(ctx, fmt.Sprintf("block:time:%s", key), block, config.Cfg.Redis.Expire).Err()
+}
+
+// 获取缓存的时间戳对应区块
+func GetBlockByTimeCache(key string) (string, error) {
+	return RedisClient.Get(ctx, fmt.Sprintf("block:time:%s", key)).Result()
+}
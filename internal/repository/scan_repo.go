@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// 默认扫描任务的断点名称
+const DefaultScanName = "default"
+
+// 获取扫描断点，ok 为 false 表示尚无记录
+func (r *BlockRepository) GetScanProgress(name string) (lastBlock int64, ok bool, err error) {
+	var progress model.ScanProgress
+	err = r.db.Where("name = ?", name).First(&progress).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return progress.LastBlock, true, nil
+}
+
+// 保存扫描断点，只会向前推进（重新扫描旧区间不会回退断点）
+func (r *BlockRepository) SaveScanProgress(name string, lastBlock int64) error {
+	progress := &model.ScanProgress{Name: name, LastBlock: lastBlock, UpdatedAt: time.Now()}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_block": gorm.Expr("GREATEST(last_block, VALUES(last_block))"),
+			"updated_at": progress.UpdatedAt,
+		}),
+	}).Create(progress).Error
+}
+
+// 记录扫描失败的区块；已存在时累加重试次数，超过 maxRetries 后标记为 stuck
+func (r *BlockRepository) RecordFailedBlock(blockNumber int64, errMsg string, maxRetries int, backoff func(retryCount int) time.Duration) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var failed model.FailedBlock
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("block_number = ?", blockNumber).First(&failed).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		now := time.Now()
+		if err == gorm.ErrRecordNotFound {
+			failed = model.FailedBlock{
+				BlockNumber: blockNumber,
+				Status:      model.FailedBlockPending,
+				CreatedAt:   now,
+			}
+		} else {
+			failed.RetryCount++
+		}
+
+		failed.LastError = errMsg
+		failed.NextRetryAt = now.Add(backoff(failed.RetryCount))
+		failed.UpdatedAt = now
+		if failed.RetryCount >= maxRetries {
+			failed.Status = model.FailedBlockStuck
+		}
+
+		return tx.Save(&failed).Error
+	})
+}
+
+// 获取已到重试时间的失败区块
+func (r *BlockRepository) ListDueFailedBlocks(now time.Time, limit int) ([]model.FailedBlock, error) {
+	var blocks []model.FailedBlock
+	err := r.db.Where("status = ? AND next_retry_at <= ?", model.FailedBlockPending, now).
+		Order("block_number").Limit(limit).Find(&blocks).Error
+	return blocks, err
+}
+
+// 分页查询失败区块，status 为空时返回全部
+func (r *BlockRepository) ListFailedBlocks(status string, page, size int) ([]model.FailedBlock, int64, error) {
+	var blocks []model.FailedBlock
+	var total int64
+
+	query := r.db.Model(&model.FailedBlock{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("block_number").Offset(offset).Limit(size).Find(&blocks).Error; err != nil {
+		return nil, 0, err
+	}
+	return blocks, total, nil
+}
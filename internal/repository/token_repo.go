@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository() *TokenRepository {
+	return &TokenRepository{db: GetDB()}
+}
+
+// 按合约地址获取代币元数据，不存在时返回 nil
+func (r *TokenRepository) GetToken(contractAddress string) (*model.Token, error) {
+	var token model.Token
+	err := r.db.Where("contract_address = ?", contractAddress).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// 批量获取代币元数据，按合约地址索引，未登记的合约不在结果中
+func (r *TokenRepository) GetTokens(contractAddresses []string) (map[string]*model.Token, error) {
+	tokens := make(map[string]*model.Token)
+	if len(contractAddresses) == 0 {
+		return tokens, nil
+	}
+
+	var list []model.Token
+	if err := r.db.Where("contract_address IN ?", contractAddresses).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for i := range list {
+		tokens[list[i].ContractAddress] = &list[i]
+	}
+	return tokens, nil
+}
+
+// 保存代币元数据，合约已存在时覆盖
+func (r *TokenRepository) SaveToken(token *model.Token) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "contract_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "symbol", "decimals", "total_supply", "updated_at"}),
+	}).Create(token).Error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+)
+
+// 查询多笔交易的ERC20转移记录，按交易哈希和日志序号排列
+func (r *BlockRepository) ListERC20TransfersByTx(txHashes []string) ([]model.ERC20Transfer, error) {
+	transfers := make([]model.ERC20Transfer, 0)
+	if len(txHashes) == 0 {
+		return transfers, nil
+	}
+	err := r.db.Where("tx_hash IN ?", txHashes).Order("tx_hash, log_index").Find(&transfers).Error
+	return transfers, err
+}
+
+// 查询交易的NFT转移记录，按日志序号排列
+func (r *BlockRepository) ListNFTTransfersByTx(txHash string) ([]model.NFTTransfer, error) {
+	var transfers []model.NFTTransfer
+	err := r.db.Where("tx_hash = ?", txHash).Order("log_index, batch_index").Find(&transfers).Error
+	return transfers, err
+}
+
+// 查询地址转入或转出过的全部ERC20合约地址
+func (r *BlockRepository) ListTokenContractsByAddress(address string) ([]string, error) {
+	var contracts []string
+	err := r.db.Model(&model.ERC20Transfer{}).
+		Where("from_address = ? OR to_address = ?", address, address).
+		Distinct().Order("contract_address").
+		Pluck("contract_address", &contracts).Error
+	return contracts, err
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"blockchain-asset-api/internal/model"
+)
+
+// 分页查询地址收到的信标链提款，按区块号倒序，blockNumber 不为空时只查询该区块
+func (r *BlockRepository) ListWithdrawalsByAddress(address string, blockNumber *int64, page, size int) ([]model.Withdrawal, int64, error) {
+	var withdrawals []model.Withdrawal
+	var total int64
+
+	query := r.db.Model(&model.Withdrawal{}).Where("address = ?", address)
+	if blockNumber != nil {
+		query = query.Where("block_number = ?", *blockNumber)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("withdrawal_index DESC").
+		Offset(offset).Limit(size).Find(&withdrawals).Error; err != nil {
+		return nil, 0, err
+	}
+	return withdrawals, total, nil
+}
+
+// 查询区块内的信标链提款，按提款序号排列
+func (r *BlockRepository) ListWithdrawalsByBlock(blockNumber int64) ([]model.Withdrawal, error) {
+	withdrawals := make([]model.Withdrawal, 0)
+	err := r.db.Where("block_number = ?", blockNumber).Order("withdrawal_index").Find(&withdrawals).Error
+	return withdrawals, err
+}
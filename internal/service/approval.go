@@ -0,0 +1,130 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"time"
+)
+
+// 额度不低于 2^255 视为无限授权：无限授权通常为 uint256 最大值，部分代币转账时会从中扣减
+var unlimitedAllowance = new(big.Int).Lsh(big.NewInt(1), 255)
+
+// 部分代币（如 UNI、COMP）以 uint96 保存额度，授权 uint256 最大值时记为 uint96 最大值
+var uint96Max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 96), big.NewInt(1))
+
+// 判断额度是否为无限授权
+func isUnlimitedAllowance(amount *big.Int) bool {
+	return amount.Cmp(unlimitedAllowance) >= 0 || amount.Cmp(uint96Max) == 0
+}
+
+// 处理授权事件：ERC20 Approval（3 个 topic，额度在 data 中）和 ApprovalForAll。
+// ERC-721 单个代币的 Approval（4 个 topic）在代币转移时自动清除，不记录
+func (s *BlockScanner) processApprovals(data *repository.BlockData, tx *types.Transaction, receipt *types.Receipt) {
+	for _, log := range receipt.Logs {
+		if len(log.Topics) != 3 || len(log.Data) < 32 {
+			continue
+		}
+
+		event := &model.ApprovalEvent{
+			TxHash:      tx.Hash().Hex(),
+			LogIndex:    log.Index,
+			BlockNumber: data.Block.BlockNumber,
+			Owner:       common.BytesToAddress(log.Topics[1].Bytes()).Hex(),
+			Spender:     common.BytesToAddress(log.Topics[2].Bytes()).Hex(),
+			Token:       log.Address.Hex(),
+			CreatedAt:   time.Now(),
+		}
+		value := new(big.Int).SetBytes(log.Data[:32])
+		switch log.Topics[0] {
+		case approvalTopic:
+			amount := value.String()
+			event.Kind = model.ApprovalKindERC20
+			event.Amount = &amount
+		case approvalForAllTopic:
+			event.Kind = model.ApprovalKindForAll
+		default:
+			continue
+		}
+		event.Approved = value.Sign() > 0
+		data.ApprovalEvents = append(data.ApprovalEvents, event)
+	}
+}
+
+// 地址的授权，附带通过 eth_call 查询的当前状态
+type ApprovalInfo struct {
+	model.TokenApproval
+	TokenSymbol string `json:"token_symbol,omitempty"`
+	// ERC20 当前授权额度（allowance），ApprovalForAll 或查询失败时为 null
+	Allowance          *string `json:"allowance"`
+	FormattedAllowance string  `json:"formatted_allowance,omitempty"`
+	// ApprovalForAll 当前是否仍然有效（isApprovedForAll），ERC20 或查询失败时为 null
+	ApprovedForAll *bool `json:"approved_for_all"`
+	// 无限授权：ERC20 额度不低于 2^255（或为 uint96 最大值），或 ApprovalForAll 仍然有效；查询失败时按最近一次授权事件判断
+	Unlimited bool `json:"unlimited"`
+}
+
+// GetAddressApprovals 分页查询地址当前有效的授权，并通过 eth_call 查询每个授权的当前额度。
+// 额度可能因 transferFrom 扣减而与授权事件不同，额度已用完的授权仍然返回
+func GetAddressApprovals(ctx context.Context, address string, page, size int) ([]ApprovalInfo, int64, error) {
+	approvals, total, err := repository.NewBlockRepository().ListActiveApprovals(common.HexToAddress(address).Hex(), page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	queries := make([]util.AllowanceQuery, len(approvals))
+	contracts := make([]string, 0, len(approvals))
+	for i, approval := range approvals {
+		queries[i] = util.AllowanceQuery{
+			Token:   common.HexToAddress(approval.Token),
+			Owner:   common.HexToAddress(approval.Owner),
+			Spender: common.HexToAddress(approval.Spender),
+			ForAll:  approval.Kind == model.ApprovalKindForAll,
+		}
+		contracts = append(contracts, approval.Token)
+	}
+	allowances, err := util.GetAllowances(ctx, queries)
+	if err != nil {
+		return nil, 0, err
+	}
+	tokens, err := repository.NewTokenRepository().GetTokens(contracts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询代币元数据失败: %v", err)
+	}
+
+	infos := make([]ApprovalInfo, len(approvals))
+	for i, approval := range approvals {
+		info := ApprovalInfo{TokenApproval: approval}
+		token := tokens[approval.Token]
+		if token != nil {
+			info.TokenSymbol = token.Symbol
+		}
+
+		live := allowances[i]
+		if approval.Kind == model.ApprovalKindForAll {
+			info.Unlimited = approval.Approved
+			if live != nil {
+				approved := live.Sign() > 0
+				info.ApprovedForAll = &approved
+				info.Unlimited = approved
+			}
+		} else {
+			amount := live
+			if live != nil {
+				allowance := live.String()
+				info.Allowance = &allowance
+				info.FormattedAllowance = formatTokenAmount(token, allowance)
+			} else if approval.Amount != nil {
+				amount, _ = new(big.Int).SetString(*approval.Amount, 10)
+			}
+			info.Unlimited = amount != nil && isUnlimitedAllowance(amount)
+		}
+		infos[i] = info
+	}
+	return infos, total, nil
+}
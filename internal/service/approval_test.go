@@ -0,0 +1,111 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"testing"
+)
+
+// 模拟授权查询：allowance(owner, spender) / isApprovedForAll(owner, operator) 按 spender 返回固定结果
+func fakeAllowances(method string, results map[common.Address][]byte) testutil.ContractFunc {
+	selector := crypto.Keccak256([]byte(method))[:4]
+	return func(data []byte) ([]byte, error) {
+		if !bytes.HasPrefix(data, selector) || len(data) < 68 {
+			return nil, errors.New("revert")
+		}
+		if result, ok := results[common.BytesToAddress(data[36:68])]; ok {
+			return result, nil
+		}
+		return uint256Word(0), nil
+	}
+}
+
+func TestScanTracksApprovals(t *testing.T) {
+	chain := setupScanTest(t)
+
+	token := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	nft := common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000a11")
+	router := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	vault := common.HexToAddress("0x0000000000000000000000000000000000000c02")
+	operator := common.HexToAddress("0x0000000000000000000000000000000000000c03")
+	spent := new(big.Int).Sub(math.MaxBig256, big.NewInt(5))
+	chain.SetContract(token, fakeAllowances("allowance(address,address)", map[common.Address][]byte{
+		router: uint256Word(400),
+		vault:  math.U256Bytes(spent),
+	}))
+	chain.SetContract(nft, fakeAllowances("isApprovedForAll(address,address)", map[common.Address][]byte{
+		operator: uint256Word(1),
+	}))
+
+	chain.Mine(testutil.Tx{To: router, Data: []byte{0x01}, Logs: []*types.Log{
+		{Address: token, Topics: []common.Hash{approvalTopic, addressTopic(alice), addressTopic(router)}, Data: uint256Word(1000)},
+		{Address: token, Topics: []common.Hash{approvalTopic, addressTopic(alice), addressTopic(vault)}, Data: math.U256Bytes(math.MaxBig256)},
+		{Address: nft, Topics: []common.Hash{approvalForAllTopic, addressTopic(alice), addressTopic(operator)}, Data: uint256Word(1)},
+		// ERC-721 单个代币的授权不记录
+		{Address: nft, Topics: []common.Hash{approvalTopic, addressTopic(alice), addressTopic(router), common.BigToHash(big.NewInt(7))}},
+	}})
+	// 撤销对 router 的授权
+	chain.Mine(testutil.Tx{To: router, Data: []byte{0x02}, Logs: []*types.Log{
+		{Address: token, Topics: []common.Hash{approvalTopic, addressTopic(alice), addressTopic(router)}, Data: uint256Word(0)},
+	}})
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 2); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	if n := countTable(t, &model.ApprovalEvent{}); n != 4 {
+		t.Errorf("记录了 %d 条授权事件，期望 4 条", n)
+	}
+	approvals, total, err := GetAddressApprovals(context.Background(), alice.Hex(), 1, 10)
+	if err != nil || total != 2 {
+		t.Fatalf("有效授权 %d 条（%v），期望 2 条", total, err)
+	}
+	byTokenSpender := make(map[string]ApprovalInfo)
+	for _, approval := range approvals {
+		byTokenSpender[approval.Token+approval.Spender] = approval
+	}
+	if a, ok := byTokenSpender[token.Hex()+vault.Hex()]; !ok || a.Kind != model.ApprovalKindERC20 ||
+		deref(a.Allowance) != spent.String() || !a.Unlimited || a.ApprovedForAll != nil {
+		t.Errorf("vault 的授权为 %+v", a)
+	}
+	if a, ok := byTokenSpender[nft.Hex()+operator.Hex()]; !ok || a.Kind != model.ApprovalKindForAll ||
+		a.ApprovedForAll == nil || !*a.ApprovedForAll || !a.Unlimited || a.Allowance != nil {
+		t.Errorf("operator 的授权为 %+v", a)
+	}
+
+	// 回滚撤销授权的区块后，router 的授权恢复为区块 1 的状态
+	if err := repository.NewBlockRepository().RollbackToBlock(1); err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	approvals, total, err = GetAddressApprovals(context.Background(), alice.Hex(), 1, 10)
+	if err != nil || total != 3 {
+		t.Fatalf("回滚后有效授权 %d 条（%v），期望 3 条", total, err)
+	}
+	for _, a := range approvals {
+		if a.Spender != router.Hex() {
+			continue
+		}
+		if deref(a.Amount) != "1000" || deref(a.Allowance) != "400" || a.Unlimited || a.BlockNumber != 1 {
+			t.Errorf("回滚后 router 的授权为 %+v，当前额度 %s", a.TokenApproval, deref(a.Allowance))
+		}
+	}
+
+	// 重新扫描区块 1 不改变授权状态
+	if _, err := scanner.runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("重新扫描失败: %v", err)
+	}
+	if n := countTable(t, &model.TokenApproval{}); n != 3 {
+		t.Errorf("重新扫描后授权状态 %d 条，期望 3 条", n)
+	}
+}
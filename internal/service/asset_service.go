@@ -4,73 +4,115 @@ import (
 	"blockchain-asset-api/internal/model"
 	"blockchain-asset-api/internal/repository"
 	"blockchain-asset-api/internal/util"
+	"context"
 	"encoding/json"
 	"fmt"
 	_ "fmt"
-	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	_ "github.com/ethereum/go-ethereum/core/types"
-	"log"
 	"math/big"
-	"strconv"
 	"time"
 )
 
-// 查询 ETH 余额（优先查缓存，缓存未命中则查区块链）
-func GetEthBalance(address string) (string, error) {
+// 查询 ETH 余额（优先查缓存，缓存未命中则查区块链），blockNumber 为 nil 时查询最新区块
+func GetEthBalance(address string, blockNumber *big.Int) (string, error) {
 	// 1. 查缓存
-	cacheBalance, err := repository.GetEthBalanceCache(address)
+	cacheBalance, err := repository.GetEthBalanceCache(address, blockNumber)
 	if err == nil && cacheBalance != "" {
 		util.Log.Infof("从缓存获取 ETH 余额: address=%s, balance=%s", address, cacheBalance)
 		return cacheBalance, nil
 	}
 
 	// 2. 查区块链
-	balance, err := util.GetEthBalance(address)
+	balance, err := util.GetEthBalance(address, blockNumber)
 	if err != nil {
 		return "", err
 	}
 
 	// 3. 写入缓存
-	if err := repository.SetEthBalanceCache(address, balance); err != nil {
+	if err := repository.SetEthBalanceCache(address, blockNumber, balance); err != nil {
 		util.Log.Warnf("缓存 ETH 余额失败: address=%s, err=%v", address, err)
 	}
 
 	// 4. 保存查询记录
 	_ = repository.SaveQueryRecord(model.QueryRecord{
-		Address:   address,
-		QueryType: "eth_balance",
-		CreatedAt: time.Now(),
+		Address:    address,
+		QueryType:  "eth_balance",
+		QueryParam: blockParam(blockNumber),
+		CreatedAt:  time.Now(),
 	})
 
 	return balance, nil
 }
 
-// 查询 ERC20 代币余额
-func GetErc20Balance(address, contractAddress string) (string, error) {
+// 查询记录中的区块参数，最新区块为空
+func blockParam(blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return ""
+	}
+	return blockNumber.String()
+}
+
+// TokenBalance ERC20 代币余额，Balance 为最小单位的原始数值
+type TokenBalance struct {
+	Balance          string `json:"token_balance"`
+	FormattedBalance string `json:"formatted_balance"`
+	Name             string `json:"name"`
+	Symbol           string `json:"symbol"`
+	Decimals         *int   `json:"decimals"`
+}
+
+// 查询 ERC20 代币余额，同时返回按小数位数换算后的余额和代币符号，blockNumber 为 nil 时查询最新区块
+func GetErc20Balance(address, contractAddress string, blockNumber *big.Int) (*TokenBalance, error) {
+	balance, err := getErc20RawBalance(address, contractAddress, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TokenBalance{Balance: balance}
+	// 元数据查询失败时仍返回原始余额
+	token, err := GetToken(context.Background(), contractAddress)
+	if err != nil {
+		util.Log.Warnf("获取代币元数据失败: contract=%s, err=%v", contractAddress, err)
+		return result, nil
+	}
+	result.Name = token.Name
+	result.Symbol = token.Symbol
+	result.Decimals = token.Decimals
+	result.FormattedBalance = formatTokenAmount(token, balance)
+	return result, nil
+}
+
+// 查询 ERC20 代币的原始余额（优先查缓存）
+func getErc20RawBalance(address, contractAddress string, blockNumber *big.Int) (string, error) {
 	// 1. 查缓存
-	cacheBalance, err := repository.GetErc20BalanceCache(address, contractAddress)
+	cacheBalance, err := repository.GetErc20BalanceCache(address, contractAddress, blockNumber)
 	if err == nil && cacheBalance != "" {
 		util.Log.Infof("从缓存获取 ERC20 余额: address=%s, contract=%s, balance=%s", address, contractAddress, cacheBalance)
 		return cacheBalance, nil
 	}
 
 	// 2. 查区块链
-	balance, err := util.GetErc20Balance(address, contractAddress)
+	balance, err := util.GetErc20Balance(address, contractAddress, blockNumber)
 	if err != nil {
 		return "", err
 	}
 
 	// 3. 写入缓存
-	if err := repository.SetErc20BalanceCache(address, contractAddress, balance); err != nil {
+	if err := repository.SetErc20BalanceCache(address, contractAddress, blockNumber, balance); err != nil {
 		util.Log.Warnf("缓存 ERC20 余额失败: address=%s, contract=%s, err=%v", address, contractAddress, err)
 	}
 
-	// 4. 保存查询记录
+	// 4. 保存查询记录（指定区块时记录为 合约地址@区块号）
+	queryParam := contractAddress
+	if blockNumber != nil {
+		queryParam += "@" + blockNumber.String()
+	}
 	_ = repository.SaveQueryRecord(model.QueryRecord{
 		Address:    address,
 		QueryType:  "erc20_balance",
-		QueryParam: contractAddress,
+		QueryParam: queryParam,
 		CreatedAt:  time.Now(),
 	})
 
@@ -84,9 +126,18 @@ type TransactionDetail struct {
 	To          string `json:"to"`
 	Value       string `json:"value_eth"`
 	GasUsed     uint64 `json:"gas_used"`
-	GasPrice    string `json:"gas_price_gwei"`
+	GasPrice    string `json:"gas_price_gwei"` // 实际成交的 gas 单价
 	BlockNumber uint64 `json:"block_number"`
 	Status      string `json:"status"` // success / failed
+	// 交易类型、nonce 和费用字段（wei）
+	model.TxFees
+	// 所在区块的基础费用（wei）
+	BaseFeePerGas *string `json:"base_fee_per_gas"`
+	// 调用数据及其解码结果（合约登记的 ABI 优先，其次内置签名库），无法识别时为 null
+	Input        string        `json:"input"`
+	DecodedInput *DecodedInput `json:"decoded_input"`
+	// 回执中的事件日志及其解码结果
+	Logs []DecodedLog `json:"logs"`
 }
 
 func GetTransactionDetail(txHash string) (*TransactionDetail, error) {
@@ -110,8 +161,16 @@ func GetTransactionDetail(txHash string) (*TransactionDetail, error) {
 		return nil, fmt.Errorf("无法恢复交易发送方地址: %v", err)
 	}
 
+	// 基础费用取自交易所在区块，用于计算销毁的费用
+	header, err := util.EthClient.HeaderByNumber(context.Background(), receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易所在区块失败: %v", err)
+	}
+	fees := newTxFees(tx, receipt, header.BaseFee)
+
 	// 转换单位（Wei -> Gwei 用于 gasPrice）
-	gasPriceGwei := new(big.Float).Quo(new(big.Float).SetInt(tx.GasPrice()), big.NewFloat(1e9))
+	effectiveGasPrice, _ := new(big.Int).SetString(*fees.EffectiveGasPrice, 10)
+	gasPriceGwei := new(big.Float).Quo(new(big.Float).SetInt(effectiveGasPrice), big.NewFloat(1e9))
 	gasPriceStr, _ := gasPriceGwei.MarshalText()
 
 	// 交易状态（receipt.Status == 1 表示成功）
@@ -121,36 +180,27 @@ func GetTransactionDetail(txHash string) (*TransactionDetail, error) {
 	}
 
 	util.Log.Infof("交易详情: %+v", tx)
-	// 解析LogDta
-	for i, vlog := range receipt.Logs {
-		if err != nil {
-			log.Printf("日志序列化失败 - Index: %d, Error: %v", i, err)
-			continue
-		}
-		var num string
-		err = util.LogDtaUnpack(0, 64, &num, []byte(common.Bytes2Hex(vlog.Data)))
-		if err != nil {
-			log.Printf("LogDtaUnpack解析失败 - Index: %d, Error: %v", i, err)
-			continue
-		}
-		atoi, err := strconv.ParseInt(num, 16, 64)
-		if err != nil {
-			log.Printf("数值转换失败 - Value: %s, Error: %v", num, err)
-			continue
-		}
-		fmt.Println("TransactionToken === ", atoi)
+	// 合约创建交易没有接收方
+	toAddress := ""
+	if tx.To() != nil {
+		toAddress = tx.To().Hex()
 	}
 
 	detail := &TransactionDetail{
 		TxHash:      tx.Hash().Hex(),
 		From:        fromAddr.Hex(),
-		To:          tx.To().Hex(),
+		To:          toAddress,
 		Value:       util.WeiToEth(tx.Value()),
 		GasUsed:     receipt.GasUsed,
 		GasPrice:    string(gasPriceStr),
 		BlockNumber: receipt.BlockNumber.Uint64(),
 		Status:      status,
 	}
+	detail.TxFees = fees
+	detail.BaseFeePerGas = weiString(header.BaseFee)
+	detail.Input = hexutil.Encode(tx.Data())
+	detail.DecodedInput = decodeTransactionInput(tx.To(), tx.Data())
+	detail.Logs = decodeReceiptLogs(receipt.Logs)
 
 	// 保存查询记录
 	_ = repository.SaveQueryRecord(model.QueryRecord{
@@ -172,6 +222,19 @@ type BlockInfo struct {
 	GasUsed      uint64 `json:"gas_used"`
 	GasLimit     uint64 `json:"gas_limit"`
 	Miner        string `json:"miner"`
+	// EIP-1559 基础费用和区块内销毁的费用（wei），伦敦升级前的区块为空
+	BaseFeePerGas *string `json:"base_fee_per_gas"`
+	BurntFees     *string `json:"burnt_fees"`
+	// 出块者收到的小费总和（wei）
+	PriorityFees *string `json:"priority_fees"`
+	ParentHash   string  `json:"parent_hash"`
+	StateRoot    string  `json:"state_root"`
+	Size         uint64  `json:"size"`
+	// 坎昆升级前的区块为空
+	BlobGasUsed   *uint64 `json:"blob_gas_used"`
+	ExcessBlobGas *uint64 `json:"excess_blob_gas"`
+	// 上海升级后的信标链提款
+	Withdrawals []*model.Withdrawal `json:"withdrawals"`
 }
 
 func GetBlockInfo(blockNum string) (*BlockInfo, error) {
@@ -201,6 +264,26 @@ func GetBlockInfo(blockNum string) (*BlockInfo, error) {
 		GasLimit:     block.GasLimit(),
 		Miner:        block.Coinbase().Hex(),
 	}
+	blockInfo.ParentHash = block.ParentHash().Hex()
+	blockInfo.StateRoot = block.Root().Hex()
+	blockInfo.Size = block.Size()
+	blockInfo.BlobGasUsed = block.BlobGasUsed()
+	blockInfo.ExcessBlobGas = block.ExcessBlobGas()
+	blockInfo.Withdrawals = newWithdrawals(int64(block.NumberU64()), block.Withdrawals())
+	blockInfo.BaseFeePerGas = weiString(block.BaseFee())
+	// 销毁的 blob 费用和小费需要回执中的 blobGasPrice 和 effectiveGasPrice，获取失败时不返回
+	if len(block.Transactions()) > 0 {
+		receipts, err := util.GetBlockReceipts(context.Background(), block.Hash())
+		if err != nil {
+			util.Log.Warnf("获取区块回执失败，无法计算区块费用: blockNum=%s, err=%v", blockNum, err)
+		} else {
+			blockInfo.BurntFees = blockBurntFees(block, receipts)
+			blockInfo.PriorityFees = blockPriorityFees(block, receipts)
+		}
+	} else {
+		blockInfo.BurntFees = blockBurntFees(block, nil)
+		blockInfo.PriorityFees = blockPriorityFees(block, nil)
+	}
 
 	// 4. 写入缓存（序列化后存储）
 	blockData, _ := json.Marshal(blockInfo)
@@ -1,121 +1,437 @@
 package service
 
 import (
+	"blockchain-asset-api/config"
 	"blockchain-asset-api/internal/model"
 	"blockchain-asset-api/internal/repository"
 	"blockchain-asset-api/internal/util"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/time/rate"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	TxTypeEthTransfer   = "eth_transfer"
 	TxTypeERC20Transfer = "erc20_transfer"
+	TxTypeNFTTransfer   = "nft_transfer"
 	TxTypeContractCall  = "contract_call"
 )
 
+// ReorgError 表示扫描时发现了链重组，本地数据已回滚到共同祖先 Ancestor
+type ReorgError struct {
+	Block    int64 // 发现父哈希不一致的区块号
+	Ancestor int64 // 共同祖先区块号
+}
+
+func (e *ReorgError) Error() string {
+	return fmt.Sprintf("区块 %d 发生链重组，已回滚到共同祖先区块 %d", e.Block, e.Ancestor)
+}
+
+// 链重组深度超过 scanner.maxReorgDepth，本地数据未回滚，扫描任务停止等待人工处理
+var ErrReorgTooDeep = errors.New("链重组深度超过上限")
+
 type BlockScanner struct {
 	blockRepo *repository.BlockRepository
-	ctx       context.Context
-	cancel    context.CancelFunc
+	tokenRepo *repository.TokenRepository
+	limiter   *rate.Limiter // 节点 RPC 请求的速率预算
+	// 已登记元数据的代币合约地址
+	knownTokens sync.Map
+	// 已查询过的 DEX 交易池，不是交易池的合约缓存为 nil
+	knownPools sync.Map
+	// 配置的 WETH 合约地址，未配置时为零地址，不识别包装 / 解包 ETH
+	weth common.Address
+	// 节点不支持 eth_getBlockReceipts 时置位，之后改用批量请求
+	noBlockReceipts atomic.Bool
+	// 追踪模式为 auto 且节点不支持 trace_block 时置位，之后改用 debug_traceBlockByNumber
+	noTraceBlock atomic.Bool
+	// 串行化区块提交：扫描任务和失败区块重试不能同时校验父哈希、回滚或写入
+	commitMu sync.Mutex
+	// 扫描器生命周期，后台重试和所有扫描任务都在其下运行
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	jobs map[string]*scanJob // 每个任务槽当前（或最近一次）的扫描任务
 }
 
 func NewBlockScanner() *BlockScanner {
 	ctx, cancel := context.WithCancel(context.Background())
+
+	// 未配置速率时不限速
+	limit := rate.Inf
+	if config.Cfg.Scanner.RPCRate > 0 {
+		limit = rate.Limit(config.Cfg.Scanner.RPCRate)
+	}
+	burst := config.Cfg.Scanner.RPCBurst
+	if burst < 1 {
+		burst = 1
+	}
+
+	var weth common.Address
+	if common.IsHexAddress(config.Cfg.Eth.WETH) {
+		weth = common.HexToAddress(config.Cfg.Eth.WETH)
+	}
+
 	return &BlockScanner{
 		blockRepo: repository.NewBlockRepository(),
+		tokenRepo: repository.NewTokenRepository(),
+		limiter:   rate.NewLimiter(limit, burst),
+		weth:      weth,
+		jobs:      make(map[string]*scanJob),
 		ctx:       ctx,
 		cancel:    cancel,
 	}
 }
 
-// 开始扫描区块
-func (s *BlockScanner) StartScan(fromBlock int64) error {
-	latestBlockNumber, err := s.blockRepo.GetLatestBlockNumber()
+// 启动扫描任务，toBlock 不大于 0 时扫描到已确认的最新区块
+func (s *BlockScanner) StartScan(fromBlock, toBlock int64) error {
+	return s.startJob(ScanJobScan, func(ctx context.Context) error {
+		return s.scan(ctx, fromBlock, toBlock)
+	})
+}
+
+// 启动回填任务，只扫描缺失的区块区段
+func (s *BlockScanner) StartBackfill(gaps []repository.BlockGap) error {
+	return s.startJob(ScanJobBackfill, func(ctx context.Context) error {
+		return s.backfill(ctx, gaps)
+	})
+}
+
+// 启动持续跟随链头的扫描任务
+func (s *BlockScanner) StartFollow() error {
+	return s.startJob(ScanJobFollow, s.follow)
+}
+
+// 扫描区块
+func (s *BlockScanner) scan(ctx context.Context, fromBlock, toBlock int64) error {
+	resumeBlock, err := s.resumeBlock()
 	if err != nil {
-		return fmt.Errorf("获取最新区块号失败: %v", err)
+		return fmt.Errorf("获取扫描断点失败: %v", err)
 	}
 
-	// 如果指定了起始区块，则使用指定的；否则从数据库中最新的区块+1开始
+	// 如果指定了起始区块，则使用指定的；否则从扫描断点继续
 	startBlock := fromBlock
 	if startBlock <= 0 {
-		startBlock = latestBlockNumber + 1
+		startBlock = resumeBlock
 	}
 
-	// 获取当前最新区块
-	header, err := util.EthClient.HeaderByNumber(s.ctx, nil)
+	// 获取已达到确认数的最新区块
+	currentBlock, err := s.targetBlock(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("获取最新区块头失败: %v", err)
+		return err
+	}
+	if toBlock > 0 {
+		if toBlock > currentBlock {
+			util.Log.Warnf("结束区块 %d 尚未达到确认数，只扫描到区块 %d", toBlock, currentBlock)
+		} else {
+			currentBlock = toBlock
+		}
 	}
-
-	currentBlock := header.Number.Int64()
 
 	util.Log.Infof("开始扫描区块: %d 到 %d", startBlock, currentBlock)
+	s.setJobRange(ctx, startBlock, currentBlock)
 
-	for i := startBlock; i <= currentBlock; i++ {
-		select {
-		case <-s.ctx.Done():
-			util.Log.Info("区块扫描已停止")
+	next, err := s.scanRange(ctx, startBlock, currentBlock)
+	if err != nil {
+		return err
+	}
+	if next <= currentBlock {
+		util.Log.Info("区块扫描已停止")
+		return nil
+	}
+
+	util.Log.Info("区块扫描完成")
+	return nil
+}
+
+// 回填缺失的区块区段
+func (s *BlockScanner) backfill(ctx context.Context, gaps []repository.BlockGap) error {
+	if len(gaps) > 0 {
+		s.setJobRange(ctx, gaps[0].From, gaps[len(gaps)-1].To)
+	}
+
+	for _, gap := range gaps {
+		util.Log.Infof("开始回填区块: %d 到 %d", gap.From, gap.To)
+		next, err := s.scanRange(ctx, gap.From, gap.To)
+		if err != nil {
+			return err
+		}
+		if next <= gap.To {
+			util.Log.Info("区块回填已停止")
 			return nil
-		default:
-			if err := s.scanBlock(i); err != nil {
-				util.Log.Errorf("扫描区块 %d 失败: %v", i, err)
-				continue
-			}
-			util.Log.Infof("成功扫描区块: %d", i)
-			time.Sleep(100 * time.Millisecond) // 避免请求过于频繁
 		}
 	}
 
-	util.Log.Info("区块扫描完成")
+	util.Log.Info("区块回填完成")
 	return nil
 }
 
-// 扫描单个区块
-func (s *BlockScanner) scanBlock(blockNumber int64) error {
-	// 获取区块信息
-	block, err := util.EthClient.BlockByNumber(context.Background(), big.NewInt(blockNumber))
+// 持续跟随链头扫描：WebSocket/IPC 节点订阅新区块头，HTTP 节点按间隔轮询
+func (s *BlockScanner) follow(ctx context.Context) error {
+	// 重启后从扫描断点继续
+	next, err := s.resumeBlock()
+	if err != nil {
+		return fmt.Errorf("获取扫描断点失败: %v", err)
+	}
+
+	heads := make(chan *types.Header, 16)
+	var subErr <-chan error
+	if util.IsStreamingEndpoint(config.Cfg.Eth.NodeURL) {
+		sub, err := util.EthClient.SubscribeNewHead(ctx, heads)
+		if err != nil {
+			util.Log.Warnf("订阅新区块失败，改用轮询: %v", err)
+		} else {
+			defer sub.Unsubscribe()
+			subErr = sub.Err()
+			util.Log.Info("跟随扫描已启动（订阅模式）")
+		}
+	}
+
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	if subErr == nil {
+		ticker = time.NewTicker(config.Cfg.Scanner.PollInterval)
+		tick = ticker.C
+		util.Log.Infof("跟随扫描已启动（轮询模式，间隔 %s）", config.Cfg.Scanner.PollInterval)
+	}
+
+	if next, err = s.followHead(ctx, next, nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			util.Log.Info("跟随扫描已停止")
+			return nil
+		case header := <-heads:
+			next, err = s.followHead(ctx, next, header)
+		case <-tick:
+			next, err = s.followHead(ctx, next, nil)
+		case err := <-subErr:
+			// 订阅断开后退回轮询模式
+			util.Log.Warnf("新区块订阅中断，改用轮询: %v", err)
+			subErr = nil
+			ticker = time.NewTicker(config.Cfg.Scanner.PollInterval)
+			tick = ticker.C
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// 扫描到链头，header 为订阅收到的新区块头（可为空），返回下一个待扫描的区块号；
+// 只有需要停止跟随的错误（链重组过深）才返回
+func (s *BlockScanner) followHead(ctx context.Context, next int64, header *types.Header) (int64, error) {
+	head, err := s.targetBlock(ctx, header)
 	if err != nil {
-		return fmt.Errorf("获取区块失败: %v", err)
+		util.Log.Error(err)
+		return next, nil
 	}
 
-	// 保存区块信息
+	// 落后区块数在允许范围内时暂不扫描
+	if head-(next-1) <= config.Cfg.Scanner.MaxLag {
+		return next, nil
+	}
+
+	util.Log.Infof("跟随扫描区块: %d 到 %d", next, head)
+	s.setJobRange(ctx, next, head)
+	return s.scanRange(ctx, next, head)
+}
+
+// 计算可以写入数据库的最新区块号：按 headTag 获取链头后减去确认数
+func (s *BlockScanner) targetBlock(ctx context.Context, header *types.Header) (int64, error) {
+	tag := config.Cfg.Scanner.HeadTag
+
+	var head int64
+	if header != nil && (tag == "" || tag == "latest") {
+		head = header.Number.Int64()
+	} else {
+		number, err := util.GetHeadNumber(ctx, tag)
+		if err != nil {
+			return 0, fmt.Errorf("获取最新区块头失败: %v", err)
+		}
+		head = number
+	}
+
+	return head - config.Cfg.Scanner.Confirmations, nil
+}
+
+// 扫描 [from, to] 区间，发生链重组时从共同祖先继续，返回下一个待扫描的区块号；
+// 链重组过深时返回 ErrReorgTooDeep，扫描任务应停止
+func (s *BlockScanner) scanRange(ctx context.Context, from, to int64) (int64, error) {
+	for {
+		next, err := s.runPipeline(ctx, from, to)
+		var reorgErr *ReorgError
+		if !errors.As(err, &reorgErr) {
+			return next, err
+		}
+		// 发生链重组时从共同祖先的下一个区块重新扫描规范链
+		util.Log.Warn(reorgErr.Error())
+		from = reorgErr.Ancestor + 1
+	}
+}
+
+// 提交已抓取的区块：校验父哈希后保存区块、交易、转移记录、DEX兑换、内部交易和提款。
+// 扫描任务和失败区块重试在不同的 goroutine 中提交，校验父哈希、回滚和保存需要串行执行
+func (s *BlockScanner) commitBlock(ctx context.Context, fb *fetchedBlock) error {
+	s.commitMu.Lock()
+	data, err := s.saveBlock(ctx, fb)
+	s.commitMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// 首次出现的代币合约登记元数据
+	s.registerTokens(ctx, data.ERC20Transfers)
+	// 检测新部署合约的字节码和代币标准
+	s.inspectContracts(ctx, data.Contracts)
+
+	return nil
+}
+
+// 校验父哈希并在一个数据库事务中保存区块数据，调用方需持有 commitMu
+func (s *BlockScanner) saveBlock(ctx context.Context, fb *fetchedBlock) (*repository.BlockData, error) {
+	block := fb.block
+	blockNumber := fb.number
+
+	// 校验父哈希，发现链重组则回滚
+	if err := s.checkParent(ctx, blockNumber, block.ParentHash().Hex()); err != nil {
+		return nil, err
+	}
+
+	// 区块信息
 	blockModel := &model.Block{
 		BlockNumber:       blockNumber,
 		BlockHash:         block.Hash().Hex(),
+		ParentHash:        block.ParentHash().Hex(),
 		Timestamp:         time.Unix(int64(block.Time()), 0),
 		TransactionsCount: len(block.Transactions()),
 		GasUsed:           int64(block.GasUsed()),
 		GasLimit:          int64(block.GasLimit()),
 		Miner:             block.Coinbase().Hex(),
 		CreatedAt:         time.Now(),
+		BaseFeePerGas:     weiString(block.BaseFee()),
+		BurntFees:         blockBurntFees(block, fb.receipts),
+		PriorityFees:      blockPriorityFees(block, fb.receipts),
+		BlobGasUsed:       block.BlobGasUsed(),
+		ExcessBlobGas:     block.ExcessBlobGas(),
+		StateRoot:         block.Root().Hex(),
+		Size:              block.Size(),
+		WithdrawalsCount:  len(block.Withdrawals()),
 	}
 
-	if err := s.blockRepo.SaveBlock(blockModel); err != nil {
-		return fmt.Errorf("保存区块信息失败: %v", err)
-	}
+	data := &repository.BlockData{Block: blockModel}
 
 	// 处理区块中的交易
-	for _, tx := range block.Transactions() {
-		if err := s.processTransaction(tx, blockNumber); err != nil {
-			util.Log.Errorf("处理交易 %s 失败: %v", tx.Hash().Hex(), err)
+	for i, tx := range block.Transactions() {
+		if err := s.processTransaction(data, tx, fb.receipts[i], block.BaseFee()); err != nil {
+			return nil, fmt.Errorf("处理交易 %s 失败: %v", tx.Hash().Hex(), err)
 		}
 	}
+	s.processInternalTransactions(data, fb.traces)
+	s.processWithdrawals(data, block.Withdrawals())
+	if err := s.processDexEvents(ctx, data, fb.receipts); err != nil {
+		return nil, err
+	}
 
-	return nil
+	// 区块数据在一个数据库事务中写入
+	if err := s.blockRepo.SaveBlockData(data); err != nil {
+		return nil, fmt.Errorf("保存区块数据失败: %v", err)
+	}
+	return data, nil
 }
 
-// 处理交易
-func (s *BlockScanner) processTransaction(tx *types.Transaction, blockNumber int64) error {
-	// 获取交易回执
-	receipt, err := util.EthClient.TransactionReceipt(context.Background(), tx.Hash())
+// 校验区块的父哈希是否与本地保存的上一个区块哈希一致
+func (s *BlockScanner) checkParent(ctx context.Context, blockNumber int64, parentHash string) error {
+	if blockNumber == 0 {
+		return nil
+	}
+
+	parent, err := s.blockRepo.GetBlockByNumber(blockNumber - 1)
 	if err != nil {
-		return fmt.Errorf("获取交易回执失败: %v", err)
+		return fmt.Errorf("获取父区块失败: %v", err)
 	}
+	// 本地没有父区块（首次扫描或指定了起始区块）时无法比较
+	if parent == nil || parent.BlockHash == parentHash {
+		return nil
+	}
+
+	util.Log.Warnf("检测到链重组: 区块 %d 的父哈希 %s 与本地区块 %d 的哈希 %s 不一致",
+		blockNumber, parentHash, blockNumber-1, parent.BlockHash)
+
+	ancestor, err := s.findCommonAncestor(ctx, blockNumber-1)
+	if err != nil {
+		return fmt.Errorf("查找共同祖先失败: %w", err)
+	}
+
+	if err := s.blockRepo.RollbackToBlock(ancestor); err != nil {
+		return fmt.Errorf("回滚孤块数据失败: %v", err)
+	}
+
+	event := &model.ReorgEvent{
+		DetectedBlock:  blockNumber,
+		CommonAncestor: ancestor,
+		Depth:          blockNumber - 1 - ancestor,
+		OldHash:        parent.BlockHash,
+		NewHash:        parentHash,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.blockRepo.SaveReorgEvent(event); err != nil {
+		util.Log.Errorf("保存链重组事件失败: %v", err)
+	}
+	util.Log.Warnf("链重组处理完成: 共同祖先区块 %d, 回滚深度 %d", ancestor, event.Depth)
+
+	return &ReorgError{Block: blockNumber, Ancestor: ancestor}
+}
+
+// 从 blockNumber 开始向前回溯，找到本地哈希与链上规范哈希一致的共同祖先
+func (s *BlockScanner) findCommonAncestor(ctx context.Context, blockNumber int64) (int64, error) {
+	maxDepth := config.Cfg.Scanner.MaxReorgDepth
+	for n := blockNumber; n >= 0; n-- {
+		if blockNumber-n >= maxDepth {
+			return 0, fmt.Errorf("%w %d，区块 %d 之前的本地数据需要人工处理", ErrReorgTooDeep, maxDepth, n)
+		}
+
+		stored, err := s.blockRepo.GetBlockByNumber(n)
+		if err != nil {
+			return 0, err
+		}
+		// 本地没有该区块，无需继续比较
+		if stored == nil {
+			return n, nil
+		}
+
+		if err := s.limiter.Wait(ctx); err != nil {
+			return 0, err
+		}
+		header, err := util.EthClient.HeaderByNumber(ctx, big.NewInt(n))
+		if err != nil {
+			return 0, fmt.Errorf("获取区块头 %d 失败: %v", n, err)
+		}
+		if header.Hash().Hex() == stored.BlockHash {
+			return n, nil
+		}
+	}
+	// 直到创世区块都不一致：本地数据来自另一条链，不能回滚到 -1 清空全部索引
+	return 0, fmt.Errorf("%w：本地创世区块与链上不一致，本地数据需要人工处理", ErrReorgTooDeep)
+}
+
+// 处理交易，解析结果追加到 data 中，baseFee 为区块的基础费用
+func (s *BlockScanner) processTransaction(data *repository.BlockData, tx *types.Transaction, receipt *types.Receipt, baseFee *big.Int) error {
+	blockNumber := data.Block.BlockNumber
 
 	// 恢复发送方地址
 	signer := types.LatestSignerForChainID(tx.ChainId())
@@ -140,10 +456,11 @@ func (s *BlockScanner) processTransaction(tx *types.Transaction, blockNumber int
 		FromAddress: fromAddr.Hex(),
 		Value:       util.WeiToEth(tx.Value()),
 		GasLimit:    int64(tx.Gas()),
-		GasPrice:    util.WeiToEth(tx.GasPrice()),
 		TxType:      txType,
 		Status:      status,
 		CreatedAt:   time.Now(),
+		TxFees:      newTxFees(tx, receipt, baseFee),
+		Labels:      s.transactionLabels(receipt),
 	}
 
 	// 设置接收方地址和Gas使用量
@@ -154,18 +471,21 @@ func (s *BlockScanner) processTransaction(tx *types.Transaction, blockNumber int
 	gasUsed := int64(receipt.GasUsed)
 	txModel.GasUsed = &gasUsed
 
-	// 保存交易
-	if err := s.blockRepo.SaveTransaction(txModel); err != nil {
-		return fmt.Errorf("保存交易失败: %v", err)
-	}
+	data.Transactions = append(data.Transactions, txModel)
 
-	// 如果是ERC20交易，处理Transfer事件
-	if txType == TxTypeERC20Transfer {
-		if err := s.processERC20Transfers(tx, receipt); err != nil {
-			util.Log.Errorf("处理ERC20转移事件失败: %v", err)
-		}
+	// 合约创建交易记录部署的合约
+	if tx.To() == nil {
+		s.processContractCreation(data, txModel, receipt.ContractAddress)
 	}
 
+	// 保存全部事件日志，登记了 ABI 的合约同时解码
+	s.processLogs(data, tx, receipt)
+
+	// 任何交易都可能触发代币转移（例如DEX兑换），每条转移日志单独记录
+	s.processERC20Transfers(data, tx, receipt)
+	s.processNFTTransfers(data, tx, receipt)
+	s.processApprovals(data, tx, receipt)
+
 	return nil
 }
 
@@ -173,13 +493,28 @@ func (s *BlockScanner) processTransaction(tx *types.Transaction, blockNumber int
 func (s *BlockScanner) determineTxType(tx *types.Transaction, receipt *types.Receipt) string {
 	// 如果有输入数据且不是简单的ETH转账，则可能是合约调用
 	if len(tx.Data()) > 0 {
-		// 检查是否是ERC20 Transfer事件
+		// 只有直接调用代币合约的交易才算代币转移；
+		// 通过其他合约（如DEX路由）间接产生的转移仍是合约调用，转移记录照常保存
+		hasERC20, hasNFT := false, false
 		for _, log := range receipt.Logs {
-			if len(log.Topics) > 0 && log.Topics[0].Hex() == "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef" {
-				// 这是ERC20 Transfer事件的签名哈希
-				return TxTypeERC20Transfer
+			switch {
+			case isERC20Transfer(log):
+				hasERC20 = true
+			case isNFTTransfer(log):
+				hasNFT = true
+			default:
+				continue
+			}
+			if tx.To() == nil || log.Address != *tx.To() {
+				return TxTypeContractCall
 			}
 		}
+		if hasERC20 {
+			return TxTypeERC20Transfer
+		}
+		if hasNFT {
+			return TxTypeNFTTransfer
+		}
 		return TxTypeContractCall
 	}
 
@@ -188,13 +523,10 @@ func (s *BlockScanner) determineTxType(tx *types.Transaction, receipt *types.Rec
 }
 
 // 处理ERC20转移事件
-func (s *BlockScanner) processERC20Transfers(tx *types.Transaction, receipt *types.Receipt) error {
-	// ERC20 Transfer事件的topic0签名哈希
-	transferTopic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
-
+func (s *BlockScanner) processERC20Transfers(data *repository.BlockData, tx *types.Transaction, receipt *types.Receipt) {
 	for _, log := range receipt.Logs {
-		// 检查是否是Transfer事件
-		if len(log.Topics) == 3 && log.Topics[0] == transferTopic {
+		// 检查是否是Transfer事件（ERC-721 的 Transfer 有 4 个 topic，由 processNFTTransfers 处理）
+		if isERC20Transfer(log) {
 			// 解析Transfer事件数据
 			fromAddr := common.BytesToAddress(log.Topics[1].Bytes()).Hex()
 			toAddr := common.BytesToAddress(log.Topics[2].Bytes()).Hex()
@@ -205,6 +537,8 @@ func (s *BlockScanner) processERC20Transfers(tx *types.Transaction, receipt *typ
 
 				transfer := &model.ERC20Transfer{
 					TxHash:          tx.Hash().Hex(),
+					LogIndex:        log.Index,
+					BlockNumber:     data.Block.BlockNumber,
 					FromAddress:     fromAddr,
 					ToAddress:       toAddr,
 					ContractAddress: log.Address.Hex(),
@@ -212,17 +546,8 @@ func (s *BlockScanner) processERC20Transfers(tx *types.Transaction, receipt *typ
 					CreatedAt:       time.Now(),
 				}
 
-				if err := s.blockRepo.SaveERC20Transfer(transfer); err != nil {
-					util.Log.Errorf("保存ERC20转移记录失败: %v", err)
-				}
+				data.ERC20Transfers = append(data.ERC20Transfers, transfer)
 			}
 		}
 	}
-
-	return nil
-}
-
-// 停止扫描
-func (s *BlockScanner) Stop() {
-	s.cancel()
 }
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 准备扫描测试环境：进程内数据库和测试链
+func setupScanTest(t *testing.T) *testutil.Chain {
+	t.Helper()
+
+	util.InitLog()
+	util.Log.SetOutput(io.Discard)
+
+	config.Cfg.MySQL.DSN = testutil.StartMySQL(t)
+	if err := repository.InitMySQL(); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	contractABIs.reset()
+
+	chain := testutil.NewChain(t)
+	util.EthClient = chain.Client()
+	return chain
+}
+
+// 断言数据库中 [from, to] 的区块哈希与测试链一致
+func assertCanonical(t *testing.T, chain *testutil.Chain, from, to int64) {
+	t.Helper()
+
+	var blocks []model.Block
+	if err := repository.DB.Order("block_number").Find(&blocks).Error; err != nil {
+		t.Fatalf("查询区块失败: %v", err)
+	}
+	if len(blocks) != int(to-from+1) {
+		t.Fatalf("数据库中有 %d 个区块，期望 %d 个", len(blocks), to-from+1)
+	}
+	for i, block := range blocks {
+		number := from + int64(i)
+		want := chain.Block(uint64(number)).Hash().Hex()
+		if block.BlockNumber != number || block.BlockHash != want {
+			t.Errorf("区块 %d 的哈希为 %s，期望区块 %d 的哈希 %s", block.BlockNumber, block.BlockHash, number, want)
+		}
+	}
+}
+
+func TestScanRollsBackOrphanedBlocks(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.MaxReorgDepth = 64
+
+	for i := 0; i < 5; i++ {
+		chain.Mine(testutil.Tx{To: chain.Sender(), Value: big.NewInt(1)})
+	}
+	scanner := NewBlockScanner()
+	if err := scanner.StartScan(1, 0); err != nil {
+		t.Fatalf("启动扫描失败: %v", err)
+	}
+	scanner.Wait(ScanSlotRange)
+	assertCanonical(t, chain, 1, 5)
+	orphanTx := chain.Block(5).Transactions()[0].Hash().Hex()
+
+	// 区块 4、5 被一条更长的分叉替换
+	chain.Rewind(3)
+	chain.MineEmpty(3)
+	if err := scanner.StartScan(0, 0); err != nil {
+		t.Fatalf("启动扫描失败: %v", err)
+	}
+	scanner.Wait(ScanSlotRange)
+	assertCanonical(t, chain, 1, 6)
+
+	var orphaned int64
+	repository.DB.Model(&model.Transaction{}).Where("tx_hash = ?", orphanTx).Count(&orphaned)
+	if orphaned != 0 {
+		t.Errorf("孤块中的交易 %s 没有被回滚", orphanTx)
+	}
+
+	events, total, err := GetReorgEvents(1, 10)
+	if err != nil || total != 1 || len(events) != 1 {
+		t.Fatalf("记录了 %d 次链重组（%v），期望 1 次", total, err)
+	}
+	if e := events[0]; e.DetectedBlock != 6 || e.CommonAncestor != 3 || e.Depth != 2 {
+		t.Errorf("链重组事件 = {区块 %d, 共同祖先 %d, 深度 %d}，期望 {6, 3, 2}", e.DetectedBlock, e.CommonAncestor, e.Depth)
+	}
+}
+
+func TestScanStopsOnReorgDeeperThanLimit(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.MaxReorgDepth = 2
+	t.Cleanup(func() { config.Cfg.Scanner.MaxReorgDepth = 64 })
+
+	chain.MineEmpty(5)
+	scanner := NewBlockScanner()
+	if err := scanner.StartScan(1, 0); err != nil {
+		t.Fatalf("启动扫描失败: %v", err)
+	}
+	scanner.Wait(ScanSlotRange)
+	orphanHash := chain.Block(5).Hash().Hex()
+
+	// 区块 2 到 5 被替换，回溯深度超过上限
+	chain.Rewind(1)
+	chain.MineEmpty(6)
+	if err := scanner.StartScan(0, 0); err != nil {
+		t.Fatalf("启动扫描失败: %v", err)
+	}
+	scanner.Wait(ScanSlotRange)
+
+	status := scanner.Status(ScanSlotRange)
+	if status.State != ScanStateFailed || !strings.Contains(status.LastError, ErrReorgTooDeep.Error()) {
+		t.Errorf("扫描任务状态为 %s（%s），期望因链重组过深失败", status.State, status.LastError)
+	}
+	var blocks []model.Block
+	repository.DB.Order("block_number").Find(&blocks)
+	if len(blocks) != 5 || blocks[4].BlockHash != orphanHash {
+		t.Errorf("链重组过深时本地保存了 %d 个区块，期望保留原有的 5 个区块等待人工处理", len(blocks))
+	}
+	if n := countTable(t, &model.FailedBlock{}); n != 0 {
+		t.Errorf("链重组过深的区块进入了重试队列（%d 条）", n)
+	}
+}
+
+func TestCommonAncestorNotFoundDoesNotWipeIndex(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.MaxReorgDepth = 64
+
+	// 本地数据来自另一条链，直到创世区块都不一致
+	chain.MineEmpty(2)
+	for n := int64(0); n <= 1; n++ {
+		block := &model.Block{BlockNumber: n, BlockHash: fmt.Sprintf("0x%064x", n+100), Timestamp: time.Now(), CreatedAt: time.Now()}
+		if err := repository.DB.Create(block).Error; err != nil {
+			t.Fatalf("写入区块失败: %v", err)
+		}
+	}
+
+	if _, err := NewBlockScanner().runPipeline(context.Background(), 2, 2); !errors.Is(err, ErrReorgTooDeep) {
+		t.Fatalf("找不到共同祖先时返回 %v，期望 ErrReorgTooDeep", err)
+	}
+	if n := countTable(t, &model.Block{}); n != 2 {
+		t.Errorf("找不到共同祖先后剩余 %d 个区块，期望保留 2 个", n)
+	}
+}
+
+func TestRangeScanRunsAlongsideFollow(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.PollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { config.Cfg.Scanner.PollInterval = 0 })
+
+	chain.MineEmpty(6)
+	scanner := NewBlockScanner()
+	if err := scanner.StartFollow(); err != nil {
+		t.Fatalf("启动跟随扫描失败: %v", err)
+	}
+	if err := scanner.StartFollow(); !errors.Is(err, ErrScanRunning) {
+		t.Errorf("重复启动跟随扫描返回 %v，期望 ErrScanRunning", err)
+	}
+
+	// 跟随扫描运行期间仍可以重新索引指定区间
+	if err := scanner.StartScan(2, 4); err != nil {
+		t.Fatalf("跟随扫描运行期间启动区间扫描失败: %v", err)
+	}
+	scanner.Wait(ScanSlotRange)
+	if status := scanner.Status(ScanSlotRange); status.State != ScanStateCompleted || status.ScannedBlocks != 3 {
+		t.Errorf("区间扫描状态为 %s，扫描了 %d 个区块", status.State, status.ScannedBlocks)
+	}
+	if status := scanner.Status(ScanSlotFollow); status.State != ScanStateRunning {
+		t.Errorf("跟随扫描状态为 %s，期望 running", status.State)
+	}
+
+	// 停止后可以重新启动跟随扫描
+	if err := scanner.Stop(ScanSlotFollow); err != nil {
+		t.Fatalf("停止跟随扫描失败: %v", err)
+	}
+	scanner.Wait(ScanSlotFollow)
+	chain.MineEmpty(2)
+	if err := scanner.StartFollow(); err != nil {
+		t.Fatalf("重新启动跟随扫描失败: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for scanner.Status(ScanSlotFollow).CurrentBlock < 8 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	_ = scanner.Stop(ScanSlotFollow)
+	scanner.Wait(ScanSlotFollow)
+	assertCanonical(t, chain, 1, 8)
+}
+
+func TestCloseStopsJobsAndRetrier(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.PollInterval = 10 * time.Millisecond
+	config.Cfg.Scanner.RetryInterval = time.Hour
+	t.Cleanup(func() {
+		config.Cfg.Scanner.PollInterval = 0
+		config.Cfg.Scanner.RetryInterval = 0
+	})
+
+	chain.MineEmpty(2)
+	scanner := NewBlockScanner()
+	if err := scanner.StartFollow(); err != nil {
+		t.Fatalf("启动跟随扫描失败: %v", err)
+	}
+	retrierDone := make(chan struct{})
+	go func() {
+		scanner.RunRetrier()
+		close(retrierDone)
+	}()
+
+	scanner.Close()
+	if status := scanner.Status(ScanSlotFollow); status.State == ScanStateRunning {
+		t.Errorf("关闭扫描器后跟随扫描状态仍为 %s", status.State)
+	}
+	select {
+	case <-retrierDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("关闭扫描器后失败区块重试未停止")
+	}
+}
+
+func TestBackfillFillsGaps(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.Workers = 2
+
+	chain.MineEmpty(12)
+	scanner := NewBlockScanner()
+	for _, r := range [][2]int64{{1, 2}, {6, 7}, {10, 10}} {
+		if _, err := scanner.runPipeline(context.Background(), r[0], r[1]); err != nil {
+			t.Fatalf("扫描区块 %d 到 %d 失败: %v", r[0], r[1], err)
+		}
+	}
+
+	gaps, err := scanner.blockRepo.FindGaps(1, 12)
+	if err != nil {
+		t.Fatalf("查找缺失区块失败: %v", err)
+	}
+	want := []repository.BlockGap{{From: 3, To: 5}, {From: 8, To: 9}, {From: 11, To: 12}}
+	if !reflect.DeepEqual(gaps, want) {
+		t.Fatalf("缺失区段为 %v，期望 %v", gaps, want)
+	}
+
+	if err := scanner.StartBackfill(gaps); err != nil {
+		t.Fatalf("启动回填失败: %v", err)
+	}
+	scanner.Wait(ScanSlotRange)
+	if gaps, _ := scanner.blockRepo.FindGaps(1, 12); len(gaps) != 0 {
+		t.Errorf("回填后仍缺失区段 %v", gaps)
+	}
+	assertCanonical(t, chain, 1, 12)
+}
+
+func TestFindBlockGapsStartsFromEarliestBlock(t *testing.T) {
+	chain := setupScanTest(t)
+
+	if _, err := FindBlockGaps(-1, 0); !errors.Is(err, ErrNoIndexedBlocks) {
+		t.Errorf("数据库为空时返回 %v，期望 ErrNoIndexedBlocks", err)
+	}
+
+	// 从区块 5 开始索引，区块 1 到 4 不算缺失
+	chain.MineEmpty(9)
+	scanner := NewBlockScanner()
+	for _, r := range [][2]int64{{5, 7}, {9, 9}} {
+		if _, err := scanner.runPipeline(context.Background(), r[0], r[1]); err != nil {
+			t.Fatalf("扫描区块 %d 到 %d 失败: %v", r[0], r[1], err)
+		}
+	}
+
+	gaps, err := FindBlockGaps(-1, 0)
+	if want := []repository.BlockGap{{From: 8, To: 8}}; err != nil || !reflect.DeepEqual(gaps, want) {
+		t.Errorf("缺失区段为 %v（%v），期望 %v", gaps, err, want)
+	}
+	gaps, err = FindBlockGaps(3, 0)
+	if want := []repository.BlockGap{{From: 3, To: 4}, {From: 8, To: 8}}; err != nil || !reflect.DeepEqual(gaps, want) {
+		t.Errorf("指定起始区块时缺失区段为 %v（%v），期望 %v", gaps, err, want)
+	}
+}
+
+func TestScanRecordsEveryERC20Transfer(t *testing.T) {
+	chain := setupScanTest(t)
+
+	router := common.HexToAddress("0x00000000000000000000000000000000000000d1")
+	tokenA := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	tokenB := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	pool := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+	user := chain.Sender()
+
+	erc20Log := func(token, from, to common.Address, amount int64) *types.Log {
+		return &types.Log{
+			Address: token,
+			Topics:  []common.Hash{transferTopic, addressTopic(from), addressTopic(to)},
+			Data:    uint256Word(amount),
+		}
+	}
+	chain.Mine(
+		// 通过路由兑换：用户转出 tokenA，池子转出 tokenB
+		testutil.Tx{To: router, Data: []byte{0x01}, Logs: []*types.Log{
+			erc20Log(tokenA, user, pool, 100),
+			erc20Log(tokenB, pool, user, 250),
+		}},
+		// 直接调用代币合约转账
+		testutil.Tx{To: tokenA, Data: []byte{0x01}, Logs: []*types.Log{
+			erc20Log(tokenA, user, pool, 7),
+		}},
+	)
+
+	if _, err := NewBlockScanner().runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	txs := chain.Block(1).Transactions()
+	transactions, _, err := GetTransactions(1, 10, "", "", nil)
+	if err != nil {
+		t.Fatalf("查询交易列表失败: %v", err)
+	}
+	byHash := make(map[string]model.Transaction)
+	for _, tx := range transactions {
+		byHash[tx.TxHash] = tx
+	}
+
+	swap := byHash[txs[0].Hash().Hex()]
+	if swap.TxType != TxTypeContractCall {
+		t.Errorf("兑换交易类型为 %s，期望 %s", swap.TxType, TxTypeContractCall)
+	}
+	if len(swap.ERC20Transfers) != 2 {
+		t.Fatalf("兑换交易有 %d 条ERC20转移记录，期望 2 条", len(swap.ERC20Transfers))
+	}
+	for i, want := range []struct {
+		token    common.Address
+		logIndex uint
+	}{{tokenA, 0}, {tokenB, 1}} {
+		got := swap.ERC20Transfers[i]
+		if got.ContractAddress != want.token.Hex() || got.LogIndex != want.logIndex || got.BlockNumber != 1 {
+			t.Errorf("第 %d 条转移记录为 %+v，期望合约 %s、日志序号 %d", i, got, want.token.Hex(), want.logIndex)
+		}
+	}
+
+	direct := byHash[txs[1].Hash().Hex()]
+	if direct.TxType != TxTypeERC20Transfer || len(direct.ERC20Transfers) != 1 {
+		t.Errorf("直接转账交易类型为 %s、转移记录 %d 条，期望 %s 和 1 条", direct.TxType, len(direct.ERC20Transfers), TxTypeERC20Transfer)
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// 时间戳对应区块的查找方向
+const (
+	ClosestBefore = "before" // 时间戳不晚于 ts 的最后一个区块
+	ClosestAfter  = "after"  // 时间戳不早于 ts 的第一个区块
+)
+
+// 时间戳对应区块的数据来源
+const (
+	BlockTimeSourceIndex = "index" // 已索引的 blocks 表
+	BlockTimeSourceRPC   = "rpc"   // 通过 RPC 区块头二分查找
+)
+
+// 时间戳超出链的时间范围，属于请求参数错误
+var (
+	ErrBeforeGenesis = errors.New("时间戳早于创世区块")
+	ErrAfterHead     = errors.New("时间戳晚于最新区块")
+)
+
+// BlockByTime 时间戳对应的区块
+type BlockByTime struct {
+	Timestamp      int64  `json:"timestamp"` // 查询的时间戳（Unix 秒）
+	Closest        string `json:"closest"`
+	BlockNumber    int64  `json:"block_number"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	Source         string `json:"source"`
+}
+
+// GetBlockByTime 查找时间戳 ts（Unix 秒）对应的区块，closest 为 before 或 after。
+// 区间已索引时直接查询 blocks 表，否则通过区块头二分查找，结果写入 Redis 缓存。
+func GetBlockByTime(ts int64, closest string) (*BlockByTime, error) {
+	if closest != ClosestBefore && closest != ClosestAfter {
+		return nil, fmt.Errorf("无效的 closest 参数: %s（支持 before 或 after）", closest)
+	}
+
+	// 1. 查缓存
+	key := fmt.Sprintf("%s:%d", closest, ts)
+	if cached, err := repository.GetBlockByTimeCache(key); err == nil && cached != "" {
+		var result BlockByTime
+		if err := json.Unmarshal([]byte(cached), &result); err == nil {
+			return &result, nil
+		}
+	}
+
+	// 2. 查索引或链上
+	result, final, err := resolveBlockByTime(context.Background(), ts, closest)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 写入缓存，ts 晚于链头时结果会随新区块变化，不缓存
+	if final {
+		data, _ := json.Marshal(result)
+		if err := repository.SetBlockByTimeCache(key, string(data)); err != nil {
+			util.Log.Warnf("缓存时间戳对应区块失败: ts=%d, closest=%s, err=%v", ts, closest, err)
+		}
+	}
+	return result, nil
+}
+
+// BlockAtTime 查找 ts 时刻链上的最新区块，即时间戳不晚于 ts 的最后一个区块
+func BlockAtTime(ts int64) (int64, error) {
+	result, err := GetBlockByTime(ts, ClosestBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.BlockNumber, nil
+}
+
+// 查找时间戳对应的区块，final 为 false 表示结果是当前链头、之后可能变化
+func resolveBlockByTime(ctx context.Context, ts int64, closest string) (result *BlockByTime, final bool, err error) {
+	result = &BlockByTime{Timestamp: ts, Closest: closest}
+
+	number, blockTime, ok, err := indexedBlockByTime(ts, closest)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		result.BlockNumber, result.BlockTimestamp, result.Source = number, blockTime, BlockTimeSourceIndex
+		return result, true, nil
+	}
+
+	if closest == ClosestBefore {
+		number, final, err = bisectBlockBefore(ctx, ts)
+	} else {
+		number, err = bisectBlockAfter(ctx, ts)
+		final = true
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if blockTime, err = headerTime(ctx, number); err != nil {
+		return nil, false, err
+	}
+	result.BlockNumber, result.BlockTimestamp, result.Source = number, blockTime, BlockTimeSourceRPC
+	return result, final, nil
+}
+
+// 从 blocks 表查找时间戳对应的区块。只有相邻区块也已索引时结果才可信
+// （区块时间戳严格递增，相邻区块在 ts 另一侧说明中间没有未索引的区块），否则 ok 为 false
+func indexedBlockByTime(ts int64, closest string) (number, blockTime int64, ok bool, err error) {
+	repo := repository.NewBlockRepository()
+	t := time.Unix(ts, 0)
+
+	if closest == ClosestBefore {
+		block, err := repo.FindBlockBefore(t)
+		if err != nil || block == nil {
+			return 0, 0, false, err
+		}
+		next, err := repo.GetBlockByNumber(block.BlockNumber + 1)
+		if err != nil || next == nil {
+			return 0, 0, false, err
+		}
+		return block.BlockNumber, block.Timestamp.Unix(), true, nil
+	}
+
+	block, err := repo.FindBlockAfter(t)
+	if err != nil || block == nil {
+		return 0, 0, false, err
+	}
+	if block.BlockNumber > 0 {
+		prev, err := repo.GetBlockByNumber(block.BlockNumber - 1)
+		if err != nil || prev == nil {
+			return 0, 0, false, err
+		}
+	}
+	return block.BlockNumber, block.Timestamp.Unix(), true, nil
+}
+
+// 二分查找时间戳不晚于 ts 的最后一个区块，final 为 false 表示 ts 不早于链头、结果为当前链头
+func bisectBlockBefore(ctx context.Context, ts int64) (number int64, final bool, err error) {
+	first, head, err := firstBlockAfter(ctx, ts+1)
+	if err != nil {
+		return 0, false, err
+	}
+	if first < 0 {
+		return head, false, nil
+	}
+	if first == 0 {
+		return 0, false, fmt.Errorf("%w: %d", ErrBeforeGenesis, ts)
+	}
+	return first - 1, true, nil
+}
+
+// 二分查找时间戳不早于 ts 的第一个区块
+func bisectBlockAfter(ctx context.Context, ts int64) (int64, error) {
+	first, _, err := firstBlockAfter(ctx, ts)
+	if err != nil {
+		return 0, err
+	}
+	if first < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrAfterHead, ts)
+	}
+	return first, nil
+}
+
+// 二分查找时间戳不早于 ts 的第一个区块号，链头时间戳也早于 ts 时返回 -1，同时返回链头区块号
+func firstBlockAfter(ctx context.Context, ts int64) (first, head int64, err error) {
+	header, err := util.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("获取最新区块头失败: %v", err)
+	}
+	head = header.Number.Int64()
+	if int64(header.Time) < ts {
+		return -1, head, nil
+	}
+
+	// 保持 lo 的时间戳 < ts <= hi 的时间戳，lo 为 -1 表示创世区块之前
+	lo, hi := int64(-1), head
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		t, err := headerTime(ctx, mid)
+		if err != nil {
+			return 0, 0, err
+		}
+		if t < ts {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi, head, nil
+}
+
+// 查询区块的时间戳
+func headerTime(ctx context.Context, number int64) (int64, error) {
+	header, err := util.EthClient.HeaderByNumber(ctx, big.NewInt(number))
+	if err != nil {
+		return 0, fmt.Errorf("获取区块头 %d 失败: %v", number, err)
+	}
+	return int64(header.Time), nil
+}
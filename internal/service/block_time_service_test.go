@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// 测试链区块 n 的时间戳
+func testBlockTime(n int64) int64 {
+	return 1700000000 + 12*n
+}
+
+func TestBisectBlockBefore(t *testing.T) {
+	chain := setupScanTest(t)
+	chain.MineEmpty(20)
+
+	cases := []struct {
+		ts     int64
+		number int64
+		final  bool
+	}{
+		{testBlockTime(0), 0, true},
+		{testBlockTime(0) + 11, 0, true},
+		{testBlockTime(1), 1, true},
+		{testBlockTime(8) + 4, 8, true},
+		{testBlockTime(19) + 11, 19, true},
+		{testBlockTime(20), 20, false},
+		{testBlockTime(30), 20, false},
+	}
+	for _, tc := range cases {
+		number, final, err := bisectBlockBefore(context.Background(), tc.ts)
+		if err != nil {
+			t.Fatalf("ts=%d 查找失败: %v", tc.ts, err)
+		}
+		if number != tc.number || final != tc.final {
+			t.Errorf("ts=%d 得到区块 %d (final=%v)，期望 %d (final=%v)", tc.ts, number, final, tc.number, tc.final)
+		}
+	}
+
+	if _, _, err := bisectBlockBefore(context.Background(), testBlockTime(0)-1); !errors.Is(err, ErrBeforeGenesis) {
+		t.Errorf("早于创世区块的时间戳返回 %v，期望 ErrBeforeGenesis", err)
+	}
+}
+
+func TestResolveBlockByTimeUsesIndex(t *testing.T) {
+	chain := setupScanTest(t)
+	chain.MineEmpty(20)
+	if _, err := NewBlockScanner().runPipeline(context.Background(), 1, 10); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	cases := []struct {
+		ts      int64
+		closest string
+		number  int64
+		source  string
+	}{
+		// 已索引区间 [1, 10]
+		{testBlockTime(5), ClosestBefore, 5, BlockTimeSourceIndex},
+		{testBlockTime(5) + 1, ClosestBefore, 5, BlockTimeSourceIndex},
+		{testBlockTime(5) + 1, ClosestAfter, 6, BlockTimeSourceIndex},
+		{testBlockTime(5), ClosestAfter, 5, BlockTimeSourceIndex},
+		// 区块 10 之后未索引，无法确认区块 11 的时间戳
+		{testBlockTime(10) + 1, ClosestBefore, 10, BlockTimeSourceRPC},
+		{testBlockTime(14) + 1, ClosestAfter, 15, BlockTimeSourceRPC},
+		// 区块 0 未索引
+		{testBlockTime(0) + 1, ClosestAfter, 1, BlockTimeSourceRPC},
+	}
+	for _, tc := range cases {
+		result, final, err := resolveBlockByTime(context.Background(), tc.ts, tc.closest)
+		if err != nil {
+			t.Fatalf("ts=%d closest=%s 查找失败: %v", tc.ts, tc.closest, err)
+		}
+		if result.BlockNumber != tc.number || result.Source != tc.source || !final {
+			t.Errorf("ts=%d closest=%s 得到 %+v (final=%v)，期望区块 %d 来源 %s", tc.ts, tc.closest, result, final, tc.number, tc.source)
+		}
+		if result.BlockTimestamp != testBlockTime(tc.number) {
+			t.Errorf("区块 %d 时间戳为 %d，期望 %d", tc.number, result.BlockTimestamp, testBlockTime(tc.number))
+		}
+	}
+
+	if _, _, err := resolveBlockByTime(context.Background(), testBlockTime(20)+1, ClosestAfter); !errors.Is(err, ErrAfterHead) {
+		t.Errorf("晚于最新区块的时间戳查找 after 返回 %v，期望 ErrAfterHead", err)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/time/rate"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// 记录新部署的合约，合约地址取自交易回执（失败的创建交易不会部署合约）
+func (s *BlockScanner) processContractCreation(data *repository.BlockData, txModel *model.Transaction, contractAddress common.Address) {
+	if txModel.Status != "success" || contractAddress == (common.Address{}) {
+		return
+	}
+	txModel.ContractAddress = contractAddress.Hex()
+	data.Contracts = append(data.Contracts, newContract(data, txModel.TxHash, txModel.FromAddress, contractAddress.Hex()))
+}
+
+func newContract(data *repository.BlockData, txHash, deployer, address string) *model.Contract {
+	now := time.Now()
+	return &model.Contract{
+		Address:        address,
+		Deployer:       deployer,
+		CreationTxHash: txHash,
+		BlockNumber:    data.Block.BlockNumber,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// 检测区块中新部署合约的字节码和代币标准，查询失败只记录日志，查询合约时会重新检测
+func (s *BlockScanner) inspectContracts(ctx context.Context, contracts []*model.Contract) {
+	for _, contract := range contracts {
+		if err := inspectContract(ctx, s.blockRepo, contract, s.limiter); err != nil {
+			util.Log.Warnf("检测合约失败: address=%s, err=%v", contract.Address, err)
+		}
+	}
+}
+
+// 查询合约在创建区块时的字节码并检测代币标准，结果写回 contracts 表
+func inspectContract(ctx context.Context, blockRepo *repository.BlockRepository, contract *model.Contract, limiter *rate.Limiter) error {
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	info, err := util.InspectContract(ctx, common.HexToAddress(contract.Address), big.NewInt(contract.BlockNumber))
+	if err != nil {
+		return err
+	}
+
+	contract.BytecodeHash = info.BytecodeHash.Hex()
+	contract.CodeSize = info.CodeSize
+	contract.Standards = strings.Join(info.Standards, ",")
+	return blockRepo.UpdateContractCode(contract.Address, contract.BytecodeHash, contract.CodeSize, contract.Standards)
+}
+
+// GetContract 查询已索引的合约，尚未检测字节码时重新检测，合约未索引时返回 nil
+func GetContract(address string) (*model.Contract, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("无效的合约地址: %s", address)
+	}
+
+	blockRepo := repository.NewBlockRepository()
+	contract, err := blockRepo.GetContract(common.HexToAddress(address).Hex())
+	if err != nil || contract == nil {
+		return contract, err
+	}
+
+	if contract.BytecodeHash == "" {
+		if err := inspectContract(context.Background(), blockRepo, contract, nil); err != nil {
+			util.Log.Warnf("检测合约失败: address=%s, err=%v", contract.Address, err)
+		}
+	}
+	return contract, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"testing"
+)
+
+// 包含给定函数选择器的字节码
+func selectorCode(selectors ...string) []byte {
+	code := []byte{0x60, 0x80, 0x60, 0x40}
+	for _, selector := range selectors {
+		code = append(code, 0x63)
+		code = append(code, common.FromHex(selector)...)
+		code = append(code, 0x14, 0x61, 0x00, 0x10, 0x57)
+	}
+	return code
+}
+
+func TestScanRecordsContractCreations(t *testing.T) {
+	chain := setupScanTest(t)
+
+	// ERC20 通过字节码函数选择器识别
+	token := chain.NextContractAddress()
+	tokenCode := selectorCode("a9059cbb", "23b872dd", "095ea7b3", "70a08231", "18160ddd", "dd62ed3e")
+	chain.SetCode(token, tokenCode)
+	chain.Mine(testutil.Tx{Deploy: true, Data: []byte{0x60, 0x80}})
+
+	// 代理合约的字节码中没有 NFT 选择器，通过 ERC-165 识别
+	nft := chain.NextContractAddress()
+	chain.SetCode(nft, []byte{0x36, 0x3d, 0x3d, 0x37})
+	chain.SetContract(nft, func(data []byte) ([]byte, error) {
+		if len(data) == 36 && common.Bytes2Hex(data[:4]) == "01ffc9a7" && common.Bytes2Hex(data[4:8]) == "80ac58cd" {
+			return uint256Word(1), nil
+		}
+		return uint256Word(0), nil
+	})
+	chain.Mine(
+		testutil.Tx{Deploy: true, Data: []byte{0x60, 0x80}},
+		// 失败的创建交易不部署合约
+		testutil.Tx{Deploy: true, Data: []byte{0x60, 0x80}, Failed: true},
+	)
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 2); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	if n := countTable(t, &model.Contract{}); n != 2 {
+		t.Errorf("记录了 %d 个合约，期望 2 个", n)
+	}
+
+	cases := []struct {
+		address   common.Address
+		block     int64
+		code      []byte
+		standards string
+	}{
+		{token, 1, tokenCode, "erc20"},
+		{nft, 2, []byte{0x36, 0x3d, 0x3d, 0x37}, "erc721"},
+	}
+	for _, tc := range cases {
+		contract, err := GetContract(tc.address.Hex())
+		if err != nil || contract == nil {
+			t.Fatalf("查询合约 %s 失败: %v", tc.address.Hex(), err)
+		}
+		if contract.Deployer != chain.Sender().Hex() || contract.BlockNumber != tc.block {
+			t.Errorf("合约 %s 部署地址为 %s 区块为 %d，期望 %s 和 %d", tc.address.Hex(), contract.Deployer, contract.BlockNumber, chain.Sender().Hex(), tc.block)
+		}
+		if want := crypto.Keccak256Hash(tc.code).Hex(); contract.BytecodeHash != want || contract.CodeSize != len(tc.code) {
+			t.Errorf("合约 %s 字节码哈希为 %s（%d 字节），期望 %s（%d 字节）", tc.address.Hex(), contract.BytecodeHash, contract.CodeSize, want, len(tc.code))
+		}
+		if contract.Standards != tc.standards {
+			t.Errorf("合约 %s 的代币标准为 %q，期望 %q", tc.address.Hex(), contract.Standards, tc.standards)
+		}
+
+		var tx model.Transaction
+		if err := repository.DB.Where("tx_hash = ?", contract.CreationTxHash).First(&tx).Error; err != nil {
+			t.Fatalf("查询创建交易失败: %v", err)
+		}
+		if tx.ContractAddress != tc.address.Hex() || tx.ToAddress != "" {
+			t.Errorf("创建交易的合约地址为 %s、接收方为 %q，期望 %s 和空", tx.ContractAddress, tx.ToAddress, tc.address.Hex())
+		}
+	}
+
+	if contract, err := GetContract(chain.NextContractAddress().Hex()); err != nil || contract != nil {
+		t.Errorf("未部署的地址查询到合约 %+v, err=%v", contract, err)
+	}
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// 查询池子流动性事件时的事件类型无效
+var ErrInvalidPoolEvent = errors.New("无效的流动性事件类型，可选 mint / burn / sync")
+
+// 兑换事件，与 model 中的流动性事件类型一起用于区分 Uniswap 事件
+const dexEventSwap = "swap"
+
+// Uniswap 兑换和流动性事件按 topic0 区分协议版本，参数定义见内置签名库
+var dexEvents = map[common.Hash]struct{ protocol, event string }{
+	crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address)")):     {model.DexUniswapV2, dexEventSwap},
+	crypto.Keccak256Hash([]byte("Mint(address,uint256,uint256)")):                             {model.DexUniswapV2, model.DexEventMint},
+	crypto.Keccak256Hash([]byte("Burn(address,uint256,uint256,address)")):                     {model.DexUniswapV2, model.DexEventBurn},
+	crypto.Keccak256Hash([]byte("Sync(uint112,uint112)")):                                     {model.DexUniswapV2, model.DexEventSync},
+	crypto.Keccak256Hash([]byte("Swap(address,address,int256,int256,uint160,uint128,int24)")): {model.DexUniswapV3, dexEventSwap},
+	crypto.Keccak256Hash([]byte("Mint(address,address,int24,int24,uint128,uint256,uint256)")): {model.DexUniswapV3, model.DexEventMint},
+	crypto.Keccak256Hash([]byte("Burn(address,int24,int24,uint128,uint256,uint256)")):         {model.DexUniswapV3, model.DexEventBurn},
+}
+
+// 解析区块中的 Uniswap V2 / V3 兑换和流动性事件。发出事件的合约需要能通过 eth_call 查询到 token0 / token1，
+// 否则不是交易池（只是事件签名相同），跳过；查询节点失败返回错误，区块稍后重试
+func (s *BlockScanner) processDexEvents(ctx context.Context, data *repository.BlockData, receipts []*types.Receipt) error {
+	txFrom := make(map[string]string, len(data.Transactions))
+	for _, tx := range data.Transactions {
+		txFrom[tx.TxHash] = tx.FromAddress
+	}
+
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if len(l.Topics) == 0 {
+				continue
+			}
+			kind, ok := dexEvents[l.Topics[0]]
+			if !ok {
+				continue
+			}
+			decoded := decodeBuiltinEvent(l)
+			if decoded == nil {
+				util.Log.Debugf("解码 DEX 事件失败: tx=%s, log=%d", receipt.TxHash.Hex(), l.Index)
+				continue
+			}
+			pool, err := s.loadPool(ctx, l.Address, kind.protocol)
+			if err != nil {
+				return fmt.Errorf("查询交易池 %s 失败: %v", l.Address.Hex(), err)
+			}
+			if pool == nil {
+				continue
+			}
+
+			if kind.event == dexEventSwap {
+				swap := newDexSwap(pool, decoded.Args)
+				if swap == nil {
+					continue
+				}
+				swap.TxHash = receipt.TxHash.Hex()
+				swap.LogIndex = l.Index
+				swap.BlockNumber = data.Block.BlockNumber
+				swap.TxFrom = txFrom[swap.TxHash]
+				data.DexSwaps = append(data.DexSwaps, swap)
+				continue
+			}
+
+			event := newDexPoolEvent(pool, kind.event, decoded.Args)
+			event.TxHash = receipt.TxHash.Hex()
+			event.LogIndex = l.Index
+			event.BlockNumber = data.Block.BlockNumber
+			data.DexPoolEvents = append(data.DexPoolEvents, event)
+		}
+	}
+	return nil
+}
+
+// 按内置签名库解码日志
+func decodeBuiltinEvent(l *types.Log) *util.DecodedEvent {
+	for _, event := range util.BuiltinEvents(l.Topics[0]) {
+		if decoded, err := util.DecodeEventLog(event, l.Topics, l.Data); err == nil {
+			return decoded
+		}
+	}
+	return nil
+}
+
+// 读取或登记交易池，合约不是交易池时返回 nil。结果缓存在内存中，非交易池（token0 / token1 revert 或返回空数据）也缓存为 nil，
+// 避免每次出现同名事件都查询节点；节点错误直接返回，不缓存
+func (s *BlockScanner) loadPool(ctx context.Context, address common.Address, protocol string) (*model.DexPool, error) {
+	if cached, ok := s.knownPools.Load(address); ok {
+		return cached.(*model.DexPool), nil
+	}
+
+	pool, err := s.blockRepo.GetDexPool(address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		tokens, err := util.GetPoolTokens(ctx, address, protocol == model.DexUniswapV3)
+		if err != nil {
+			return nil, err
+		}
+		if tokens == nil {
+			s.knownPools.Store(address, (*model.DexPool)(nil))
+			return nil, nil
+		}
+		pool = &model.DexPool{
+			Address:   address.Hex(),
+			Protocol:  protocol,
+			Token0:    tokens.Token0.Hex(),
+			Token1:    tokens.Token1.Hex(),
+			Fee:       tokens.Fee,
+			CreatedAt: time.Now(),
+		}
+		if err := s.blockRepo.SaveDexPool(pool); err != nil {
+			return nil, err
+		}
+		util.Log.Infof("登记交易池: pool=%s, protocol=%s, token0=%s, token1=%s", pool.Address, protocol, pool.Token0, pool.Token1)
+	}
+	s.knownPools.Store(address, pool)
+	return pool, nil
+}
+
+// 把兑换事件归一为卖出和买入的代币：V2 按 amountIn - amountOut、V3 按 amount0 / amount1 计算池子两种代币的净流入，
+// 净流入为正的代币是卖出的代币。两种代币同向变化（如闪电贷全额归还）时无法确定方向，返回 nil
+func newDexSwap(pool *model.DexPool, args map[string]interface{}) *model.DexSwap {
+	swap := &model.DexSwap{
+		Protocol:  pool.Protocol,
+		Pool:      pool.Address,
+		Sender:    argString(args, "sender"),
+		CreatedAt: time.Now(),
+	}
+
+	var delta0, delta1 *big.Int
+	if pool.Protocol == model.DexUniswapV2 {
+		swap.Recipient = argString(args, "to")
+		delta0 = new(big.Int).Sub(argInt(args, "amount0In"), argInt(args, "amount0Out"))
+		delta1 = new(big.Int).Sub(argInt(args, "amount1In"), argInt(args, "amount1Out"))
+	} else {
+		swap.Recipient = argString(args, "recipient")
+		delta0, delta1 = argInt(args, "amount0"), argInt(args, "amount1")
+	}
+
+	switch {
+	case delta0.Sign() > 0 && delta1.Sign() < 0:
+		swap.TokenIn, swap.TokenOut = pool.Token0, pool.Token1
+		swap.AmountIn, swap.AmountOut = delta0.String(), new(big.Int).Neg(delta1).String()
+	case delta1.Sign() > 0 && delta0.Sign() < 0:
+		swap.TokenIn, swap.TokenOut = pool.Token1, pool.Token0
+		swap.AmountIn, swap.AmountOut = delta1.String(), new(big.Int).Neg(delta0).String()
+	default:
+		return nil
+	}
+	return swap
+}
+
+// 按事件参数构造流动性事件，Sync 事件的储备量记为 Amount0 / Amount1
+func newDexPoolEvent(pool *model.DexPool, event string, args map[string]interface{}) *model.DexPoolEvent {
+	e := &model.DexPoolEvent{
+		Protocol:  pool.Protocol,
+		Pool:      pool.Address,
+		Event:     event,
+		Amount0:   argInt(args, "amount0").String(),
+		Amount1:   argInt(args, "amount1").String(),
+		CreatedAt: time.Now(),
+	}
+	if event == model.DexEventSync {
+		e.Amount0 = argInt(args, "reserve0").String()
+		e.Amount1 = argInt(args, "reserve1").String()
+		return e
+	}
+
+	if pool.Protocol == model.DexUniswapV2 {
+		e.Owner = argString(args, "sender")
+		e.Recipient = argString(args, "to")
+		return e
+	}
+	e.Owner = argString(args, "owner")
+	liquidity := argInt(args, "amount").String()
+	e.Liquidity = &liquidity
+	e.TickLower = argTick(args, "tickLower")
+	e.TickUpper = argTick(args, "tickUpper")
+	return e
+}
+
+// 解码后的字符串参数（地址为十六进制），不存在时返回空字符串
+func argString(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// 解码后的整数参数（十进制字符串），不存在或无法解析时返回 0
+func argInt(args map[string]interface{}, name string) *big.Int {
+	n, ok := new(big.Int).SetString(argString(args, name), 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return n
+}
+
+func argTick(args map[string]interface{}, name string) *int {
+	tick, err := strconv.Atoi(argString(args, name))
+	if err != nil {
+		return nil
+	}
+	return &tick
+}
+
+// GetAddressSwaps 分页查询地址参与的 DEX 兑换（交易发起方、调用方或接收方）
+func GetAddressSwaps(address string, page, size int) ([]model.DexSwap, int64, error) {
+	swaps, total, err := repository.NewBlockRepository().ListSwapsByAddress(common.HexToAddress(address).Hex(), page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	return swaps, total, fillSwapTokenInfo(swaps)
+}
+
+// GetPoolSwaps 分页查询交易池的 DEX 兑换
+func GetPoolSwaps(pool string, page, size int) ([]model.DexSwap, int64, error) {
+	swaps, total, err := repository.NewBlockRepository().ListSwapsByPool(common.HexToAddress(pool).Hex(), page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	return swaps, total, fillSwapTokenInfo(swaps)
+}
+
+// 按代币元数据填充兑换记录的代币符号和换算后的金额，未登记的代币保持原样
+func fillSwapTokenInfo(swaps []model.DexSwap) error {
+	contracts := make([]string, 0, 2*len(swaps))
+	for _, swap := range swaps {
+		contracts = append(contracts, swap.TokenIn, swap.TokenOut)
+	}
+	tokens, err := repository.NewTokenRepository().GetTokens(contracts)
+	if err != nil {
+		return fmt.Errorf("查询代币元数据失败: %v", err)
+	}
+
+	for i := range swaps {
+		if token, ok := tokens[swaps[i].TokenIn]; ok {
+			swaps[i].TokenInSymbol = token.Symbol
+			swaps[i].FormattedAmountIn = formatTokenAmount(token, swaps[i].AmountIn)
+		}
+		if token, ok := tokens[swaps[i].TokenOut]; ok {
+			swaps[i].TokenOutSymbol = token.Symbol
+			swaps[i].FormattedAmountOut = formatTokenAmount(token, swaps[i].AmountOut)
+		}
+	}
+	return nil
+}
+
+// 交易池信息，V2 池带有最近一次 Sync 事件同步的储备量
+type PoolInfo struct {
+	model.DexPool
+	Token0Symbol string  `json:"token0_symbol,omitempty"`
+	Token1Symbol string  `json:"token1_symbol,omitempty"`
+	Reserve0     *string `json:"reserve0"`
+	Reserve1     *string `json:"reserve1"`
+	ReserveBlock *int64  `json:"reserve_block"`
+}
+
+// GetPool 查询已登记的交易池，未登记时返回 nil
+func GetPool(address string) (*PoolInfo, error) {
+	blockRepo := repository.NewBlockRepository()
+	pool, err := blockRepo.GetDexPool(common.HexToAddress(address).Hex())
+	if err != nil || pool == nil {
+		return nil, err
+	}
+
+	info := &PoolInfo{DexPool: *pool}
+	tokens, err := repository.NewTokenRepository().GetTokens([]string{pool.Token0, pool.Token1})
+	if err != nil {
+		return nil, fmt.Errorf("查询代币元数据失败: %v", err)
+	}
+	if token, ok := tokens[pool.Token0]; ok {
+		info.Token0Symbol = token.Symbol
+	}
+	if token, ok := tokens[pool.Token1]; ok {
+		info.Token1Symbol = token.Symbol
+	}
+
+	latest, err := blockRepo.GetLatestPoolSync(pool.Address)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		info.Reserve0, info.Reserve1 = &latest.Amount0, &latest.Amount1
+		info.ReserveBlock = &latest.BlockNumber
+	}
+	return info, nil
+}
+
+// GetPoolEvents 分页查询交易池的流动性事件，event 为空时查询全部类型
+func GetPoolEvents(pool, event string, page, size int) ([]model.DexPoolEvent, int64, error) {
+	switch event {
+	case "", model.DexEventMint, model.DexEventBurn, model.DexEventSync:
+	default:
+		return nil, 0, ErrInvalidPoolEvent
+	}
+	return repository.NewBlockRepository().ListPoolEvents(common.HexToAddress(pool).Hex(), event, page, size)
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/testutil"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"testing"
+)
+
+// 有符号整数按 256 位补码编码为一个 ABI 字
+func int256Word(n int64) []byte {
+	return math.U256Bytes(big.NewInt(n))
+}
+
+// 模拟交易池合约：token0()、token1() 返回代币对，fee 大于 0 时同时实现 V3 的 fee()
+func fakePool(token0, token1 common.Address, fee int64) testutil.ContractFunc {
+	return func(data []byte) ([]byte, error) {
+		switch {
+		case bytes.HasPrefix(data, crypto.Keccak256([]byte("token0()"))[:4]):
+			return common.BytesToHash(token0.Bytes()).Bytes(), nil
+		case bytes.HasPrefix(data, crypto.Keccak256([]byte("token1()"))[:4]):
+			return common.BytesToHash(token1.Bytes()).Bytes(), nil
+		case fee > 0 && bytes.HasPrefix(data, crypto.Keccak256([]byte("fee()"))[:4]):
+			return uint256Word(fee), nil
+		}
+		return nil, errors.New("revert")
+	}
+}
+
+func TestScanIndexesDexSwaps(t *testing.T) {
+	chain := setupScanTest(t)
+
+	router := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	pairV2 := common.HexToAddress("0x0000000000000000000000000000000000000c02")
+	poolV3 := common.HexToAddress("0x0000000000000000000000000000000000000c03")
+	impostor := common.HexToAddress("0x0000000000000000000000000000000000000c04")
+	tokenA := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	tokenB := common.HexToAddress("0x0000000000000000000000000000000000000a02")
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000a11")
+	chain.SetContract(pairV2, fakePool(tokenA, tokenB, 0))
+	chain.SetContract(poolV3, fakePool(tokenA, tokenB, 3000))
+
+	v2Swap := crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address)"))
+	v2Sync := crypto.Keccak256Hash([]byte("Sync(uint112,uint112)"))
+	v2Mint := crypto.Keccak256Hash([]byte("Mint(address,uint256,uint256)"))
+	v2Burn := crypto.Keccak256Hash([]byte("Burn(address,uint256,uint256,address)"))
+	v3Swap := crypto.Keccak256Hash([]byte("Swap(address,address,int256,int256,uint160,uint128,int24)"))
+	v3Mint := crypto.Keccak256Hash([]byte("Mint(address,address,int24,int24,uint128,uint256,uint256)"))
+
+	chain.Mine(testutil.Tx{To: router, Data: []byte{0x01}, Logs: []*types.Log{
+		// V2：卖出 1000 tokenA，买入 1900 tokenB
+		{Address: pairV2, Topics: []common.Hash{v2Swap, addressTopic(router), addressTopic(alice)},
+			Data: bytes.Join([][]byte{uint256Word(1000), uint256Word(0), uint256Word(0), uint256Word(1900)}, nil)},
+		{Address: pairV2, Topics: []common.Hash{v2Sync}, Data: append(uint256Word(11000), uint256Word(18100)...)},
+		// V3：池子收到 250 tokenB，付出 500 tokenA
+		{Address: poolV3, Topics: []common.Hash{v3Swap, addressTopic(router), addressTopic(alice)},
+			Data: bytes.Join([][]byte{int256Word(-500), int256Word(250), uint256Word(1 << 40), uint256Word(1e6), int256Word(-10)}, nil)},
+		// 事件签名相同但不是交易池的合约
+		{Address: impostor, Topics: []common.Hash{v2Swap, addressTopic(router), addressTopic(alice)},
+			Data: bytes.Join([][]byte{uint256Word(1), uint256Word(0), uint256Word(0), uint256Word(1)}, nil)},
+	}})
+	chain.Mine(testutil.Tx{To: router, Data: []byte{0x02}, Logs: []*types.Log{
+		{Address: pairV2, Topics: []common.Hash{v2Mint, addressTopic(router)}, Data: append(uint256Word(10), uint256Word(20)...)},
+		{Address: poolV3, Topics: []common.Hash{v3Mint, addressTopic(alice), common.BytesToHash(int256Word(-600)), common.BytesToHash(int256Word(600))},
+			Data: bytes.Join([][]byte{common.LeftPadBytes(router.Bytes(), 32), uint256Word(77), uint256Word(1), uint256Word(2)}, nil)},
+		{Address: pairV2, Topics: []common.Hash{v2Burn, addressTopic(router), addressTopic(alice)}, Data: append(uint256Word(3), uint256Word(4)...)},
+	}})
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 2); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	if n := countTable(t, &model.DexPool{}); n != 2 {
+		t.Errorf("登记了 %d 个交易池，期望 2 个", n)
+	}
+
+	swaps, total, err := GetAddressSwaps(alice.Hex(), 1, 10)
+	if err != nil || total != 2 {
+		t.Fatalf("地址兑换记录 %d 条（%v），期望 2 条", total, err)
+	}
+	// 按区块号和日志序号倒序
+	v3, v2 := swaps[0], swaps[1]
+	if v2.Protocol != model.DexUniswapV2 || v2.TokenIn != tokenA.Hex() || v2.TokenOut != tokenB.Hex() ||
+		v2.AmountIn != "1000" || v2.AmountOut != "1900" || v2.Sender != router.Hex() || v2.Recipient != alice.Hex() {
+		t.Errorf("V2 兑换记录为 %+v", v2)
+	}
+	if v3.Protocol != model.DexUniswapV3 || v3.TokenIn != tokenB.Hex() || v3.TokenOut != tokenA.Hex() ||
+		v3.AmountIn != "250" || v3.AmountOut != "500" || v3.TxFrom != chain.Sender().Hex() {
+		t.Errorf("V3 兑换记录为 %+v", v3)
+	}
+	if _, total, err := GetAddressSwaps(chain.Sender().Hex(), 1, 10); err != nil || total != 2 {
+		t.Errorf("交易发起方的兑换记录 %d 条（%v），期望 2 条", total, err)
+	}
+	if _, total, err := GetPoolSwaps(impostor.Hex(), 1, 10); err != nil || total != 0 {
+		t.Errorf("非交易池合约的兑换记录 %d 条（%v），期望 0 条", total, err)
+	}
+	// token0 / token1 revert 的合约缓存为非交易池，不再重复查询
+	if cached, ok := scanner.knownPools.Load(impostor); !ok || cached.(*model.DexPool) != nil {
+		t.Errorf("非交易池合约的缓存为 %v（%v），期望缓存为 nil", cached, ok)
+	}
+
+	pool, err := GetPool(pairV2.Hex())
+	if err != nil || pool == nil {
+		t.Fatalf("查询 V2 交易池失败: %v", err)
+	}
+	if pool.Token0 != tokenA.Hex() || pool.Fee != nil || deref(pool.Reserve0) != "11000" || deref(pool.Reserve1) != "18100" {
+		t.Errorf("V2 交易池为 %+v，储备量 %s / %s", pool.DexPool, deref(pool.Reserve0), deref(pool.Reserve1))
+	}
+	pool, err = GetPool(poolV3.Hex())
+	if err != nil || pool == nil || pool.Fee == nil || *pool.Fee != 3000 || pool.Reserve0 != nil {
+		t.Errorf("V3 交易池为 %+v（%v）", pool, err)
+	}
+
+	events, total, err := GetPoolEvents(pairV2.Hex(), "", 1, 10)
+	if err != nil || total != 3 {
+		t.Fatalf("V2 流动性事件 %d 条（%v），期望 3 条", total, err)
+	}
+	if burn := events[0]; burn.Event != model.DexEventBurn || burn.Owner != router.Hex() || burn.Recipient != alice.Hex() || burn.Amount0 != "3" {
+		t.Errorf("V2 Burn 事件为 %+v", burn)
+	}
+	events, _, err = GetPoolEvents(poolV3.Hex(), model.DexEventMint, 1, 10)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("V3 Mint 事件 %d 条（%v），期望 1 条", len(events), err)
+	}
+	mint := events[0]
+	if mint.Owner != alice.Hex() || deref(mint.Liquidity) != "77" || mint.TickLower == nil || *mint.TickLower != -600 || *mint.TickUpper != 600 {
+		t.Errorf("V3 Mint 事件为 %+v", mint)
+	}
+	if _, _, err := GetPoolEvents(poolV3.Hex(), "swap", 1, 10); !errors.Is(err, ErrInvalidPoolEvent) {
+		t.Errorf("按无效事件类型查询返回 %v，期望 ErrInvalidPoolEvent", err)
+	}
+}
+
+// 模拟节点限流错误
+type rateLimitError struct{}
+
+func (rateLimitError) Error() string  { return "limit exceeded" }
+func (rateLimitError) ErrorCode() int { return -32005 }
+
+func TestPoolLookupNodeErrorIsRetried(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.MaxRetries = 5
+	t.Cleanup(func() { config.Cfg.Scanner.MaxRetries = 0 })
+
+	router := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	pair := common.HexToAddress("0x0000000000000000000000000000000000000c02")
+	tokenA := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	tokenB := common.HexToAddress("0x0000000000000000000000000000000000000a02")
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000a11")
+	chain.SetContract(pair, func([]byte) ([]byte, error) { return nil, rateLimitError{} })
+
+	v2Swap := crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address)"))
+	chain.Mine(testutil.Tx{To: router, Data: []byte{0x01}, Logs: []*types.Log{
+		{Address: pair, Topics: []common.Hash{v2Swap, addressTopic(router), addressTopic(alice)},
+			Data: bytes.Join([][]byte{uint256Word(1000), uint256Word(0), uint256Word(0), uint256Word(1900)}, nil)},
+	}})
+
+	// 查询代币对被限流时区块进入重试队列，交易池不会被当作非交易池
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if failedBlock(t, 1) == nil {
+		t.Fatal("查询交易池被限流时区块 1 应进入重试队列")
+	}
+	if n := countTable(t, &model.DexPool{}); n != 0 {
+		t.Errorf("登记了 %d 个交易池，期望 0 个", n)
+	}
+	if _, ok := scanner.knownPools.Load(pair); ok {
+		t.Error("节点错误的查询结果不应缓存")
+	}
+
+	// 节点恢复后重试成功，兑换记录补全
+	chain.SetContract(pair, fakePool(tokenA, tokenB, 0))
+	expireFailedBlock(t, 1)
+	scanner.retryFailedBlocks()
+	if failed := failedBlock(t, 1); failed != nil {
+		t.Fatalf("重试成功后区块 1 仍在重试队列中: %+v", failed)
+	}
+	if _, total, err := GetAddressSwaps(alice.Hex(), 1, 10); err != nil || total != 1 {
+		t.Errorf("地址兑换记录 %d 条（%v），期望 1 条", total, err)
+	}
+}
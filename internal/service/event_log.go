@@ -0,0 +1,274 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"sync"
+	"time"
+)
+
+var (
+	// 事件日志查询参数无效
+	ErrInvalidLogQuery = errors.New("无效的事件查询参数")
+	// 登记的合约 ABI 无法解析
+	ErrInvalidABI = errors.New("无效的合约 ABI")
+)
+
+// 重新解码已保存日志时每批读取的条数
+const decodeLogsBatchSize = 500
+
+// ABI 缓存的地址数上限，超过后清空重新加载
+const abiCacheSize = 10000
+
+// 合约 ABI 缓存：按地址缓存解析后的 ABI，未登记的地址缓存为 nil，避免每条日志都查询数据库
+type abiCache struct {
+	mu   sync.RWMutex
+	abis map[common.Address]*abi.ABI
+}
+
+var contractABIs = &abiCache{abis: make(map[common.Address]*abi.ABI)}
+
+// 获取合约登记的 ABI，未登记时返回 nil
+func (c *abiCache) get(address common.Address) (*abi.ABI, error) {
+	c.mu.RLock()
+	parsed, ok := c.abis[address]
+	c.mu.RUnlock()
+	if ok {
+		return parsed, nil
+	}
+
+	stored, err := repository.NewBlockRepository().GetContractABI(address.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("查询合约 ABI 失败: %v", err)
+	}
+	if stored != nil {
+		if parsed, err = parseABI(stored.ABI); err != nil {
+			util.Log.Warnf("合约 %s 登记的 ABI 无法解析: %v", address.Hex(), err)
+		}
+	}
+	c.set(address, parsed)
+	return parsed, nil
+}
+
+func (c *abiCache) set(address common.Address, parsed *abi.ABI) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.abis) >= abiCacheSize {
+		c.abis = make(map[common.Address]*abi.ABI)
+	}
+	c.abis[address] = parsed
+}
+
+// 清空缓存，数据库切换后使用
+func (c *abiCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.abis = make(map[common.Address]*abi.ABI)
+}
+
+func parseABI(data []byte) (*abi.ABI, error) {
+	parsed, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// 保存交易回执中的全部事件日志，合约登记了 ABI 时同时解码
+func (s *BlockScanner) processLogs(data *repository.BlockData, tx *types.Transaction, receipt *types.Receipt) {
+	for _, l := range receipt.Logs {
+		logModel := &model.Log{
+			TxHash:      tx.Hash().Hex(),
+			LogIndex:    l.Index,
+			BlockNumber: data.Block.BlockNumber,
+			Address:     l.Address.Hex(),
+			Data:        hexutil.Encode(l.Data),
+			CreatedAt:   time.Now(),
+		}
+		topics := []*string{&logModel.Topic0, &logModel.Topic1, &logModel.Topic2, &logModel.Topic3}
+		for i, topic := range l.Topics {
+			if i < len(topics) {
+				*topics[i] = topic.Hex()
+			}
+		}
+		decodeLog(logModel, l.Topics, l.Data)
+		data.Logs = append(data.Logs, logModel)
+	}
+}
+
+// 按合约登记的 ABI 解码事件日志，返回是否解码成功。未登记 ABI 或事件不匹配时清空解码结果
+func decodeLog(logModel *model.Log, topics []common.Hash, data []byte) bool {
+	logModel.EventName, logModel.EventSignature, logModel.Args = "", "", nil
+
+	parsed, err := contractABIs.get(common.HexToAddress(logModel.Address))
+	if err != nil {
+		util.Log.Warnf("解码事件日志失败: %v", err)
+		return false
+	}
+	if parsed == nil || len(topics) == 0 {
+		return false
+	}
+	event, err := parsed.EventByID(topics[0])
+	if err != nil {
+		return false
+	}
+	decoded, err := util.DecodeEventLog(event, topics, data)
+	if err != nil {
+		util.Log.Debugf("解码事件 %s 失败: tx=%s, logIndex=%d, err=%v", event.Sig, logModel.TxHash, logModel.LogIndex, err)
+		return false
+	}
+	args, err := json.Marshal(decoded.Args)
+	if err != nil {
+		return false
+	}
+
+	logModel.EventName = decoded.Name
+	logModel.EventSignature = decoded.Signature
+	logModel.Args = args
+	return true
+}
+
+// RegisterContractABI 登记合约 ABI，之后扫描到的该合约事件按 ABI 解码
+func RegisterContractABI(address, name string, abiJSON []byte) (*model.ContractABI, error) {
+	parsed, err := parseABI(abiJSON)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidABI, err)
+	}
+
+	contract := common.HexToAddress(address)
+	contractABI := &model.ContractABI{
+		Address:   contract.Hex(),
+		Name:      name,
+		ABI:       abiJSON,
+		CreatedAt: time.Now(),
+	}
+	if err := repository.NewBlockRepository().SaveContractABI(contractABI); err != nil {
+		return nil, fmt.Errorf("保存合约 ABI 失败: %v", err)
+	}
+	contractABIs.set(contract, parsed)
+	return contractABI, nil
+}
+
+// GetContractABI 查询合约登记的 ABI，未登记时返回 nil
+func GetContractABI(address string) (*model.ContractABI, error) {
+	return repository.NewBlockRepository().GetContractABI(common.HexToAddress(address).Hex())
+}
+
+// DecodeStoredLogs 按合约当前登记的 ABI 重新解码已保存的事件日志，返回解码成功的条数
+func DecodeStoredLogs(ctx context.Context, address string) (int, error) {
+	blockRepo := repository.NewBlockRepository()
+	contract := common.HexToAddress(address).Hex()
+
+	decoded := 0
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return decoded, err
+		}
+		logs, err := blockRepo.ListContractLogsAfter(contract, afterID, decodeLogsBatchSize)
+		if err != nil {
+			return decoded, fmt.Errorf("读取事件日志失败: %v", err)
+		}
+		if len(logs) == 0 {
+			return decoded, nil
+		}
+
+		for i := range logs {
+			l := &logs[i]
+			if decodeLog(l, logTopics(l), common.FromHex(l.Data)) {
+				decoded++
+			}
+			if err := blockRepo.UpdateLogDecoding(l.ID, l.EventName, l.EventSignature, l.Args); err != nil {
+				return decoded, fmt.Errorf("更新事件日志失败: %v", err)
+			}
+		}
+		afterID = logs[len(logs)-1].ID
+	}
+}
+
+// 已保存日志的 topic 列表
+func logTopics(l *model.Log) []common.Hash {
+	var topics []common.Hash
+	for _, topic := range []string{l.Topic0, l.Topic1, l.Topic2, l.Topic3} {
+		if topic == "" {
+			break
+		}
+		topics = append(topics, common.HexToHash(topic))
+	}
+	return topics
+}
+
+// LogQuery 事件日志查询条件。Args 按 indexed 参数名筛选，需要同时指定合约地址和事件名
+type LogQuery struct {
+	Address   string
+	Event     string
+	Topics    [4]string
+	Args      map[string]string
+	FromBlock *int64
+	ToBlock   *int64
+}
+
+// GetLogs 分页查询事件日志
+func GetLogs(query LogQuery, page, size int) ([]model.Log, int64, error) {
+	filter := repository.LogFilter{
+		EventName: query.Event,
+		FromBlock: query.FromBlock,
+		ToBlock:   query.ToBlock,
+	}
+	if query.Address != "" {
+		filter.Address = common.HexToAddress(query.Address).Hex()
+	}
+	for i, topic := range query.Topics {
+		if topic != "" {
+			filter.Topics[i] = common.HexToHash(topic).Hex()
+		}
+	}
+
+	if len(query.Args) > 0 {
+		if filter.Address == "" || query.Event == "" {
+			return nil, 0, fmt.Errorf("%w: 按参数筛选需要指定合约地址和事件名", ErrInvalidLogQuery)
+		}
+		parsed, err := contractABIs.get(common.HexToAddress(filter.Address))
+		if err != nil {
+			return nil, 0, err
+		}
+		if parsed == nil {
+			return nil, 0, fmt.Errorf("%w: 合约 %s 未登记 ABI", ErrInvalidLogQuery, filter.Address)
+		}
+		event := findEvent(parsed, query.Event)
+		if event == nil {
+			return nil, 0, fmt.Errorf("%w: 合约 ABI 中没有事件 %s", ErrInvalidLogQuery, query.Event)
+		}
+		for name, value := range query.Args {
+			arg, position, err := util.IndexedArgument(event, name)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%w: %v", ErrInvalidLogQuery, err)
+			}
+			topic, err := util.EncodeTopic(arg, value)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%w: %v", ErrInvalidLogQuery, err)
+			}
+			filter.Topics[position] = topic.Hex()
+		}
+	}
+
+	return repository.NewBlockRepository().ListLogs(filter, page, size)
+}
+
+// 按事件名查找 ABI 中的事件，重载事件取 ABI 中的第一个（其余重载的键名带数字后缀）
+func findEvent(parsed *abi.ABI, name string) *abi.Event {
+	if event, ok := parsed.Events[name]; ok {
+		return &event
+	}
+	return nil
+}
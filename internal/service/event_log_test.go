@@ -0,0 +1,156 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 测试用的金库合约 ABI：Deposit 带 indexed 的地址、整数和字符串参数
+const vaultABI = `[
+	{"type":"event","name":"Deposit","inputs":[
+		{"name":"user","type":"address","indexed":true},
+		{"name":"id","type":"uint256","indexed":true},
+		{"name":"tag","type":"string","indexed":true},
+		{"name":"amount","type":"uint256","indexed":false},
+		{"name":"memo","type":"string","indexed":false}
+	]},
+	{"type":"event","name":"Paused","inputs":[]}
+]`
+
+// 按 vaultABI 构造 Deposit 日志
+func depositLog(t *testing.T, contract, user common.Address, id int64, tag string, amount int64, memo string) *types.Log {
+	t.Helper()
+
+	parsed, err := abi.JSON(strings.NewReader(vaultABI))
+	if err != nil {
+		t.Fatalf("解析 ABI 失败: %v", err)
+	}
+	event := parsed.Events["Deposit"]
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(amount), memo)
+	if err != nil {
+		t.Fatalf("编码事件数据失败: %v", err)
+	}
+	return &types.Log{
+		Address: contract,
+		Topics: []common.Hash{
+			event.ID,
+			common.BytesToHash(user.Bytes()),
+			common.BigToHash(big.NewInt(id)),
+			crypto.Keccak256Hash([]byte(tag)),
+		},
+		Data: data,
+	}
+}
+
+func TestScanIndexesAndDecodesLogs(t *testing.T) {
+	chain := setupScanTest(t)
+
+	vault := common.HexToAddress("0x0000000000000000000000000000000000000f01")
+	later := common.HexToAddress("0x0000000000000000000000000000000000000f02")
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000a11")
+	bob := common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+
+	if _, err := RegisterContractABI(vault.Hex(), "Vault", []byte(vaultABI)); err != nil {
+		t.Fatalf("登记 ABI 失败: %v", err)
+	}
+	if _, err := RegisterContractABI(later.Hex(), "Broken", []byte(`{"type":`)); !errors.Is(err, ErrInvalidABI) {
+		t.Fatalf("登记无效 ABI 返回 %v，期望 ErrInvalidABI", err)
+	}
+
+	unknown := &types.Log{Address: vault, Topics: []common.Hash{common.HexToHash("0xdead")}, Data: []byte{0x01}}
+	chain.Mine(testutil.Tx{To: vault, Logs: []*types.Log{
+		depositLog(t, vault, alice, 1, "gold", 100, "first"),
+		unknown,
+		depositLog(t, later, bob, 2, "silver", 200, "later"),
+	}})
+	chain.Mine(testutil.Tx{To: vault, Logs: []*types.Log{
+		depositLog(t, vault, bob, 3, "gold", 300, "second"),
+	}})
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 2); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	// 全部日志都保存，包括无法解码的
+	if n := countTable(t, &model.Log{}); n != 4 {
+		t.Fatalf("保存了 %d 条日志，期望 4 条", n)
+	}
+
+	logs, total, err := GetLogs(LogQuery{Address: vault.Hex(), Event: "Deposit"}, 1, 10)
+	if err != nil {
+		t.Fatalf("查询事件失败: %v", err)
+	}
+	if total != 2 || len(logs) != 2 {
+		t.Fatalf("查询到 %d 条 Deposit 事件，期望 2 条", total)
+	}
+	first := logs[1]
+	if first.EventSignature != "Deposit(address,uint256,string,uint256,string)" {
+		t.Errorf("事件签名为 %s", first.EventSignature)
+	}
+	var args map[string]interface{}
+	if err := json.Unmarshal(first.Args, &args); err != nil {
+		t.Fatalf("解析事件参数失败: %v", err)
+	}
+	want := map[string]interface{}{
+		"user":   alice.Hex(),
+		"id":     "1",
+		"tag":    crypto.Keccak256Hash([]byte("gold")).Hex(),
+		"amount": "100",
+		"memo":   "first",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("事件参数为 %v，期望 %v", args, want)
+	}
+
+	// 按 indexed 参数筛选：地址、整数和按 keccak256 匹配的字符串
+	cases := []struct {
+		args  map[string]string
+		total int64
+	}{
+		{map[string]string{"user": bob.Hex()}, 1},
+		{map[string]string{"id": "1"}, 1},
+		{map[string]string{"tag": "gold"}, 2},
+		{map[string]string{"tag": "gold", "user": alice.Hex()}, 1},
+	}
+	for _, tc := range cases {
+		_, total, err := GetLogs(LogQuery{Address: vault.Hex(), Event: "Deposit", Args: tc.args}, 1, 10)
+		if err != nil || total != tc.total {
+			t.Errorf("按参数 %v 查询到 %d 条事件（%v），期望 %d 条", tc.args, total, err, tc.total)
+		}
+	}
+	if _, _, err := GetLogs(LogQuery{Address: vault.Hex(), Event: "Deposit", Args: map[string]string{"amount": "100"}}, 1, 10); !errors.Is(err, ErrInvalidLogQuery) {
+		t.Errorf("按非 indexed 参数筛选返回 %v，期望 ErrInvalidLogQuery", err)
+	}
+	if _, _, err := GetLogs(LogQuery{Event: "Deposit", Args: map[string]string{"id": "1"}}, 1, 10); !errors.Is(err, ErrInvalidLogQuery) {
+		t.Errorf("未指定合约按参数筛选返回 %v，期望 ErrInvalidLogQuery", err)
+	}
+
+	// 未登记 ABI 的合约日志保持未解码，登记后重新解码已保存的日志
+	pending, _, err := GetLogs(LogQuery{Address: later.Hex()}, 1, 10)
+	if err != nil || len(pending) != 1 || pending[0].EventName != "" {
+		t.Fatalf("未登记 ABI 的日志为 %+v（%v），期望 1 条未解码日志", pending, err)
+	}
+	if _, err := RegisterContractABI(later.Hex(), "Vault", []byte(vaultABI)); err != nil {
+		t.Fatalf("登记 ABI 失败: %v", err)
+	}
+	decoded, err := DecodeStoredLogs(context.Background(), later.Hex())
+	if err != nil || decoded != 1 {
+		t.Fatalf("重新解码了 %d 条日志（%v），期望 1 条", decoded, err)
+	}
+	_, total, err = GetLogs(LogQuery{Address: later.Hex(), Event: "Deposit", Args: map[string]string{"user": bob.Hex()}}, 1, 10)
+	if err != nil || total != 1 {
+		t.Errorf("重新解码后按参数查询到 %d 条事件（%v），期望 1 条", total, err)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 内部交易追踪模式
+const (
+	TraceModeOff   = ""
+	TraceModeDebug = "debug" // debug_traceBlockByNumber + callTracer（Geth）
+	TraceModeTrace = "trace" // trace_block（Erigon / Nethermind / Reth）
+	TraceModeAuto  = "auto"  // 优先 trace_block，节点不支持时改用 debug_traceBlockByNumber
+)
+
+// 按配置的追踪模式获取区块内全部调用帧，追踪关闭或区块没有交易时返回空
+func (s *BlockScanner) fetchTraces(ctx context.Context, block *types.Block) ([]util.TraceCall, error) {
+	mode := config.Cfg.Scanner.TraceMode
+	if mode == TraceModeOff || len(block.Transactions()) == 0 {
+		return nil, nil
+	}
+
+	if mode == TraceModeTrace || (mode == TraceModeAuto && !s.noTraceBlock.Load()) {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		calls, err := util.TraceBlockByParity(ctx, block.Number())
+		switch {
+		case err == nil:
+			return calls, nil
+		case mode == TraceModeAuto && util.IsMethodNotSupported(err):
+			// 记住节点不支持 trace_block，后续区块直接使用 debug_traceBlockByNumber
+			s.noTraceBlock.Store(true)
+			util.Log.Warnf("节点不支持 trace_block，改用 debug_traceBlockByNumber: %v", err)
+		default:
+			return nil, fmt.Errorf("追踪区块失败: %v", err)
+		}
+	} else if mode != TraceModeDebug && mode != TraceModeAuto {
+		return nil, fmt.Errorf("不支持的追踪模式: %s", mode)
+	}
+
+	txHashes := make([]common.Hash, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		txHashes = append(txHashes, tx.Hash())
+	}
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	calls, err := util.TraceBlockByCallTracer(ctx, block.Number(), txHashes)
+	if err != nil {
+		return nil, fmt.Errorf("追踪区块失败: %v", err)
+	}
+	return calls, nil
+}
+
+// 从调用帧中提取内部交易：带 ETH 的内部调用、合约创建和自毁，内部创建的合约同时登记到合约表。
+// 顶层调用即交易本身，已回滚的调用没有实际效果，都不记录
+func (s *BlockScanner) processInternalTransactions(data *repository.BlockData, calls []util.TraceCall) {
+	for _, call := range calls {
+		if len(call.TraceAddress) == 0 || call.Reverted {
+			continue
+		}
+
+		switch call.Type {
+		case model.InternalTxCall:
+			if call.Value.Sign() == 0 {
+				continue
+			}
+		case model.InternalTxCreate, model.InternalTxCreate2, model.InternalTxSelfDestruct:
+		default:
+			// delegatecall / callcode 不转移 ETH，staticcall 不能携带 ETH
+			continue
+		}
+
+		// 合约内部创建的合约同样登记，部署地址为工厂合约
+		if call.Type == model.InternalTxCreate || call.Type == model.InternalTxCreate2 {
+			data.Contracts = append(data.Contracts, newContract(data, call.TxHash.Hex(), call.From.Hex(), call.To.Hex()))
+		}
+
+		data.InternalTransactions = append(data.InternalTransactions, &model.InternalTransaction{
+			TxHash:       call.TxHash.Hex(),
+			TraceAddress: formatTraceAddress(call.TraceAddress),
+			BlockNumber:  data.Block.BlockNumber,
+			Type:         call.Type,
+			FromAddress:  call.From.Hex(),
+			ToAddress:    call.To.Hex(),
+			Value:        util.WeiToEth(call.Value),
+			CreatedAt:    time.Now(),
+		})
+	}
+}
+
+// 调用路径格式化为以点分隔的序号，如 [0 1] -> "0.1"
+func formatTraceAddress(traceAddress []int) string {
+	parts := make([]string, len(traceAddress))
+	for i, n := range traceAddress {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// GetAddressInternalTransactions 分页查询地址的内部交易
+func GetAddressInternalTransactions(address string, page, size int) ([]model.InternalTransaction, int64, error) {
+	return repository.NewBlockRepository().ListInternalTransactionsByAddress(common.HexToAddress(address).Hex(), page, size)
+}
+
+// GetTransactionInternalTransactions 查询交易的内部交易
+func GetTransactionInternalTransactions(txHash string) ([]model.InternalTransaction, error) {
+	return repository.NewBlockRepository().ListInternalTransactionsByTx(common.HexToHash(txHash).Hex())
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// 以 0.1 ETH 为单位的金额
+func tenthEth(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e17))
+}
+
+func TestScanIndexesInternalTransactions(t *testing.T) {
+	wallet := common.HexToAddress("0x0000000000000000000000000000000000000d01")
+	payee := common.HexToAddress("0x0000000000000000000000000000000000000d02")
+	library := common.HexToAddress("0x0000000000000000000000000000000000000d03")
+	created := common.HexToAddress("0x0000000000000000000000000000000000000d04")
+	reverter := common.HexToAddress("0x0000000000000000000000000000000000000d05")
+
+	txs := []testutil.Tx{
+		{To: wallet, Data: []byte{0x01}, Calls: []testutil.Call{
+			{Type: "CALL", From: wallet, To: payee, Value: tenthEth(10)},
+			{Type: "DELEGATECALL", From: wallet, To: library, Value: tenthEth(10)},
+			{Type: "STATICCALL", From: wallet, To: library},
+			{Type: "CALL", From: wallet, To: payee, Value: new(big.Int)},
+			{Type: "CREATE", From: wallet, To: created, Value: tenthEth(5), Calls: []testutil.Call{
+				{Type: "CALL", From: created, To: payee, Value: tenthEth(1)},
+			}},
+			// 失败的调用及其子调用都已回滚
+			{Type: "CALL", From: wallet, To: reverter, Value: tenthEth(1), Error: "execution reverted", Calls: []testutil.Call{
+				{Type: "CALL", From: reverter, To: payee, Value: tenthEth(20)},
+			}},
+			{Type: "SELFDESTRUCT", From: wallet, To: payee, Value: tenthEth(30)},
+		}},
+		// 失败交易中的调用不记录
+		{To: wallet, Data: []byte{0x01}, Failed: true, Calls: []testutil.Call{
+			{Type: "CALL", From: wallet, To: payee, Value: tenthEth(10)},
+		}},
+	}
+
+	type row struct {
+		traceAddress, typ, from, to, value string
+	}
+	want := []row{
+		{"0", model.InternalTxCall, wallet.Hex(), payee.Hex(), "1"},
+		{"4", model.InternalTxCreate, wallet.Hex(), created.Hex(), "0.5"},
+		{"4.0", model.InternalTxCall, created.Hex(), payee.Hex(), "0.1"},
+		{"6", model.InternalTxSelfDestruct, wallet.Hex(), payee.Hex(), "3"},
+	}
+
+	cases := []struct {
+		name, mode string
+		disabled   string
+	}{
+		{"debug", TraceModeDebug, ""},
+		{"trace", TraceModeTrace, ""},
+		{"auto 回退到 debug", TraceModeAuto, "trace_block"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := setupScanTest(t)
+			config.Cfg.Scanner.TraceMode = tc.mode
+			t.Cleanup(func() { config.Cfg.Scanner.TraceMode = TraceModeOff })
+			if tc.disabled != "" {
+				chain.DisableMethod(tc.disabled)
+			}
+			block := chain.Mine(txs...)
+
+			scanner := NewBlockScanner()
+			if _, err := scanner.runPipeline(context.Background(), 1, 1); err != nil {
+				t.Fatalf("扫描失败: %v", err)
+			}
+
+			txHash := block.Transactions()[0].Hash().Hex()
+			internals, err := scanner.blockRepo.ListInternalTransactionsByTx(txHash)
+			if err != nil {
+				t.Fatalf("查询内部交易失败: %v", err)
+			}
+			if n := countTable(t, &model.InternalTransaction{}); n != int64(len(want)) {
+				t.Errorf("共有 %d 条内部交易，期望 %d 条", n, len(want))
+			}
+			if len(internals) != len(want) {
+				t.Fatalf("交易 %s 的内部交易为 %+v，期望 %d 条", txHash, internals, len(want))
+			}
+			for i, w := range want {
+				it := internals[i]
+				// decimal 列读出时补齐了小数位
+				value := strings.TrimRight(strings.TrimRight(it.Value, "0"), ".")
+				if got := (row{it.TraceAddress, it.Type, it.FromAddress, it.ToAddress, value}); got != w {
+					t.Errorf("第 %d 条内部交易为 %+v，期望 %+v", i, got, w)
+				}
+			}
+
+			byAddress, total, err := scanner.blockRepo.ListInternalTransactionsByAddress(created.Hex(), 1, 10)
+			if err != nil {
+				t.Fatalf("按地址查询内部交易失败: %v", err)
+			}
+			if total != 2 || len(byAddress) != 2 {
+				t.Errorf("地址 %s 有 %d 条内部交易，期望 2 条", created.Hex(), total)
+			}
+		})
+	}
+}
+
+func TestScanWithoutTraceMode(t *testing.T) {
+	chain := setupScanTest(t)
+	// 关闭追踪时不应调用追踪接口
+	chain.DisableMethod("debug_traceBlockByNumber")
+	chain.DisableMethod("trace_block")
+	chain.Mine(testutil.Tx{To: common.HexToAddress("0x0000000000000000000000000000000000000d01"), Calls: []testutil.Call{
+		{Type: "CALL", Value: big.NewInt(1)},
+	}})
+
+	if _, err := NewBlockScanner().runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if n := countTable(t, &model.Block{}); n != 1 {
+		t.Errorf("保存了 %d 个区块，期望 1 个", n)
+	}
+	if n := countTable(t, &model.InternalTransaction{}); n != 0 {
+		t.Errorf("追踪关闭时记录了 %d 条内部交易", n)
+	}
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+)
+
+// GetNFTTransfers 分页查询NFT转移记录
+func GetNFTTransfers(filter repository.NFTTransferFilter, page, size int) ([]model.NFTTransfer, int64, error) {
+	return repository.NewBlockRepository().ListNFTTransfers(filter, page, size)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"time"
+)
+
+var (
+	// ERC20 / ERC-721 Transfer(address,address,uint256)，ERC20 的 value 在 data 中，ERC-721 的 tokenId 是第 4 个 topic
+	transferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	// ERC-1155 TransferSingle(address operator, address from, address to, uint256 id, uint256 value)
+	transferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	// ERC-1155 TransferBatch(address operator, address from, address to, uint256[] ids, uint256[] values)
+	transferBatchTopic = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// TransferBatch 事件 data 部分的 ids、values 数组
+var transferBatchData = func() abi.Arguments {
+	uint256Array, _ := abi.NewType("uint256[]", "", nil)
+	return abi.Arguments{{Name: "ids", Type: uint256Array}, {Name: "values", Type: uint256Array}}
+}()
+
+// 判断日志是否为 ERC20 Transfer 事件（3 个 topic，金额在 data 中）
+func isERC20Transfer(log *types.Log) bool {
+	return len(log.Topics) == 3 && log.Topics[0] == transferTopic
+}
+
+// 判断日志是否为 NFT 转移事件（ERC-721 Transfer、ERC-1155 TransferSingle / TransferBatch）
+func isNFTTransfer(log *types.Log) bool {
+	if len(log.Topics) != 4 {
+		return false
+	}
+	topic := log.Topics[0]
+	return topic == transferTopic || topic == transferSingleTopic || topic == transferBatchTopic
+}
+
+// 处理NFT转移事件，一个 TransferBatch 事件按代币 ID 拆分为多条记录
+func (s *BlockScanner) processNFTTransfers(data *repository.BlockData, tx *types.Transaction, receipt *types.Receipt) {
+	for _, log := range receipt.Logs {
+		if !isNFTTransfer(log) {
+			continue
+		}
+
+		transfer := model.NFTTransfer{
+			TxHash:          tx.Hash().Hex(),
+			LogIndex:        log.Index,
+			BlockNumber:     data.Block.BlockNumber,
+			ContractAddress: log.Address.Hex(),
+			CreatedAt:       time.Now(),
+		}
+
+		switch log.Topics[0] {
+		case transferTopic:
+			transfer.Standard = model.NFTStandardERC721
+			transfer.FromAddress = common.BytesToAddress(log.Topics[1].Bytes()).Hex()
+			transfer.ToAddress = common.BytesToAddress(log.Topics[2].Bytes()).Hex()
+			transfer.TokenID = log.Topics[3].Big().String()
+			transfer.Amount = "1"
+			data.NFTTransfers = append(data.NFTTransfers, &transfer)
+
+		case transferSingleTopic:
+			if len(log.Data) < 64 {
+				util.Log.Warnf("TransferSingle 事件数据长度不足: tx=%s, log=%d", transfer.TxHash, log.Index)
+				continue
+			}
+			transfer.Standard = model.NFTStandardERC1155
+			transfer.Operator = common.BytesToAddress(log.Topics[1].Bytes()).Hex()
+			transfer.FromAddress = common.BytesToAddress(log.Topics[2].Bytes()).Hex()
+			transfer.ToAddress = common.BytesToAddress(log.Topics[3].Bytes()).Hex()
+			transfer.TokenID = new(big.Int).SetBytes(log.Data[0:32]).String()
+			transfer.Amount = new(big.Int).SetBytes(log.Data[32:64]).String()
+			data.NFTTransfers = append(data.NFTTransfers, &transfer)
+
+		case transferBatchTopic:
+			values, err := transferBatchData.Unpack(log.Data)
+			if err != nil {
+				util.Log.Warnf("解析 TransferBatch 事件失败: tx=%s, log=%d, err=%v", transfer.TxHash, log.Index, err)
+				continue
+			}
+			ids, amounts := values[0].([]*big.Int), values[1].([]*big.Int)
+			if len(ids) != len(amounts) {
+				util.Log.Warnf("TransferBatch 事件 ids 与 values 长度不一致: tx=%s, log=%d", transfer.TxHash, log.Index)
+				continue
+			}
+			transfer.Standard = model.NFTStandardERC1155
+			transfer.Operator = common.BytesToAddress(log.Topics[1].Bytes()).Hex()
+			transfer.FromAddress = common.BytesToAddress(log.Topics[2].Bytes()).Hex()
+			transfer.ToAddress = common.BytesToAddress(log.Topics[3].Bytes()).Hex()
+			for i := range ids {
+				item := transfer
+				item.BatchIndex = i
+				item.TokenID = ids[i].String()
+				item.Amount = amounts[i].String()
+				data.NFTTransfers = append(data.NFTTransfers, &item)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"testing"
+)
+
+// 地址转为 topic
+func addressTopic(addr common.Address) common.Hash {
+	return common.BytesToHash(addr.Bytes())
+}
+
+// 数值转为 32 字节
+func uint256Word(n int64) []byte {
+	return common.BigToHash(big.NewInt(n)).Bytes()
+}
+
+func TestScanIndexesNFTTransfers(t *testing.T) {
+	chain := setupScanTest(t)
+
+	nft := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	multi := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	operator := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	from := common.HexToAddress("0x0000000000000000000000000000000000000c02")
+	to := common.HexToAddress("0x0000000000000000000000000000000000000c03")
+
+	batchData, err := transferBatchData.Pack([]*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(10), big.NewInt(20)})
+	if err != nil {
+		t.Fatalf("编码 TransferBatch 数据失败: %v", err)
+	}
+
+	chain.Mine(
+		// ERC-721 Transfer，tokenId 在第 4 个 topic
+		testutil.Tx{To: nft, Data: []byte{0x01}, Logs: []*types.Log{{
+			Address: nft,
+			Topics:  []common.Hash{transferTopic, addressTopic(from), addressTopic(to), common.BigToHash(big.NewInt(42))},
+		}}},
+		// ERC-1155 TransferSingle 和 TransferBatch
+		testutil.Tx{To: multi, Data: []byte{0x01}, Logs: []*types.Log{{
+			Address: multi,
+			Topics:  []common.Hash{transferSingleTopic, addressTopic(operator), addressTopic(from), addressTopic(to)},
+			Data:    append(uint256Word(7), uint256Word(5)...),
+		}, {
+			Address: multi,
+			Topics:  []common.Hash{transferBatchTopic, addressTopic(operator), addressTopic(from), addressTopic(to)},
+			Data:    batchData,
+		}}},
+	)
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	var txTypes []string
+	repository.DB.Model(&model.Transaction{}).Order("id").Pluck("tx_type", &txTypes)
+	if len(txTypes) != 2 || txTypes[0] != TxTypeNFTTransfer || txTypes[1] != TxTypeNFTTransfer {
+		t.Errorf("交易类型为 %v，期望都是 %s", txTypes, TxTypeNFTTransfer)
+	}
+	if n := countTable(t, &model.ERC20Transfer{}); n != 0 {
+		t.Errorf("ERC-721 Transfer 被记录为 %d 条 ERC20 转移", n)
+	}
+
+	transfers, total, err := scanner.blockRepo.ListNFTTransfers(repository.NFTTransferFilter{Address: to.Hex()}, 1, 10)
+	if err != nil {
+		t.Fatalf("查询NFT转移记录失败: %v", err)
+	}
+	if total != 4 {
+		t.Fatalf("有 %d 条NFT转移记录，期望 4 条", total)
+	}
+
+	type row struct {
+		standard, contract, operator, tokenID, amount string
+	}
+	got := make(map[string]row)
+	for _, tr := range transfers {
+		got[tr.ContractAddress+"/"+tr.TokenID] = row{tr.Standard, tr.ContractAddress, tr.Operator, tr.TokenID, tr.Amount}
+	}
+	want := []row{
+		{model.NFTStandardERC721, nft.Hex(), "", "42", "1"},
+		{model.NFTStandardERC1155, multi.Hex(), operator.Hex(), "7", "5"},
+		{model.NFTStandardERC1155, multi.Hex(), operator.Hex(), "1", "10"},
+		{model.NFTStandardERC1155, multi.Hex(), operator.Hex(), "2", "20"},
+	}
+	for _, w := range want {
+		if g, ok := got[w.contract+"/"+w.tokenID]; !ok || g != w {
+			t.Errorf("代币 %s/%s 的转移记录为 %+v，期望 %+v", w.contract, w.tokenID, g, w)
+		}
+	}
+
+	byToken, total, err := scanner.blockRepo.ListNFTTransfers(repository.NFTTransferFilter{Contract: multi.Hex(), TokenID: "2"}, 1, 10)
+	if err != nil {
+		t.Fatalf("按代币 ID 查询失败: %v", err)
+	}
+	if total != 1 || byToken[0].BatchIndex != 1 {
+		t.Errorf("按代币 ID 查询到 %d 条记录 %+v，期望 1 条 batch_index 为 1 的记录", total, byToken)
+	}
+}
+
+// 统计表中的行数
+func countTable(t *testing.T, model interface{}) int64 {
+	t.Helper()
+
+	var count int64
+	if err := repository.DB.Model(model).Count(&count).Error; err != nil {
+		t.Fatalf("统计行数失败: %v", err)
+	}
+	return count
+}
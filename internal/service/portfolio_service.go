@@ -0,0 +1,159 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PortfolioToken 代币组合中的一个代币余额
+type PortfolioToken struct {
+	ContractAddress  string `json:"contract_address"`
+	Name             string `json:"name"`
+	Symbol           string `json:"symbol"`
+	Decimals         *int   `json:"decimals"`
+	Balance          string `json:"balance"` // 最小单位的原始余额
+	FormattedBalance string `json:"formatted_balance"`
+}
+
+// Portfolio 地址的 ETH 余额和代币余额
+type Portfolio struct {
+	Address    string           `json:"address"`
+	EthBalance string           `json:"eth_balance"`
+	Tokens     []PortfolioToken `json:"tokens"`
+	// balanceOf 调用失败的合约（非 ERC20 或已自毁）
+	FailedTokens []string `json:"failed_tokens,omitempty"`
+}
+
+// GetPortfolio 查询地址的代币组合，contracts 为空时使用地址在已索引转移记录中出现过的全部代币。
+// 余额通过 Multicall3 查询，每次 eth_call 最多包含 eth.multicallBatchSize 个子调用，结果写入 Redis 缓存。
+func GetPortfolio(address string, contracts []string) (*Portfolio, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("无效的以太坊地址: %s", address)
+	}
+	address = common.HexToAddress(address).Hex()
+	for i, contract := range contracts {
+		if !common.IsHexAddress(contract) {
+			return nil, fmt.Errorf("无效的合约地址: %s", contract)
+		}
+		contracts[i] = common.HexToAddress(contract).Hex()
+	}
+
+	// 1. 查缓存
+	key := portfolioCacheKey(address, contracts)
+	if cached, err := repository.GetPortfolioCache(key); err == nil && cached != "" {
+		var portfolio Portfolio
+		if err := json.Unmarshal([]byte(cached), &portfolio); err == nil {
+			util.Log.Infof("从缓存获取代币组合: address=%s", address)
+			return &portfolio, nil
+		}
+	}
+
+	// 2. 查区块链
+	portfolio, err := buildPortfolio(context.Background(), address, contracts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 写入缓存
+	data, _ := json.Marshal(portfolio)
+	if err := repository.SetPortfolioCache(key, string(data)); err != nil {
+		util.Log.Warnf("缓存代币组合失败: address=%s, err=%v", address, err)
+	}
+
+	// 4. 保存查询记录
+	_ = repository.SaveQueryRecord(model.QueryRecord{
+		Address:   address,
+		QueryType: "portfolio",
+		CreatedAt: time.Now(),
+	})
+
+	return portfolio, nil
+}
+
+// 代币组合的缓存 key：未指定代币时按地址缓存，否则附加排序后代币列表的摘要
+func portfolioCacheKey(address string, contracts []string) string {
+	if len(contracts) == 0 {
+		return address
+	}
+	sorted := append([]string(nil), contracts...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, ",")))
+	return address + ":" + hex.EncodeToString(sum[:8])
+}
+
+// 查询代币组合，显式指定的代币余额为 0 时也返回，从转移记录得到的代币只返回非 0 余额
+func buildPortfolio(ctx context.Context, address string, contracts []string) (*Portfolio, error) {
+	explicit := len(contracts) > 0
+	if !explicit {
+		indexed, err := repository.NewBlockRepository().ListTokenContractsByAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("查询地址的代币转移记录失败: %v", err)
+		}
+		contracts = indexed
+	}
+
+	owner := common.HexToAddress(address)
+	multicall := common.HexToAddress(config.Cfg.Eth.Multicall3)
+	tokenAddrs := make([]common.Address, 0, len(contracts))
+	for _, contract := range contracts {
+		tokenAddrs = append(tokenAddrs, common.HexToAddress(contract))
+	}
+
+	// 第一个子调用查询 ETH 余额，其余依次为各代币的 balanceOf
+	calls, err := util.BalanceOfCalls(tokenAddrs, owner)
+	if err != nil {
+		return nil, err
+	}
+	calls = append([]util.MulticallCall{util.EthBalanceCall(multicall, owner)}, calls...)
+	results, err := util.MulticallBatched(ctx, multicall, calls, config.Cfg.Eth.MulticallBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ethBalance := util.DecodeUint256Result(results[0])
+	if ethBalance == nil {
+		return nil, fmt.Errorf("查询 ETH 余额失败")
+	}
+	portfolio := &Portfolio{
+		Address:    address,
+		EthBalance: util.WeiToEth(ethBalance),
+		Tokens:     make([]PortfolioToken, 0, len(contracts)),
+	}
+
+	tokenRepo := repository.NewTokenRepository()
+	for i, contract := range contracts {
+		balance := util.DecodeUint256Result(results[i+1])
+		if balance == nil {
+			portfolio.FailedTokens = append(portfolio.FailedTokens, contract)
+			continue
+		}
+		if balance.Sign() == 0 && !explicit {
+			continue
+		}
+
+		item := PortfolioToken{ContractAddress: contract, Balance: balance.String()}
+		token, err := loadToken(ctx, tokenRepo, tokenAddrs[i], nil)
+		if err != nil {
+			util.Log.Warnf("获取代币元数据失败: contract=%s, err=%v", contract, err)
+		} else {
+			item.Name = token.Name
+			item.Symbol = token.Symbol
+			item.Decimals = token.Decimals
+			item.FormattedBalance = formatTokenAmount(token, item.Balance)
+		}
+		portfolio.Tokens = append(portfolio.Tokens, item)
+	}
+
+	return portfolio, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"testing"
+)
+
+// 在模拟代币上增加 balanceOf，未列出的地址余额为 0
+func withBalances(fn testutil.ContractFunc, balances map[common.Address]int64) testutil.ContractFunc {
+	return func(data []byte) ([]byte, error) {
+		if len(data) == 36 && common.Bytes2Hex(data[:4]) == "70a08231" { // balanceOf(address)
+			return uint256Word(balances[common.BytesToAddress(data[4:36])]), nil
+		}
+		return fn(data)
+	}
+}
+
+func TestPortfolioUsesIndexedTokens(t *testing.T) {
+	chain := setupScanTest(t)
+
+	multicall := common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	config.Cfg.Eth.Multicall3 = multicall.Hex()
+	chain.DeployMulticall3(multicall)
+	// ETH 余额和 3 个代币共 4 个子调用，分两批查询
+	config.Cfg.Eth.MulticallBatchSize = 2
+	t.Cleanup(func() { config.Cfg.Eth.MulticallBatchSize = 0 })
+
+	owner := common.HexToAddress("0x0000000000000000000000000000000000000f01")
+	other := common.HexToAddress("0x0000000000000000000000000000000000000f02")
+	usdc := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	spent := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	broken := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	chain.SetBalance(owner, big.NewInt(2_000_000_000_000_000_000))
+	chain.SetContract(usdc, withBalances(fakeToken(t, "USD Coin", "USDC", 6, 1e12), map[common.Address]int64{owner: 12_500_000}))
+	chain.SetContract(spent, withBalances(fakeToken(t, "Spent", "SPT", 18, 1e18), nil))
+
+	transfer := func(token, from, to common.Address) testutil.Tx {
+		return testutil.Tx{To: token, Data: []byte{0x01}, Logs: []*types.Log{{
+			Address: token,
+			Topics:  []common.Hash{transferTopic, addressTopic(from), addressTopic(to)},
+			Data:    uint256Word(1),
+		}}}
+	}
+	chain.Mine(transfer(usdc, other, owner), transfer(spent, owner, other), transfer(broken, other, owner))
+	if _, err := NewBlockScanner().runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	portfolio, err := buildPortfolio(context.Background(), owner.Hex(), nil)
+	if err != nil {
+		t.Fatalf("查询代币组合失败: %v", err)
+	}
+	if portfolio.EthBalance != "2" {
+		t.Errorf("ETH 余额为 %s，期望 2", portfolio.EthBalance)
+	}
+	// 余额为 0 的 SPT 不返回，broken 不是ERC20合约
+	if len(portfolio.Tokens) != 1 {
+		t.Fatalf("代币组合为 %+v，期望只有 USDC", portfolio.Tokens)
+	}
+	if got := portfolio.Tokens[0]; got.ContractAddress != usdc.Hex() || got.Symbol != "USDC" ||
+		got.Balance != "12500000" || got.FormattedBalance != "12.5" {
+		t.Errorf("USDC 余额为 %+v，期望 12500000 / 12.5 USDC", got)
+	}
+	if len(portfolio.FailedTokens) != 1 || portfolio.FailedTokens[0] != broken.Hex() {
+		t.Errorf("查询失败的代币为 %v，期望 [%s]", portfolio.FailedTokens, broken.Hex())
+	}
+
+	// 显式指定的代币余额为 0 时也返回
+	explicit, err := buildPortfolio(context.Background(), owner.Hex(), []string{spent.Hex()})
+	if err != nil {
+		t.Fatalf("查询指定代币组合失败: %v", err)
+	}
+	if len(explicit.Tokens) != 1 || explicit.Tokens[0].Symbol != "SPT" || explicit.Tokens[0].FormattedBalance != "0" {
+		t.Errorf("指定代币的组合为 %+v，期望 SPT 余额 0", explicit.Tokens)
+	}
+}
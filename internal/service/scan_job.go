@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 扫描任务类型
+const (
+	ScanJobScan     = "scan"
+	ScanJobBackfill = "backfill"
+	ScanJobFollow   = "follow"
+)
+
+// 扫描任务状态
+const (
+	ScanStateIdle      = "idle"
+	ScanStateRunning   = "running"
+	ScanStatePaused    = "paused"
+	ScanStateCompleted = "completed"
+	ScanStateStopped   = "stopped"
+	ScanStateFailed    = "failed"
+)
+
+// 扫描任务槽：跟随任务单独占用一个槽，区间扫描和回填共用一个槽，两个槽的任务可以同时运行
+const (
+	ScanSlotRange  = "range"
+	ScanSlotFollow = "follow"
+)
+
+// 任务类型所在的槽
+func scanSlot(kind string) string {
+	if kind == ScanJobFollow {
+		return ScanSlotFollow
+	}
+	return ScanSlotRange
+}
+
+var (
+	ErrScanRunning = errors.New("已有扫描任务在运行")
+	ErrScanSlot    = errors.New("无效的扫描任务槽，可选 range / follow")
+	ErrNoScanJob   = errors.New("没有正在运行的扫描任务")
+	ErrScanPaused  = errors.New("扫描任务已暂停")
+	ErrScanActive  = errors.New("扫描任务未暂停")
+)
+
+// ScanStatus 扫描任务状态
+type ScanStatus struct {
+	Kind            string     `json:"kind"`
+	State           string     `json:"state"`
+	FromBlock       int64      `json:"from_block"`
+	CurrentBlock    int64      `json:"current_block"` // 最近处理的区块号
+	TargetBlock     int64      `json:"target_block"`
+	ScannedBlocks   int64      `json:"scanned_blocks"`
+	BlocksPerSecond float64    `json:"blocks_per_second"`
+	ETASeconds      int64      `json:"eta_seconds"` // 预计剩余秒数，-1 表示未知
+	LastError       string     `json:"last_error,omitempty"`
+	LastErrorAt     *time.Time `json:"last_error_at,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+// 当前（或最近一次）扫描任务
+type scanJob struct {
+	status    ScanStatus
+	cancel    context.CancelFunc
+	resume    chan struct{} // 暂停时非空，恢复时关闭
+	done      chan struct{}
+	activeFor time.Duration // 不含暂停的累计运行时长（截至 resumedAt）
+	resumedAt time.Time     // 最近一次开始或恢复运行的时间
+	rangeSet  bool          // 是否已记录起始区块
+}
+
+func (j *scanJob) active() bool {
+	return j != nil && (j.status.State == ScanStateRunning || j.status.State == ScanStatePaused)
+}
+
+// 任务上下文中保存当前任务，供流水线更新进度和检查暂停
+type scanJobKey struct{}
+
+func jobFromContext(ctx context.Context) *scanJob {
+	job, _ := ctx.Value(scanJobKey{}).(*scanJob)
+	return job
+}
+
+// 启动扫描任务，每个槽同一时间只允许一个任务运行
+func (s *BlockScanner) startJob(kind string, run func(ctx context.Context) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slot := scanSlot(kind)
+	if s.jobs[slot].active() {
+		return ErrScanRunning
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	now := time.Now()
+	job := &scanJob{
+		status: ScanStatus{
+			Kind:       kind,
+			State:      ScanStateRunning,
+			ETASeconds: -1,
+			StartedAt:  &now,
+		},
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		resumedAt: now,
+	}
+	s.jobs[slot] = job
+	ctx = context.WithValue(ctx, scanJobKey{}, job)
+
+	go func() {
+		defer close(job.done)
+		defer cancel()
+
+		err := run(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		finished := time.Now()
+		if job.status.State == ScanStateRunning {
+			job.activeFor += finished.Sub(job.resumedAt)
+		}
+		job.status.FinishedAt = &finished
+		switch {
+		case ctx.Err() != nil:
+			job.status.State = ScanStateStopped
+		case err != nil:
+			job.status.State = ScanStateFailed
+			job.status.LastError = err.Error()
+			job.status.LastErrorAt = &finished
+		default:
+			job.status.State = ScanStateCompleted
+		}
+	}()
+
+	return nil
+}
+
+// 槽中正在运行的任务，槽无效时返回 ErrScanSlot，没有运行中的任务时返回 ErrNoScanJob。调用方需持有 s.mu
+func (s *BlockScanner) activeJob(slot string) (*scanJob, error) {
+	if slot != ScanSlotRange && slot != ScanSlotFollow {
+		return nil, ErrScanSlot
+	}
+	job := s.jobs[slot]
+	if !job.active() {
+		return nil, ErrNoScanJob
+	}
+	return job, nil
+}
+
+// 停止槽中的扫描任务
+func (s *BlockScanner) Stop(slot string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.activeJob(slot)
+	if err != nil {
+		return err
+	}
+	job.cancel()
+	return nil
+}
+
+// 关闭扫描器：取消后台重试和全部扫描任务，并等待扫描任务退出
+func (s *BlockScanner) Close() {
+	s.cancel()
+	for _, slot := range []string{ScanSlotRange, ScanSlotFollow} {
+		s.Wait(slot)
+	}
+}
+
+// 暂停槽中的扫描任务：已抓取的区块提交完后不再分发新区块
+func (s *BlockScanner) Pause(slot string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.activeJob(slot)
+	if err != nil {
+		return err
+	}
+	if job.status.State == ScanStatePaused {
+		return ErrScanPaused
+	}
+
+	job.status.State = ScanStatePaused
+	job.resume = make(chan struct{})
+	job.activeFor += time.Since(job.resumedAt)
+	return nil
+}
+
+// 恢复槽中已暂停的扫描任务
+func (s *BlockScanner) Resume(slot string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := s.activeJob(slot)
+	if err != nil {
+		return err
+	}
+	if job.status.State != ScanStatePaused {
+		return ErrScanActive
+	}
+
+	job.status.State = ScanStateRunning
+	close(job.resume)
+	job.resume = nil
+	job.resumedAt = time.Now()
+	return nil
+}
+
+// 等待槽中的扫描任务结束
+func (s *BlockScanner) Wait(slot string) {
+	s.mu.Lock()
+	job := s.jobs[slot]
+	s.mu.Unlock()
+
+	if job != nil {
+		<-job.done
+	}
+}
+
+// 获取槽中当前（或最近一次）扫描任务的状态
+func (s *BlockScanner) Status(slot string) ScanStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.jobs[slot]
+	if job == nil {
+		return ScanStatus{State: ScanStateIdle, ETASeconds: -1}
+	}
+
+	status := job.status
+	active := job.activeFor
+	if status.State == ScanStateRunning {
+		active += time.Since(job.resumedAt)
+	}
+	if active > 0 && status.ScannedBlocks > 0 {
+		status.BlocksPerSecond = float64(status.ScannedBlocks) / active.Seconds()
+	}
+	if job.active() && status.BlocksPerSecond > 0 {
+		remaining := status.TargetBlock - status.CurrentBlock
+		if remaining < 0 {
+			remaining = 0
+		}
+		status.ETASeconds = int64(float64(remaining) / status.BlocksPerSecond)
+	}
+	return status
+}
+
+// 暂停期间阻塞，直到恢复或任务被停止
+func (s *BlockScanner) waitIfPaused(ctx context.Context) error {
+	job := jobFromContext(ctx)
+	if job == nil {
+		return nil
+	}
+	s.mu.Lock()
+	resume := job.resume
+	s.mu.Unlock()
+
+	if resume == nil {
+		return nil
+	}
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 更新当前任务的扫描区间
+func (s *BlockScanner) setJobRange(ctx context.Context, from, target int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := jobFromContext(ctx)
+	if !job.active() {
+		return
+	}
+	if !job.rangeSet {
+		job.status.FromBlock = from
+		job.rangeSet = true
+	}
+	job.status.TargetBlock = target
+}
+
+// 记录当前任务处理完一个区块，err 非空表示该区块扫描失败
+func (s *BlockScanner) reportBlock(ctx context.Context, blockNumber int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := jobFromContext(ctx)
+	if !job.active() {
+		return
+	}
+	job.status.CurrentBlock = blockNumber
+	job.status.ScannedBlocks++
+	if err != nil {
+		now := time.Now()
+		job.status.LastError = err.Error()
+		job.status.LastErrorAt = &now
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"sync"
+)
+
+// 单个区块的抓取结果
+type fetchedBlock struct {
+	number   int64
+	block    *types.Block
+	receipts []*types.Receipt // 与 block.Transactions() 一一对应
+	traces   []util.TraceCall // 追踪模式下区块内全部调用帧
+	err      error
+}
+
+// 并行抓取 [from, to] 区间的区块并按区块号顺序提交，返回下一个待提交的区块号。
+// 抓取可以乱序完成，已抓取未提交的区块数不超过 scanner.queueSize。
+func (s *BlockScanner) runPipeline(parent context.Context, from, to int64) (int64, error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	workers := config.Cfg.Scanner.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queueSize := config.Cfg.Scanner.QueueSize
+	if queueSize < workers {
+		queueSize = workers
+	}
+
+	jobs := make(chan int64)
+	results := make(chan *fetchedBlock, queueSize)
+	// 提交后才释放名额，防止抓取速度远超提交速度时无限堆积
+	window := make(chan struct{}, queueSize)
+
+	// 按顺序分发区块号，任务暂停时停止分发
+	go func() {
+		defer close(jobs)
+		for n := from; n <= to; n++ {
+			if err := s.waitIfPaused(ctx); err != nil {
+				return
+			}
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// 抓取 worker
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				fb := s.fetchBlock(ctx, n)
+				select {
+				case results <- fb:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// 按区块号顺序提交
+	pending := make(map[int64]*fetchedBlock)
+	next := from
+	for fb := range results {
+		pending[fb.number] = fb
+		for {
+			if ctx.Err() != nil {
+				return next, nil
+			}
+			fb, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+
+			err := fb.err
+			if err == nil {
+				err = s.commitBlock(ctx, fb)
+			}
+
+			// 链重组：从共同祖先重新扫描；重组过深：停止任务，不推进断点
+			var reorgErr *ReorgError
+			if errors.As(err, &reorgErr) || errors.Is(err, ErrReorgTooDeep) {
+				return next, err
+			}
+			s.reportBlock(ctx, next, err)
+			if err != nil {
+				// 失败区块进入重试队列，断点照常推进；未能进入重试队列时停止任务，
+				// 否则后续区块推进断点后该区块会成为永久空洞
+				util.Log.Errorf("扫描区块 %d 失败: %v", next, err)
+				if recordErr := s.recordFailure(next, err); recordErr != nil {
+					return next, fmt.Errorf("区块 %d 扫描失败且未能加入重试队列: %w", next, recordErr)
+				}
+			} else {
+				util.Log.Infof("成功扫描区块: %d", next)
+			}
+			s.saveProgress(next)
+
+			<-window
+			next++
+		}
+	}
+
+	return next, nil
+}
+
+// 抓取区块及其全部交易回执，开启追踪模式时同时获取调用帧
+func (s *BlockScanner) fetchBlock(ctx context.Context, blockNumber int64) *fetchedBlock {
+	fb := &fetchedBlock{number: blockNumber}
+
+	if err := s.limiter.Wait(ctx); err != nil {
+		fb.err = err
+		return fb
+	}
+	block, err := util.EthClient.BlockByNumber(ctx, big.NewInt(blockNumber))
+	if err != nil {
+		fb.err = fmt.Errorf("获取区块失败: %v", err)
+		return fb
+	}
+	fb.block = block
+
+	receipts, err := s.fetchReceipts(ctx, block)
+	if err != nil {
+		fb.err = err
+		return fb
+	}
+	fb.receipts = receipts
+
+	traces, err := s.fetchTraces(ctx, block)
+	if err != nil {
+		fb.err = err
+		return fb
+	}
+	fb.traces = traces
+
+	return fb
+}
+
+// 获取区块内全部交易回执：优先使用 eth_getBlockReceipts，节点不支持时改用批量 eth_getTransactionReceipt
+func (s *BlockScanner) fetchReceipts(ctx context.Context, block *types.Block) ([]*types.Receipt, error) {
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	if !s.noBlockReceipts.Load() {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		receipts, err := util.GetBlockReceipts(ctx, block.Hash())
+		switch {
+		case err == nil && len(receipts) == len(txs):
+			return receipts, nil
+		case err == nil:
+			util.Log.Warnf("区块 %d 回执数量 %d 与交易数量 %d 不一致，改用批量请求", block.NumberU64(), len(receipts), len(txs))
+		case util.IsMethodNotSupported(err):
+			// 记住节点不支持该方法，后续区块直接走批量请求
+			s.noBlockReceipts.Store(true)
+			util.Log.Warnf("节点不支持 eth_getBlockReceipts，改用批量 eth_getTransactionReceipt: %v", err)
+		default:
+			return nil, fmt.Errorf("获取区块回执失败: %v", err)
+		}
+	}
+
+	batchSize := config.Cfg.Scanner.ReceiptBatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	receipts := make([]*types.Receipt, 0, len(txs))
+	for start := 0; start < len(txs); start += batchSize {
+		end := start + batchSize
+		if end > len(txs) {
+			end = len(txs)
+		}
+
+		hashes := make([]common.Hash, 0, end-start)
+		for _, tx := range txs[start:end] {
+			hashes = append(hashes, tx.Hash())
+		}
+
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		batch, err := util.BatchGetReceipts(ctx, hashes)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, batch...)
+	}
+
+	return receipts, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPipelineCommitsInOrder(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.Workers = 4
+	config.Cfg.Scanner.QueueSize = 8
+
+	for i := 0; i < 12; i++ {
+		chain.Mine(testutil.Tx{To: chain.Sender()})
+	}
+	// 同一批次中区块号越小返回越慢，worker 会乱序完成抓取
+	chain.SetLatency(func(number uint64) time.Duration {
+		return time.Duration(4-number%4) * 10 * time.Millisecond
+	})
+
+	var mu sync.Mutex
+	var committed []int64
+	err := repository.DB.Callback().Create().After("gorm:create").Register("test:block_order", func(db *gorm.DB) {
+		if block, ok := db.Statement.Dest.(*model.Block); ok {
+			mu.Lock()
+			committed = append(committed, block.BlockNumber)
+			mu.Unlock()
+		}
+	})
+	if err != nil {
+		t.Fatalf("注册回调失败: %v", err)
+	}
+
+	next, err := NewBlockScanner().runPipeline(context.Background(), 1, 12)
+	if err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if next != 13 {
+		t.Errorf("下一个待扫描区块为 %d，期望 13", next)
+	}
+
+	if len(committed) != 12 {
+		t.Fatalf("提交了 %d 个区块，期望 12 个", len(committed))
+	}
+	for i, number := range committed {
+		if number != int64(i+1) {
+			t.Fatalf("区块提交顺序为 %v，期望按区块号递增", committed)
+		}
+	}
+	assertCanonical(t, chain, 1, 12)
+}
+
+func TestFetchReceiptsFallsBackToBatch(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.Workers = 1
+	config.Cfg.Scanner.ReceiptBatchSize = 2
+	chain.DisableMethod("eth_getBlockReceipts")
+
+	receiver := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	for i := 0; i < 3; i++ {
+		chain.Mine(
+			testutil.Tx{To: receiver, Value: big.NewInt(1)},
+			testutil.Tx{To: receiver, Value: big.NewInt(2), Failed: true},
+			testutil.Tx{To: receiver, Value: big.NewInt(3)},
+		)
+	}
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 3); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if !scanner.noBlockReceipts.Load() {
+		t.Error("节点不支持 eth_getBlockReceipts 时应记住并改用批量请求")
+	}
+
+	var txs []model.Transaction
+	repository.DB.Order("block_number, id").Find(&txs)
+	if len(txs) != 9 {
+		t.Fatalf("保存了 %d 笔交易，期望 9 笔", len(txs))
+	}
+	for i, tx := range txs {
+		want := "success"
+		if i%3 == 1 {
+			want = "failed"
+		}
+		if tx.Status != want || tx.GasUsed == nil || *tx.GasUsed != 21000 {
+			t.Errorf("区块 %d 第 %d 笔交易状态为 %s，期望 %s，且 gas_used 为 21000", tx.BlockNumber, i%3, tx.Status, want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"errors"
+	"time"
+)
+
+// 断点续扫的起始区块号：优先使用扫描断点，没有断点时从数据库中最新的区块+1开始
+func (s *BlockScanner) resumeBlock() (int64, error) {
+	lastBlock, ok, err := s.blockRepo.GetScanProgress(repository.DefaultScanName)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		lastBlock, err = s.blockRepo.GetLatestBlockNumber()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return lastBlock + 1, nil
+}
+
+// 推进扫描断点
+func (s *BlockScanner) saveProgress(blockNumber int64) {
+	if err := s.blockRepo.SaveScanProgress(repository.DefaultScanName, blockNumber); err != nil {
+		util.Log.Errorf("保存扫描断点失败: block=%d, err=%v", blockNumber, err)
+	}
+}
+
+// 将扫描失败的区块放入重试队列，按指数退避安排下次重试
+func (s *BlockScanner) recordFailure(blockNumber int64, cause error) error {
+	err := s.blockRepo.RecordFailedBlock(blockNumber, cause.Error(), config.Cfg.Scanner.MaxRetries, retryBackoff)
+	if err != nil {
+		util.Log.Errorf("记录失败区块 %d 失败: %v", blockNumber, err)
+	}
+	return err
+}
+
+// 第 retryCount 次重试前的等待时间：base * 2^retryCount，不超过 retryMaxDelay
+func retryBackoff(retryCount int) time.Duration {
+	delay := config.Cfg.Scanner.RetryBaseDelay
+	maxDelay := config.Cfg.Scanner.RetryMaxDelay
+	for i := 0; i < retryCount && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// 后台定期重试失败区块，直到扫描器停止
+func (s *BlockScanner) RunRetrier() {
+	ticker := time.NewTicker(config.Cfg.Scanner.RetryInterval)
+	defer ticker.Stop()
+
+	util.Log.Infof("失败区块重试已启动，间隔 %s", config.Cfg.Scanner.RetryInterval)
+	for {
+		select {
+		case <-s.ctx.Done():
+			util.Log.Info("失败区块重试已停止")
+			return
+		case <-ticker.C:
+			s.retryFailedBlocks()
+		}
+	}
+}
+
+// 重试一批已到期的失败区块
+func (s *BlockScanner) retryFailedBlocks() {
+	blocks, err := s.blockRepo.ListDueFailedBlocks(time.Now(), 50)
+	if err != nil {
+		util.Log.Errorf("查询失败区块失败: %v", err)
+		return
+	}
+
+	for _, failed := range blocks {
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		fb := s.fetchBlock(s.ctx, failed.BlockNumber)
+		err := fb.err
+		if err == nil {
+			err = s.commitBlock(s.ctx, fb)
+		}
+
+		var reorgErr *ReorgError
+		switch {
+		case err == nil:
+			util.Log.Infof("重试区块 %d 成功", failed.BlockNumber)
+		case errors.As(err, &reorgErr):
+			// 回滚会清理之后的失败记录，本批剩余区块不再处理
+			util.Log.Warn(reorgErr.Error())
+			return
+		default:
+			util.Log.Errorf("重试区块 %d 失败（第 %d 次）: %v", failed.BlockNumber, failed.RetryCount+1, err)
+			_ = s.recordFailure(failed.BlockNumber, err)
+		}
+	}
+}
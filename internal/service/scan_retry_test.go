@@ -0,0 +1,113 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"testing"
+	"time"
+)
+
+// 让失败区块立即到期
+func expireFailedBlock(t *testing.T, blockNumber int64) {
+	t.Helper()
+
+	err := repository.DB.Model(&model.FailedBlock{}).Where("block_number = ?", blockNumber).
+		Update("next_retry_at", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatalf("更新重试时间失败: %v", err)
+	}
+}
+
+// 读取区块的失败记录，不存在时返回 nil
+func failedBlock(t *testing.T, blockNumber int64) *model.FailedBlock {
+	t.Helper()
+
+	var failed []model.FailedBlock
+	if err := repository.DB.Where("block_number = ?", blockNumber).Find(&failed).Error; err != nil {
+		t.Fatalf("查询失败区块失败: %v", err)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &failed[0]
+}
+
+func TestFailedBlockIsRetriedWithBackoff(t *testing.T) {
+	chain := setupScanTest(t)
+	config.Cfg.Scanner.Workers = 2
+	config.Cfg.Scanner.MaxRetries = 5
+	config.Cfg.Scanner.RetryBaseDelay = time.Minute
+	config.Cfg.Scanner.RetryMaxDelay = time.Hour
+
+	for i := 0; i < 3; i++ {
+		chain.Mine(testutil.Tx{To: chain.Sender()})
+	}
+	chain.SetUnavailable(2, true)
+
+	scanner := NewBlockScanner()
+	if next, err := scanner.runPipeline(context.Background(), 1, 3); err != nil || next != 4 {
+		t.Fatalf("扫描结果 next=%d, err=%v，期望扫描完区块 3", next, err)
+	}
+	if progress, _, _ := scanner.blockRepo.GetScanProgress(repository.DefaultScanName); progress != 3 {
+		t.Errorf("扫描断点为 %d，失败区块进入重试队列后断点应推进到 3", progress)
+	}
+	failed := failedBlock(t, 2)
+	if failed == nil || failed.RetryCount != 0 || failed.Status != model.FailedBlockPending {
+		t.Fatalf("区块 2 的失败记录为 %+v，期望待重试且未重试过", failed)
+	}
+
+	// 未到重试时间
+	scanner.retryFailedBlocks()
+	if failedBlock(t, 2) == nil {
+		t.Fatal("未到重试时间的区块不应被重试")
+	}
+
+	// 到期后重试仍然失败，等待时间翻倍
+	expireFailedBlock(t, 2)
+	scanner.retryFailedBlocks()
+	failed = failedBlock(t, 2)
+	if failed == nil || failed.RetryCount != 1 {
+		t.Fatalf("区块 2 的失败记录为 %+v，期望已重试 1 次", failed)
+	}
+	if wait := time.Until(failed.NextRetryAt); wait < 90*time.Second || wait > 2*time.Minute {
+		t.Errorf("第 2 次重试在 %s 后，期望约 2 分钟", wait)
+	}
+
+	// 节点恢复后重试成功，区块移出重试队列
+	chain.SetUnavailable(2, false)
+	expireFailedBlock(t, 2)
+	scanner.retryFailedBlocks()
+	if failed := failedBlock(t, 2); failed != nil {
+		t.Errorf("重试成功后区块 2 仍在重试队列中: %+v", failed)
+	}
+	assertCanonical(t, chain, 1, 3)
+}
+
+func TestScanStopsWhenFailedBlockCannotBeRecorded(t *testing.T) {
+	chain := setupScanTest(t)
+
+	for i := 0; i < 3; i++ {
+		chain.Mine(testutil.Tx{To: chain.Sender()})
+	}
+	chain.SetUnavailable(2, true)
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描区块 1 失败: %v", err)
+	}
+	// 失败区块表不可写
+	if err := repository.DB.Migrator().DropTable(&model.FailedBlock{}); err != nil {
+		t.Fatalf("删除失败区块表失败: %v", err)
+	}
+
+	next, err := scanner.runPipeline(context.Background(), 2, 3)
+	if err == nil || next != 2 {
+		t.Fatalf("扫描结果 next=%d, err=%v，期望在区块 2 停止并返回错误", next, err)
+	}
+	if progress, _, _ := scanner.blockRepo.GetScanProgress(repository.DefaultScanName); progress != 1 {
+		t.Errorf("扫描断点为 %d，区块 2 未能进入重试队列时断点应停在 1", progress)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"errors"
+	"fmt"
+)
+
+// GetFailedBlocks 分页查询扫描失败的区块，status 为空时返回全部
+func GetFailedBlocks(status string, page, size int) ([]model.FailedBlock, int64, error) {
+	return repository.NewBlockRepository().ListFailedBlocks(status, page, size)
+}
+
+// GetReorgEvents 分页查询已处理的链重组事件，total 为累计次数
+func GetReorgEvents(page, size int) ([]model.ReorgEvent, int64, error) {
+	return repository.NewBlockRepository().ListReorgEvents(page, size)
+}
+
+// 未指定起始区块且数据库中还没有区块时无法确定回填区间
+var ErrNoIndexedBlocks = errors.New("数据库中还没有区块，请指定起始区块号")
+
+// FindBlockGaps 查找 [from, to] 区间内缺失的区块区段。from 小于 0 时使用数据库中最早的区块号，
+// 避免把索引起点之前的区块都当作缺失；to 不大于 0 时使用数据库中最新的区块号
+func FindBlockGaps(from, to int64) ([]repository.BlockGap, error) {
+	blockRepo := repository.NewBlockRepository()
+	if from < 0 {
+		earliest, ok, err := blockRepo.GetEarliestBlockNumber()
+		if err != nil {
+			return nil, fmt.Errorf("获取最早区块号失败: %v", err)
+		}
+		if !ok {
+			return nil, ErrNoIndexedBlocks
+		}
+		from = earliest
+	}
+	if to <= 0 {
+		latest, err := blockRepo.GetLatestBlockNumber()
+		if err != nil {
+			return nil, fmt.Errorf("获取最新区块号失败: %v", err)
+		}
+		to = latest
+	}
+	if from > to {
+		return nil, fmt.Errorf("无效的区块区间: %d 到 %d", from, to)
+	}
+
+	return blockRepo.FindGaps(from, to)
+}
+
+// 统计区段内的区块总数
+func CountGapBlocks(gaps []repository.BlockGap) int64 {
+	var count int64
+	for _, gap := range gaps {
+		count += gap.To - gap.From + 1
+	}
+	return count
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/time/rate"
+	"time"
+	"unicode/utf8"
+)
+
+// GetToken 获取代币元数据：优先读取 tokens 表，未登记时通过 eth_call 查询并保存
+func GetToken(ctx context.Context, contractAddress string) (*model.Token, error) {
+	if !common.IsHexAddress(contractAddress) {
+		return nil, fmt.Errorf("无效的合约地址: %s", contractAddress)
+	}
+	return loadToken(ctx, repository.NewTokenRepository(), common.HexToAddress(contractAddress), nil)
+}
+
+// 读取或登记代币元数据，limiter 不为空时查询节点前先等待速率预算
+func loadToken(ctx context.Context, tokenRepo *repository.TokenRepository, contract common.Address, limiter *rate.Limiter) (*model.Token, error) {
+	token, err := tokenRepo.GetToken(contract.Hex())
+	if err != nil || token != nil {
+		return token, err
+	}
+
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	meta, err := util.GetTokenMetadata(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	// 垃圾代币的元数据可能超出列长度，截断后保存，避免登记失败后每个区块都重新查询
+	token = &model.Token{
+		ContractAddress: contract.Hex(),
+		Name:            truncateRunes(meta.Name, model.TokenNameMaxLen),
+		Symbol:          truncateRunes(meta.Symbol, model.TokenSymbolMaxLen),
+		Decimals:        meta.Decimals,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if meta.TotalSupply != nil {
+		token.TotalSupply = meta.TotalSupply.String()
+	}
+	if err := tokenRepo.SaveToken(token); err != nil {
+		return nil, fmt.Errorf("保存代币元数据失败: %v", err)
+	}
+	util.Log.Infof("登记代币: contract=%s, name=%s, symbol=%s", token.ContractAddress, token.Name, token.Symbol)
+	return token, nil
+}
+
+// 按字符数截断字符串，不会截断多字节字符
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// 登记区块中首次出现的ERC20合约，元数据查询失败只记录日志，不影响扫描
+func (s *BlockScanner) registerTokens(ctx context.Context, transfers []*model.ERC20Transfer) {
+	for _, transfer := range transfers {
+		contract := transfer.ContractAddress
+		if _, ok := s.knownTokens.Load(contract); ok {
+			continue
+		}
+		if _, err := loadToken(ctx, s.tokenRepo, common.HexToAddress(contract), s.limiter); err != nil {
+			util.Log.Warnf("登记代币元数据失败: contract=%s, err=%v", contract, err)
+			continue
+		}
+		s.knownTokens.Store(contract, struct{}{})
+	}
+}
+
+// 按代币元数据填充ERC20转移记录的符号和换算后的金额，未登记的合约保持原样
+func fillTokenInfo(transfers []model.ERC20Transfer) error {
+	contracts := make([]string, 0, len(transfers))
+	for _, transfer := range transfers {
+		contracts = append(contracts, transfer.ContractAddress)
+	}
+	tokens, err := repository.NewTokenRepository().GetTokens(contracts)
+	if err != nil {
+		return fmt.Errorf("查询代币元数据失败: %v", err)
+	}
+
+	for i := range transfers {
+		token, ok := tokens[transfers[i].ContractAddress]
+		if !ok {
+			continue
+		}
+		transfers[i].Symbol = token.Symbol
+		transfers[i].Decimals = token.Decimals
+		transfers[i].FormattedAmount = formatTokenAmount(token, transfers[i].Amount)
+	}
+	return nil
+}
+
+// 按代币小数位数换算金额，小数位数未知时返回空字符串
+func formatTokenAmount(token *model.Token, amount string) string {
+	if token == nil || token.Decimals == nil {
+		return ""
+	}
+	return util.FormatUnits(amount, *token.Decimals)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// 模拟代币合约：name 按早期合约的方式返回 bytes32，其余方法按标准返回
+func fakeToken(t *testing.T, name, symbol string, decimals, totalSupply int64) testutil.ContractFunc {
+	t.Helper()
+
+	stringType, _ := abi.NewType("string", "", nil)
+	encodedSymbol, err := abi.Arguments{{Type: stringType}}.Pack(symbol)
+	if err != nil {
+		t.Fatalf("编码 symbol 失败: %v", err)
+	}
+	var encodedName [32]byte
+	copy(encodedName[:], name)
+
+	return func(data []byte) ([]byte, error) {
+		if len(data) < 4 {
+			return nil, errors.New("缺少方法选择器")
+		}
+		switch common.Bytes2Hex(data[:4]) {
+		case "06fdde03": // name()
+			return encodedName[:], nil
+		case "95d89b41": // symbol()
+			return encodedSymbol, nil
+		case "313ce567": // decimals()
+			return uint256Word(decimals), nil
+		case "18160ddd": // totalSupply()
+			return uint256Word(totalSupply), nil
+		}
+		return nil, errors.New("未实现的方法")
+	}
+}
+
+func TestScanRegistersTokenMetadata(t *testing.T) {
+	chain := setupScanTest(t)
+
+	maker := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	unknown := common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	receiver := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+	chain.SetContract(maker, fakeToken(t, "Maker", "MKR", 18, 1_000_000_000_000_000_000))
+
+	transfer := func(token common.Address, amount int64) testutil.Tx {
+		return testutil.Tx{To: token, Data: []byte{0x01}, Logs: []*types.Log{{
+			Address: token,
+			Topics:  []common.Hash{transferTopic, addressTopic(chain.Sender()), addressTopic(receiver)},
+			Data:    uint256Word(amount),
+		}}}
+	}
+	chain.Mine(transfer(maker, 1_500_000_000_000_000_000), transfer(unknown, 42))
+
+	if _, err := NewBlockScanner().runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	token, err := repository.NewTokenRepository().GetToken(maker.Hex())
+	if err != nil || token == nil {
+		t.Fatalf("代币 %s 未登记: %v", maker.Hex(), err)
+	}
+	if token.Name != "Maker" || token.Symbol != "MKR" || token.Decimals == nil || *token.Decimals != 18 ||
+		token.TotalSupply != "1000000000000000000" {
+		t.Errorf("代币元数据为 %+v，期望 Maker / MKR / 18 / 1000000000000000000", token)
+	}
+
+	// 未实现元数据方法的合约也会登记，字段为空
+	if token, _ := repository.NewTokenRepository().GetToken(unknown.Hex()); token == nil || token.Decimals != nil {
+		t.Errorf("未实现元数据方法的合约登记为 %+v，期望 decimals 为空", token)
+	}
+
+	want := map[string]model.ERC20Transfer{
+		maker.Hex():   {Symbol: "MKR", FormattedAmount: "1.5"},
+		unknown.Hex(): {},
+	}
+	for i, tx := range chain.Block(1).Transactions() {
+		transfers, err := GetTransactionTransfers(tx.Hash().Hex())
+		if err != nil {
+			t.Fatalf("查询交易 %d 的代币转移失败: %v", i, err)
+		}
+		if len(transfers.ERC20Transfers) != 1 {
+			t.Fatalf("交易 %d 有 %d 条ERC20转移记录，期望 1 条", i, len(transfers.ERC20Transfers))
+		}
+		got := transfers.ERC20Transfers[0]
+		w := want[got.ContractAddress]
+		if got.Symbol != w.Symbol || got.FormattedAmount != w.FormattedAmount {
+			t.Errorf("合约 %s 的转移记录符号为 %q、换算金额为 %q，期望 %q、%q",
+				got.ContractAddress, got.Symbol, got.FormattedAmount, w.Symbol, w.FormattedAmount)
+		}
+	}
+}
+
+func TestOversizedTokenMetadataIsTruncated(t *testing.T) {
+	chain := setupScanTest(t)
+
+	spam := common.HexToAddress("0x00000000000000000000000000000000000000c1")
+	receiver := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+	chain.SetContract(spam, fakeToken(t, "Spam", strings.Repeat("空投", 100), 18, 1))
+	chain.Mine(testutil.Tx{To: spam, Data: []byte{0x01}, Logs: []*types.Log{{
+		Address: spam,
+		Topics:  []common.Hash{transferTopic, addressTopic(chain.Sender()), addressTopic(receiver)},
+		Data:    uint256Word(1),
+	}}})
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	token, err := repository.NewTokenRepository().GetToken(spam.Hex())
+	if err != nil || token == nil {
+		t.Fatalf("超长元数据的代币未登记: %v", err)
+	}
+	if n := utf8.RuneCountInString(token.Symbol); n != model.TokenSymbolMaxLen || !strings.HasPrefix(token.Symbol, "空投") {
+		t.Errorf("代币符号为 %q（%d 个字符），期望截断为 %d 个字符", token.Symbol, n, model.TokenSymbolMaxLen)
+	}
+	if _, ok := scanner.knownTokens.Load(spam.Hex()); !ok {
+		t.Error("超长元数据的代币登记后应缓存，避免重复查询")
+	}
+}
@@ -3,8 +3,10 @@ package service
 import (
 	"blockchain-asset-api/internal/model"
 	"blockchain-asset-api/internal/repository"
-	"gorm.io/gorm"
-	_ "gorm.io/gorm"
+	"blockchain-asset-api/internal/util"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // GetTransactions 获取交易列表
@@ -16,9 +18,9 @@ func GetTransactions(page, size int, txType, address string, blockNumber *int64)
 	// 构建查询条件
 	query := db.Model(&model.Transaction{})
 
-	// 交易类型筛选
+	// 交易类型或标签筛选
 	if txType != "" {
-		query = query.Where("tx_type = ?", txType)
+		query = query.Where("tx_type = ? OR FIND_IN_SET(?, labels) > 0", txType, txType)
 	}
 
 	// 地址筛选（发送方或接收方）
@@ -42,42 +44,89 @@ func GetTransactions(page, size int, txType, address string, blockNumber *int64)
 		return nil, 0, err
 	}
 
-	// 收集所有ERC20转账交易的哈希
-	erc20TxHashes := make([]string, 0)
+	// 批量查询本页交易的ERC20转移记录，一笔交易可能有多条（例如DEX兑换）
+	txHashes := make([]string, 0, len(transactions))
+	for i := range transactions {
+		txHashes = append(txHashes, transactions[i].TxHash)
+	}
+	erc20Transfers, err := repository.NewBlockRepository().ListERC20TransfersByTx(txHashes)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := fillTokenInfo(erc20Transfers); err != nil {
+		return nil, 0, err
+	}
 
+	// 将ERC20转移记录关联到对应交易
+	erc20TransferMap := make(map[string][]model.ERC20Transfer)
+	for _, transfer := range erc20Transfers {
+		erc20TransferMap[transfer.TxHash] = append(erc20TransferMap[transfer.TxHash], transfer)
+	}
 	for i := range transactions {
-		if transactions[i].TxType == "erc20_transfer" {
-			erc20TxHashes = append(erc20TxHashes, transactions[i].TxHash)
+		transfers := erc20TransferMap[transactions[i].TxHash]
+		transactions[i].ERC20Transfers = transfers
+		// erc20_transfer 类型的交易保留第一条转移金额，兼容原有字段
+		if transactions[i].TxType == TxTypeERC20Transfer {
+			if len(transfers) > 0 && transfers[0].Amount != "" {
+				transactions[i].ERC20Amount = transfers[0].Amount
+				transactions[i].ERC20FormattedAmount = transfers[0].FormattedAmount
+				transactions[i].ERC20Symbol = transfers[0].Symbol
+			} else {
+				transactions[i].ERC20Amount = "0.00000" // 空值时设为0
+			}
 		}
 	}
 
-	// 批量查询ERC20转账记录
-	if len(erc20TxHashes) > 0 {
-		var erc20Transfers []model.ERC20Transfer
-		if err := db.Model(&model.ERC20Transfer{}).
-			Where("tx_hash IN ?", erc20TxHashes).
-			Find(&erc20Transfers).Error; err != nil && err != gorm.ErrRecordNotFound {
-			return nil, 0, err
+	// 根据链头计算确认数
+	if len(transactions) > 0 {
+		head, err := util.GetHeadNumber(context.Background(), "latest")
+		if err != nil {
+			util.Log.Warnf("获取链头失败，无法计算确认数: %v", err)
+		} else {
+			for i := range transactions {
+				transactions[i].Confirmations = confirmations(head, transactions[i].BlockNumber)
+			}
 		}
+	}
 
-		// 将ERC20转账金额关联到对应交易
-		erc20TransferMap := make(map[string]string)
-		for _, transfer := range erc20Transfers {
-			erc20TransferMap[transfer.TxHash] = transfer.Amount
-		}
+	return transactions, total, nil
+}
 
-		// 循环transactions为erc20_transfer类型的交易赋值Amount
-		for i := range transactions {
-			if transactions[i].TxType == "erc20_transfer" {
-				if amount, exists := erc20TransferMap[transactions[i].TxHash]; exists && amount != "" {
-					transactions[i].ERC20Amount = amount
-				} else {
-					transactions[i].ERC20Amount = "0.00000" // 空值时设为0
-				}
-			}
-		}
+// 计算区块在当前链头下的确认数，区块本身算 1 个确认
+func confirmations(head, blockNumber int64) int64 {
+	if blockNumber > head {
+		return 0
+	}
+	return head - blockNumber + 1
+}
 
+// TransactionTransfers 交易内的全部代币转移
+type TransactionTransfers struct {
+	TxHash         string                `json:"tx_hash"`
+	ERC20Transfers []model.ERC20Transfer `json:"erc20_transfers"`
+	NFTTransfers   []model.NFTTransfer   `json:"nft_transfers"`
+}
+
+// GetTransactionTransfers 获取已索引交易的全部ERC20和NFT转移记录
+func GetTransactionTransfers(txHash string) (*TransactionTransfers, error) {
+	txHash = common.HexToHash(txHash).Hex()
+
+	blockRepo := repository.NewBlockRepository()
+	erc20Transfers, err := blockRepo.ListERC20TransfersByTx([]string{txHash})
+	if err != nil {
+		return nil, fmt.Errorf("查询ERC20转移记录失败: %v", err)
+	}
+	if err := fillTokenInfo(erc20Transfers); err != nil {
+		return nil, err
+	}
+	nftTransfers, err := blockRepo.ListNFTTransfersByTx(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("查询NFT转移记录失败: %v", err)
 	}
 
-	return transactions, total, nil
+	return &TransactionTransfers{
+		TxHash:         txHash,
+		ERC20Transfers: erc20Transfers,
+		NFTTransfers:   nftTransfers,
+	}, nil
 }
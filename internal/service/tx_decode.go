@@ -0,0 +1,125 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 解码结果的来源
+const (
+	DecodeSourceABI     = "abi"     // 合约登记的 ABI
+	DecodeSourceBuiltin = "builtin" // 内置签名库
+)
+
+// 交易调用数据的解码结果
+type DecodedInput struct {
+	Method    string                 `json:"method"`
+	Signature string                 `json:"signature"`
+	Selector  string                 `json:"selector"`
+	Args      map[string]interface{} `json:"args"`
+	Source    string                 `json:"source"` // abi / builtin
+}
+
+// 交易回执中的事件日志，能解码时带有事件名和参数
+type DecodedLog struct {
+	LogIndex  uint                   `json:"log_index"`
+	Address   string                 `json:"address"`
+	Topics    []string               `json:"topics"`
+	Data      string                 `json:"data"`
+	Event     string                 `json:"event,omitempty"`
+	Signature string                 `json:"signature,omitempty"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+	Source    string                 `json:"source,omitempty"` // abi / builtin
+}
+
+// 解码交易调用数据：优先使用目标合约登记的 ABI，没有匹配的函数时按选择器查内置签名库。
+// 合约创建、普通转账和无法识别的调用返回 nil
+func decodeTransactionInput(to *common.Address, input []byte) *DecodedInput {
+	if to == nil || len(input) < 4 {
+		return nil
+	}
+
+	var candidates []*abi.Method
+	source := DecodeSourceABI
+	parsed, err := contractABIs.get(*to)
+	if err != nil {
+		util.Log.Warnf("解码交易调用数据失败: %v", err)
+	}
+	if parsed != nil {
+		if method, err := parsed.MethodById(input); err == nil {
+			candidates = append(candidates, method)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates, source = util.BuiltinMethods(input), DecodeSourceBuiltin
+	}
+
+	for _, method := range candidates {
+		decoded, err := util.DecodeMethodInput(method, input)
+		if err != nil {
+			continue
+		}
+		return &DecodedInput{
+			Method:    decoded.Name,
+			Signature: decoded.Signature,
+			Selector:  hexutil.Encode(input[:4]),
+			Args:      decoded.Args,
+			Source:    source,
+		}
+	}
+	return nil
+}
+
+// 解码交易回执中的全部事件日志，无法解码的日志只返回原始 topic 和数据
+func decodeReceiptLogs(logs []*types.Log) []DecodedLog {
+	result := make([]DecodedLog, 0, len(logs))
+	for _, l := range logs {
+		item := DecodedLog{
+			LogIndex: l.Index,
+			Address:  l.Address.Hex(),
+			Topics:   make([]string, len(l.Topics)),
+			Data:     hexutil.Encode(l.Data),
+		}
+		for i, topic := range l.Topics {
+			item.Topics[i] = topic.Hex()
+		}
+		if decoded, source := decodeEvent(l.Address, l.Topics, l.Data); decoded != nil {
+			item.Event = decoded.Name
+			item.Signature = decoded.Signature
+			item.Args = decoded.Args
+			item.Source = source
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// 解码单条事件日志：优先使用合约登记的 ABI，不匹配时按 topic0 查内置签名库，
+// 内置库中 indexed 参数不同的同名事件依次尝试。无法解码时返回 nil
+func decodeEvent(address common.Address, topics []common.Hash, data []byte) (*util.DecodedEvent, string) {
+	if len(topics) == 0 {
+		return nil, ""
+	}
+
+	parsed, err := contractABIs.get(address)
+	if err != nil {
+		util.Log.Warnf("解码事件日志失败: %v", err)
+	}
+	if parsed != nil {
+		if event, err := parsed.EventByID(topics[0]); err == nil {
+			if decoded, err := util.DecodeEventLog(event, topics, data); err == nil {
+				return decoded, DecodeSourceABI
+			}
+		}
+	}
+
+	for _, event := range util.BuiltinEvents(topics[0]) {
+		if decoded, err := util.DecodeEventLog(event, topics, data); err == nil {
+			return decoded, DecodeSourceBuiltin
+		}
+	}
+	return nil, ""
+}
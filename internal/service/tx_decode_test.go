@@ -0,0 +1,95 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/testutil"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetTransactionDetailDecodesInputAndLogs(t *testing.T) {
+	chain := setupScanTest(t)
+
+	token := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+	nft := common.HexToAddress("0x0000000000000000000000000000000000000e02")
+	vault := common.HexToAddress("0x0000000000000000000000000000000000000f01")
+	bob := common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+	sender := chain.Sender()
+
+	if _, err := RegisterContractABI(vault.Hex(), "Vault", []byte(vaultABI)); err != nil {
+		t.Fatalf("登记 ABI 失败: %v", err)
+	}
+
+	// transfer(bob, 5)：代币合约未登记 ABI，按内置签名库解码
+	parsed, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}]}]`))
+	if err != nil {
+		t.Fatalf("解析 ABI 失败: %v", err)
+	}
+	input, err := parsed.Pack("transfer", bob, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("编码调用数据失败: %v", err)
+	}
+
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	block := chain.Mine(testutil.Tx{To: token, Data: input, Logs: []*types.Log{
+		// ERC20 Transfer：金额在 data 中
+		{Address: token, Topics: []common.Hash{transferTopic, common.BytesToHash(sender.Bytes()), common.BytesToHash(bob.Bytes())}, Data: uint256Word(5)},
+		// ERC721 Transfer：topic0 相同，tokenId 为 indexed
+		{Address: nft, Topics: []common.Hash{transferTopic, {}, common.BytesToHash(bob.Bytes()), common.BigToHash(big.NewInt(7))}},
+		// 登记了 ABI 的合约事件
+		depositLog(t, vault, bob, 1, "gold", 100, "memo"),
+		// 无法识别的事件
+		{Address: vault, Topics: []common.Hash{common.HexToHash("0xdead")}},
+	}})
+
+	detail, err := GetTransactionDetail(block.Transactions()[0].Hash().Hex())
+	if err != nil {
+		t.Fatalf("查询交易详情失败: %v", err)
+	}
+
+	want := &DecodedInput{
+		Method:    "transfer",
+		Signature: "transfer(address,uint256)",
+		Selector:  "0xa9059cbb",
+		Args:      map[string]interface{}{"to": bob.Hex(), "value": "5"},
+		Source:    DecodeSourceBuiltin,
+	}
+	if !reflect.DeepEqual(detail.DecodedInput, want) {
+		t.Errorf("调用数据解码为 %+v，期望 %+v", detail.DecodedInput, want)
+	}
+
+	if len(detail.Logs) != 4 {
+		t.Fatalf("返回了 %d 条日志，期望 4 条", len(detail.Logs))
+	}
+	cases := []struct {
+		event  string
+		source string
+		args   map[string]interface{}
+	}{
+		{"Transfer", DecodeSourceBuiltin, map[string]interface{}{"from": sender.Hex(), "to": bob.Hex(), "value": "5"}},
+		{"Transfer", DecodeSourceBuiltin, map[string]interface{}{"from": common.Address{}.Hex(), "to": bob.Hex(), "tokenId": "7"}},
+		{"Deposit", DecodeSourceABI, map[string]interface{}{"user": bob.Hex(), "id": "1", "tag": crypto.Keccak256Hash([]byte("gold")).Hex(), "amount": "100", "memo": "memo"}},
+		{"", "", nil},
+	}
+	for i, tc := range cases {
+		l := detail.Logs[i]
+		if l.LogIndex != uint(i) || l.Event != tc.event || l.Source != tc.source || !reflect.DeepEqual(l.Args, tc.args) {
+			t.Errorf("日志 %d 解码为 %+v，期望事件 %q 来源 %q 参数 %v", i, l, tc.event, tc.source, tc.args)
+		}
+	}
+
+	// 普通转账没有调用数据
+	plain := chain.Mine(testutil.Tx{To: bob, Value: big.NewInt(1)})
+	detail, err = GetTransactionDetail(plain.Transactions()[0].Hash().Hex())
+	if err != nil {
+		t.Fatalf("查询交易详情失败: %v", err)
+	}
+	if detail.DecodedInput != nil || detail.Input != "0x" || len(detail.Logs) != 0 {
+		t.Errorf("普通转账的解码结果为 input=%s decoded=%+v logs=%d", detail.Input, detail.DecodedInput, len(detail.Logs))
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+// 计算交易的类型、nonce 和费用字段，baseFee 为交易所在区块的基础费用（伦敦升级前为空）
+func newTxFees(tx *types.Transaction, receipt *types.Receipt, baseFee *big.Int) model.TxFees {
+	fees := model.TxFees{
+		EnvelopeType: tx.Type(),
+		Nonce:        tx.Nonce(),
+	}
+
+	// legacy 交易只有 gasPrice，EIP-1559 之后的交易类型改为费用上限和小费上限
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		fees.GasPrice = weiString(tx.GasPrice())
+	default:
+		fees.MaxFeePerGas = weiString(tx.GasFeeCap())
+		fees.MaxPriorityFeePerGas = weiString(tx.GasTipCap())
+	}
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		if accessList == nil {
+			accessList = types.AccessList{}
+		}
+		fees.AccessList = jsonText(accessList)
+	}
+
+	effective := effectiveGasPrice(tx, receipt, baseFee)
+	fees.EffectiveGasPrice = weiString(effective)
+
+	gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
+	fee := new(big.Int).Mul(effective, gasUsed)
+	burnt := new(big.Int)
+	if baseFee != nil {
+		burnt.Mul(baseFee, gasUsed)
+	}
+
+	if tx.Type() == types.BlobTxType {
+		blobGasUsed := receipt.BlobGasUsed
+		fees.MaxFeePerBlobGas = weiString(tx.BlobGasFeeCap())
+		fees.BlobGasUsed = &blobGasUsed
+		fees.BlobHashes = jsonText(tx.BlobHashes())
+		if receipt.BlobGasPrice != nil {
+			fees.BlobGasPrice = weiString(receipt.BlobGasPrice)
+			// blob 费用全部销毁
+			blobFee := new(big.Int).Mul(receipt.BlobGasPrice, new(big.Int).SetUint64(blobGasUsed))
+			fee.Add(fee, blobFee)
+			burnt.Add(burnt, blobFee)
+		}
+	}
+
+	fees.Fee = weiString(fee)
+	fees.BurntFees = weiString(burnt)
+	return fees
+}
+
+// 交易实际成交的 gas 单价，节点未返回 effectiveGasPrice 时按基础费用加实际小费计算
+func effectiveGasPrice(tx *types.Transaction, receipt *types.Receipt, baseFee *big.Int) *big.Int {
+	if receipt.EffectiveGasPrice != nil {
+		return receipt.EffectiveGasPrice
+	}
+	if baseFee == nil {
+		return tx.GasPrice()
+	}
+	tip, _ := tx.EffectiveGasTip(baseFee)
+	return new(big.Int).Add(baseFee, tip)
+}
+
+// 区块内交易支付给出块者的小费总和：成交单价超出基础费用的部分乘以 gas 用量，伦敦升级前为全部手续费
+func blockPriorityFees(block *types.Block, receipts []*types.Receipt) *string {
+	total := new(big.Int)
+	for i, tx := range block.Transactions() {
+		if i >= len(receipts) {
+			break
+		}
+		tip := new(big.Int).Set(effectiveGasPrice(tx, receipts[i], block.BaseFee()))
+		if block.BaseFee() != nil {
+			tip.Sub(tip, block.BaseFee())
+		}
+		total.Add(total, tip.Mul(tip, new(big.Int).SetUint64(receipts[i].GasUsed)))
+	}
+	return weiString(total)
+}
+
+// 区块内销毁的费用：基础费用乘以区块 gas 用量，加上全部 blob 费用。伦敦升级前的区块返回空
+func blockBurntFees(block *types.Block, receipts []*types.Receipt) *string {
+	if block.BaseFee() == nil {
+		return nil
+	}
+	burnt := new(big.Int).Mul(block.BaseFee(), new(big.Int).SetUint64(block.GasUsed()))
+	for _, receipt := range receipts {
+		if receipt.BlobGasPrice != nil && receipt.BlobGasUsed > 0 {
+			burnt.Add(burnt, new(big.Int).Mul(receipt.BlobGasPrice, new(big.Int).SetUint64(receipt.BlobGasUsed)))
+		}
+	}
+	return weiString(burnt)
+}
+
+// wei 金额转为十进制字符串，nil 保持为空
+func weiString(v *big.Int) *string {
+	if v == nil {
+		return nil
+	}
+	s := v.String()
+	return &s
+}
+
+// 序列化为 JSON 文本列，失败时保存为空
+func jsonText(v interface{}) model.JSONText {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
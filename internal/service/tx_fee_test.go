@@ -0,0 +1,96 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"testing"
+)
+
+// 指针字段的值，空指针返回 "<nil>"
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
+
+func TestScanRecordsTransactionFees(t *testing.T) {
+	chain := setupScanTest(t)
+
+	to := common.HexToAddress("0x0000000000000000000000000000000000000d01")
+	block := chain.Mine(
+		testutil.Tx{To: to},
+		testutil.Tx{To: to, Legacy: true},
+		testutil.Tx{To: to, Blobs: 2},
+	)
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 1); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	// 测试链基础费用 1 gwei，实际成交单价 2 gwei，每笔交易消耗 21000 gas；
+	// 2 个 blob 消耗 262144 blob gas，单价 10 wei，blob 费用 2621440 wei 全部销毁
+	cases := []struct {
+		envelope     uint8
+		gasPrice     string
+		maxFee       string
+		maxTip       string
+		fee          string
+		burnt        string
+		blobGasPrice string
+		accessList   string
+	}{
+		{model.TxEnvelopeDynamicFee, "<nil>", "3000000000", "1000000000", "42000000000000", "21000000000000", "<nil>", "[]"},
+		{model.TxEnvelopeLegacy, "2000000000", "<nil>", "<nil>", "42000000000000", "21000000000000", "<nil>", "null"},
+		{model.TxEnvelopeBlob, "<nil>", "3000000000", "1000000000", "42000002621440", "21000002621440", "10", "[]"},
+	}
+
+	var txs []model.Transaction
+	if err := repository.DB.Order("id").Find(&txs).Error; err != nil {
+		t.Fatalf("查询交易失败: %v", err)
+	}
+	if len(txs) != len(cases) {
+		t.Fatalf("保存了 %d 笔交易，期望 %d 笔", len(txs), len(cases))
+	}
+	for i, tc := range cases {
+		tx := txs[i]
+		if tx.EnvelopeType != tc.envelope || tx.Nonce != block.Transactions()[i].Nonce() {
+			t.Errorf("交易 %d 类型为 %d nonce 为 %d，期望 %d 和 %d", i, tx.EnvelopeType, tx.Nonce, tc.envelope, block.Transactions()[i].Nonce())
+		}
+		got := []string{deref(tx.GasPrice), deref(tx.MaxFeePerGas), deref(tx.MaxPriorityFeePerGas), deref(tx.EffectiveGasPrice), deref(tx.Fee), deref(tx.BurntFees), deref(tx.BlobGasPrice)}
+		want := []string{tc.gasPrice, tc.maxFee, tc.maxTip, "2000000000", tc.fee, tc.burnt, tc.blobGasPrice}
+		for j := range got {
+			if got[j] != want[j] {
+				t.Errorf("交易 %d 费用字段为 %v，期望 %v", i, got, want)
+				break
+			}
+		}
+		if accessList, _ := tx.AccessList.MarshalJSON(); string(accessList) != tc.accessList {
+			t.Errorf("交易 %d 访问列表为 %s，期望 %s", i, accessList, tc.accessList)
+		}
+	}
+
+	blob := txs[2]
+	if blob.BlobGasUsed == nil || *blob.BlobGasUsed != 262144 || deref(blob.MaxFeePerBlobGas) != "100" {
+		t.Errorf("blob 交易的 blob gas 字段不正确: used=%v maxFee=%s", blob.BlobGasUsed, deref(blob.MaxFeePerBlobGas))
+	}
+	if want := `["` + block.Transactions()[2].BlobHashes()[0].Hex() + `","` + block.Transactions()[2].BlobHashes()[1].Hex() + `"]`; string(blob.BlobHashes) != want {
+		t.Errorf("blob 哈希为 %s，期望 %s", blob.BlobHashes, want)
+	}
+
+	var stored model.Block
+	if err := repository.DB.Where("block_number = ?", 1).First(&stored).Error; err != nil {
+		t.Fatalf("查询区块失败: %v", err)
+	}
+	if deref(stored.BaseFeePerGas) != "1000000000" {
+		t.Errorf("区块基础费用为 %s，期望 1000000000", deref(stored.BaseFeePerGas))
+	}
+	// 3 笔交易共 63000 gas 的基础费用加 blob 费用
+	if want := "63000002621440"; deref(stored.BurntFees) != want {
+		t.Errorf("区块销毁费用为 %s，期望 %s", deref(stored.BurntFees), want)
+	}
+}
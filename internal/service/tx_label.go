@@ -0,0 +1,73 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 交易标签，与交易类型一起用于筛选交易，一笔交易可以有多个标签
+const (
+	TxLabelWETHWrap   = "weth_wrap"   // 向 WETH 存入 ETH（Deposit）
+	TxLabelWETHUnwrap = "weth_unwrap" // 从 WETH 取回 ETH（Withdrawal）
+	TxLabelTokenMint  = "token_mint"  // ERC20 从零地址转出
+	TxLabelTokenBurn  = "token_burn"  // ERC20 转入零地址
+	TxLabelApproval   = "approval"    // ERC20 / ERC-721 Approval 或 ApprovalForAll
+	TxLabelNFTMint    = "nft_mint"    // ERC-721 / ERC-1155 从零地址转出
+)
+
+var (
+	// WETH Deposit(address indexed dst, uint256 wad)
+	wethDepositTopic = crypto.Keccak256Hash([]byte("Deposit(address,uint256)"))
+	// WETH Withdrawal(address indexed src, uint256 wad)
+	wethWithdrawalTopic = crypto.Keccak256Hash([]byte("Withdrawal(address,uint256)"))
+	// ERC20 / ERC-721 Approval(address owner, address spender, uint256 value)，ERC-721 的 tokenId 是第 4 个 topic
+	approvalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	// ERC-721 / ERC-1155 ApprovalForAll(address owner, address operator, bool approved)
+	approvalForAllTopic = crypto.Keccak256Hash([]byte("ApprovalForAll(address,address,bool)"))
+)
+
+// 根据交易回执中的事件日志识别交易标签，按固定顺序排列且不重复
+func (s *BlockScanner) transactionLabels(receipt *types.Receipt) model.Labels {
+	found := make(map[string]bool)
+	for _, log := range receipt.Logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+		switch topic := log.Topics[0]; {
+		case s.weth != (common.Address{}) && log.Address == s.weth && len(log.Topics) == 2:
+			if topic == wethDepositTopic {
+				found[TxLabelWETHWrap] = true
+			} else if topic == wethWithdrawalTopic {
+				found[TxLabelWETHUnwrap] = true
+			}
+		case isERC20Transfer(log):
+			if log.Topics[1] == (common.Hash{}) {
+				found[TxLabelTokenMint] = true
+			}
+			if log.Topics[2] == (common.Hash{}) {
+				found[TxLabelTokenBurn] = true
+			}
+		case isNFTTransfer(log):
+			// ERC-721 Transfer 的 from 是第 2 个 topic，ERC-1155 的第 2 个 topic 是 operator
+			from := log.Topics[1]
+			if topic != transferTopic {
+				from = log.Topics[2]
+			}
+			if from == (common.Hash{}) {
+				found[TxLabelNFTMint] = true
+			}
+		case topic == approvalTopic && len(log.Topics) >= 3, topic == approvalForAllTopic && len(log.Topics) == 3:
+			found[TxLabelApproval] = true
+		}
+	}
+
+	labels := model.Labels{}
+	for _, label := range []string{TxLabelWETHWrap, TxLabelWETHUnwrap, TxLabelTokenMint, TxLabelTokenBurn, TxLabelApproval, TxLabelNFTMint} {
+		if found[label] {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
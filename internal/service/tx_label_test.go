@@ -0,0 +1,90 @@
+package service
+
+import (
+	"blockchain-asset-api/config"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"reflect"
+	"testing"
+)
+
+func TestScanLabelsTransactions(t *testing.T) {
+	chain := setupScanTest(t)
+
+	weth := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+	fakeWETH := common.HexToAddress("0x0000000000000000000000000000000000000e02")
+	token := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	nft := common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	router := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	alice := common.HexToAddress("0x0000000000000000000000000000000000000a11")
+	zero := common.Address{}
+
+	config.Cfg.Eth.WETH = weth.Hex()
+	t.Cleanup(func() { config.Cfg.Eth.WETH = "" })
+
+	// 1：通过路由把 ETH 包装为 WETH，另一个合约的同名事件不算
+	chain.Mine(testutil.Tx{To: router, Data: []byte{0x01}, Logs: []*types.Log{
+		{Address: weth, Topics: []common.Hash{wethDepositTopic, addressTopic(router)}, Data: uint256Word(100)},
+		{Address: fakeWETH, Topics: []common.Hash{wethWithdrawalTopic, addressTopic(router)}, Data: uint256Word(100)},
+	}})
+	// 2：直接调用代币合约铸造，同时授权
+	chain.Mine(testutil.Tx{To: token, Data: []byte{0x02}, Logs: []*types.Log{
+		{Address: token, Topics: []common.Hash{transferTopic, addressTopic(zero), addressTopic(alice)}, Data: uint256Word(5)},
+		{Address: token, Topics: []common.Hash{approvalTopic, addressTopic(alice), addressTopic(router)}, Data: uint256Word(5)},
+	}})
+	// 3：销毁代币并铸造 ERC-1155 NFT，ERC-721 的授权全部
+	chain.Mine(testutil.Tx{To: router, Data: []byte{0x03}, Logs: []*types.Log{
+		{Address: token, Topics: []common.Hash{transferTopic, addressTopic(alice), addressTopic(zero)}, Data: uint256Word(1)},
+		{Address: nft, Topics: []common.Hash{transferSingleTopic, addressTopic(router), addressTopic(zero), addressTopic(alice)},
+			Data: append(uint256Word(7), uint256Word(1)...)},
+		{Address: nft, Topics: []common.Hash{approvalForAllTopic, addressTopic(alice), addressTopic(router)}, Data: uint256Word(1)},
+	}})
+	// 4：普通 ETH 转账
+	chain.Mine(testutil.Tx{To: alice})
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 4); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	txs, total, err := GetTransactions(1, 10, "", "", nil)
+	if err != nil || total != 4 {
+		t.Fatalf("交易 %d 笔（%v），期望 4 笔", total, err)
+	}
+	want := map[int64][]string{
+		1: {TxLabelWETHWrap},
+		2: {TxLabelTokenMint, TxLabelApproval},
+		3: {TxLabelTokenBurn, TxLabelApproval, TxLabelNFTMint},
+		4: {},
+	}
+	for _, tx := range txs {
+		if !reflect.DeepEqual([]string(tx.Labels), want[tx.BlockNumber]) {
+			t.Errorf("区块 %d 的交易标签为 %v，期望 %v", tx.BlockNumber, tx.Labels, want[tx.BlockNumber])
+		}
+	}
+
+	// 按标签筛选，交易类型筛选不受影响
+	filters := map[string][]int64{
+		TxLabelApproval:     {3, 2},
+		TxLabelWETHUnwrap:   nil,
+		TxLabelNFTMint:      {3},
+		TxTypeERC20Transfer: {2},
+		TxTypeContractCall:  {3, 1},
+		TxTypeEthTransfer:   {4},
+	}
+	for filter, blocks := range filters {
+		txs, total, err := GetTransactions(1, 10, filter, "", nil)
+		if err != nil {
+			t.Fatalf("按 %s 筛选失败: %v", filter, err)
+		}
+		var got []int64
+		for _, tx := range txs {
+			got = append(got, tx.BlockNumber)
+		}
+		if total != int64(len(blocks)) || !reflect.DeepEqual(got, blocks) {
+			t.Errorf("按 %s 筛选得到区块 %v（共 %d 笔），期望 %v", filter, got, total, blocks)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"math/big"
+	"time"
+)
+
+// 记录区块中的信标链提款
+func (s *BlockScanner) processWithdrawals(data *repository.BlockData, withdrawals types.Withdrawals) {
+	data.Withdrawals = append(data.Withdrawals, newWithdrawals(data.Block.BlockNumber, withdrawals)...)
+}
+
+// 转换区块中的信标链提款，提款金额以 Gwei 为单位，保存时换算为 ETH
+func newWithdrawals(blockNumber int64, withdrawals types.Withdrawals) []*model.Withdrawal {
+	result := make([]*model.Withdrawal, 0, len(withdrawals))
+	for _, w := range withdrawals {
+		amount := new(big.Int).Mul(new(big.Int).SetUint64(w.Amount), big.NewInt(params.GWei))
+		result = append(result, &model.Withdrawal{
+			WithdrawalIndex: w.Index,
+			ValidatorIndex:  w.Validator,
+			BlockNumber:     blockNumber,
+			Address:         w.Address.Hex(),
+			Amount:          util.WeiToEth(amount),
+			CreatedAt:       time.Now(),
+		})
+	}
+	return result
+}
+
+// GetAddressWithdrawals 分页查询地址收到的信标链提款
+func GetAddressWithdrawals(address string, page, size int) ([]model.Withdrawal, int64, error) {
+	return repository.NewBlockRepository().ListWithdrawalsByAddress(common.HexToAddress(address).Hex(), nil, page, size)
+}
+
+// GetTransactionWithdrawals 按地址筛选交易列表时一并返回的信标链提款。提款不是交易，没有交易哈希和交易类型，
+// 沿用交易列表的区块号筛选条件和每页数量，page 为提款自己的页码；未按地址筛选或按交易类型筛选时不返回提款
+func GetTransactionWithdrawals(page, size int, txType, address string, blockNumber *int64) ([]model.Withdrawal, int64, error) {
+	if address == "" || txType != "" || !common.IsHexAddress(address) {
+		return nil, 0, nil
+	}
+	return repository.NewBlockRepository().ListWithdrawalsByAddress(common.HexToAddress(address).Hex(), blockNumber, page, size)
+}
+
+// GetBlockWithdrawals 查询已索引区块中的信标链提款
+func GetBlockWithdrawals(blockNumber int64) ([]model.Withdrawal, error) {
+	return repository.NewBlockRepository().ListWithdrawalsByBlock(blockNumber)
+}
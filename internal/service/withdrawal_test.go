@@ -0,0 +1,100 @@
+package service
+
+import (
+	"blockchain-asset-api/internal/model"
+	"blockchain-asset-api/internal/repository"
+	"blockchain-asset-api/internal/testutil"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"strings"
+	"testing"
+)
+
+// 去掉数据库 decimal 列返回值末尾的 0
+func trimDecimal(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+func TestScanIndexesWithdrawals(t *testing.T) {
+	chain := setupScanTest(t)
+
+	validator := common.HexToAddress("0x0000000000000000000000000000000000000e01")
+	other := common.HexToAddress("0x0000000000000000000000000000000000000e02")
+	chain.MineWithdrawals([]*types.Withdrawal{
+		{Index: 100, Validator: 7, Address: validator, Amount: 32_000_000_000},
+		{Index: 101, Validator: 8, Address: other, Amount: 1_500_000},
+	}, testutil.Tx{To: other}, testutil.Tx{To: other, Blobs: 1})
+	block := chain.MineWithdrawals([]*types.Withdrawal{
+		{Index: 102, Validator: 7, Address: validator, Amount: 2_000_000},
+	})
+
+	scanner := NewBlockScanner()
+	if _, err := scanner.runPipeline(context.Background(), 1, 2); err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+
+	if n := countTable(t, &model.Withdrawal{}); n != 3 {
+		t.Errorf("记录了 %d 笔提款，期望 3 笔", n)
+	}
+
+	// 提款金额以 Gwei 为单位，保存为 ETH
+	withdrawals, total, err := GetAddressWithdrawals(validator.Hex(), 1, 10)
+	if err != nil {
+		t.Fatalf("查询地址提款失败: %v", err)
+	}
+	if total != 2 || len(withdrawals) != 2 {
+		t.Fatalf("地址有 %d 笔提款（本页 %d 笔），期望 2 笔", total, len(withdrawals))
+	}
+	if w := withdrawals[0]; w.WithdrawalIndex != 102 || w.BlockNumber != 2 || trimDecimal(w.Amount) != "0.002" {
+		t.Errorf("最新提款为 %+v，期望序号 102、区块 2、金额 0.002", w)
+	}
+	if w := withdrawals[1]; w.WithdrawalIndex != 100 || w.ValidatorIndex != 7 || trimDecimal(w.Amount) != "32" {
+		t.Errorf("第一笔提款为 %+v，期望序号 100、验证者 7、金额 32", w)
+	}
+
+	// 按地址筛选交易列表时一并返回提款，按区块号和交易类型筛选
+	withdrawals, total, err = GetTransactionWithdrawals(1, 10, "", validator.Hex(), nil)
+	if err != nil || total != 2 || len(withdrawals) != 2 {
+		t.Errorf("交易列表中地址有 %d 笔提款（%v），期望 2 笔", total, err)
+	}
+	// 提款按自己的页码分页，超出交易列表页数的提款仍可取到
+	withdrawals, total, err = GetTransactionWithdrawals(2, 1, "", validator.Hex(), nil)
+	if err != nil || total != 2 || len(withdrawals) != 1 || withdrawals[0].WithdrawalIndex != 100 {
+		t.Errorf("交易列表中地址提款第 2 页为 %+v（%v），期望序号 100", withdrawals, err)
+	}
+	blockNumber := int64(1)
+	withdrawals, total, err = GetTransactionWithdrawals(1, 10, "", validator.Hex(), &blockNumber)
+	if err != nil || total != 1 || withdrawals[0].WithdrawalIndex != 100 {
+		t.Errorf("交易列表中区块 1 的地址提款为 %+v（%v），期望序号 100", withdrawals, err)
+	}
+	if _, total, _ := GetTransactionWithdrawals(1, 10, "eth_transfer", validator.Hex(), nil); total != 0 {
+		t.Errorf("按交易类型筛选时返回了 %d 笔提款，期望不返回", total)
+	}
+
+	var stored model.Block
+	if err := repository.DB.Where("block_number = ?", 1).First(&stored).Error; err != nil {
+		t.Fatalf("查询区块失败: %v", err)
+	}
+	if stored.WithdrawalsCount != 2 || stored.BlobGasUsed == nil || *stored.BlobGasUsed != 131072 {
+		t.Errorf("区块 1 提款数为 %d，blob gas 用量为 %v，期望 2 和 131072", stored.WithdrawalsCount, stored.BlobGasUsed)
+	}
+	// 每笔交易成交单价 2 gwei，超出基础费用 1 gwei 的部分归出块者
+	if want := "42000000000000"; deref(stored.PriorityFees) != want {
+		t.Errorf("区块 1 小费总和为 %s，期望 %s", deref(stored.PriorityFees), want)
+	}
+	if want := chain.Block(1); stored.StateRoot != want.Root().Hex() || stored.Size != want.Size() {
+		t.Errorf("区块 1 状态根为 %s 大小为 %d，期望 %s 和 %d", stored.StateRoot, stored.Size, want.Root().Hex(), want.Size())
+	}
+
+	// 链重组回滚时删除孤块中的提款
+	if err := repository.NewBlockRepository().RollbackToBlock(1); err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	if n := countTable(t, &model.Withdrawal{}); n != 2 {
+		t.Errorf("回滚区块 %d 后剩余 %d 笔提款，期望 2 笔", block.NumberU64(), n)
+	}
+}
@@ -0,0 +1,621 @@
+package testutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 测试链 ID
+var ChainID = big.NewInt(1337)
+
+// 测试交易：由链的测试账户签名发送，Logs 为回执中的事件日志（地址、主题和数据即可，其余字段由链填充）
+type Tx struct {
+	To    common.Address
+	Data  []byte
+	Value *big.Int
+	Logs  []*types.Log
+	// 交易失败
+	Failed bool
+	// 合约创建交易，忽略 To，回执中的合约地址由发送方和 nonce 计算
+	Deploy bool
+	// 顶层调用中的内部调用，用于 debug_traceBlockByNumber 和 trace_block
+	Calls []Call
+	// 发送 legacy 交易（gasPrice 2 gwei），默认为 EIP-1559 交易
+	Legacy bool
+	// 大于 0 时发送携带该数量 blob 的 EIP-4844 交易，回执中 blob gas 单价为 BlobGasPrice
+	Blobs int
+}
+
+// 测试链 blob 交易回执中的 blob gas 单价
+var BlobGasPrice = big.NewInt(10)
+
+// 测试交易的内部调用
+type Call struct {
+	Type  string // CALL / DELEGATECALL / STATICCALL / CREATE / CREATE2 / SELFDESTRUCT
+	From  common.Address
+	To    common.Address // CREATE 为新合约地址，SELFDESTRUCT 为接收余额的地址
+	Value *big.Int
+	Error string // 非空表示调用失败
+	Calls []Call
+}
+
+// 进程内的以太坊 JSON-RPC 节点，只实现扫描器用到的方法，可回退区块模拟链重组
+type Chain struct {
+	mu       sync.Mutex
+	key      *ecdsa.PrivateKey
+	nonce    uint64
+	fork     uint64 // 每次回退后递增，使新出的区块哈希与旧区块不同
+	blocks   []*types.Block
+	receipts [][]*types.Receipt
+	calls    [][]Tx // 每个区块的测试交易，用于返回追踪结果
+	client   *ethclient.Client
+	// 按区块号模拟节点返回区块的耗时
+	latency func(number uint64) time.Duration
+	// 节点不支持的方法
+	disabled map[string]bool
+	// 暂时无法获取的区块
+	unavailable map[uint64]bool
+	// 按合约地址模拟 eth_call 的返回值
+	contracts map[common.Address]ContractFunc
+	// 账户 ETH 余额
+	balances map[common.Address]*big.Int
+	// 合约字节码
+	codes map[common.Address][]byte
+}
+
+// 模拟合约调用：data 为调用数据，返回 ABI 编码的结果。返回 rpc.Error 时节点原样返回该错误（用于模拟限流等节点错误），
+// 返回其他错误时节点按 revert 处理
+type ContractFunc func(data []byte) ([]byte, error)
+
+// 启动测试链（只有创世区块），测试结束时自动关闭
+func NewChain(t testing.TB) *Chain {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("生成测试账户失败: %v", err)
+	}
+	c := &Chain{key: key}
+	c.appendBlock(nil, nil)
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", &ethAPI{chain: c}); err != nil {
+		t.Fatalf("注册 RPC 服务失败: %v", err)
+	}
+	if err := srv.RegisterName("debug", &debugAPI{chain: c}); err != nil {
+		t.Fatalf("注册 RPC 服务失败: %v", err)
+	}
+	if err := srv.RegisterName("trace", &traceAPI{chain: c}); err != nil {
+		t.Fatalf("注册 RPC 服务失败: %v", err)
+	}
+	httpSrv := httptest.NewServer(srv)
+
+	client, err := ethclient.Dial(httpSrv.URL)
+	if err != nil {
+		t.Fatalf("连接测试链失败: %v", err)
+	}
+	c.client = client
+
+	t.Cleanup(func() {
+		client.Close()
+		httpSrv.Close()
+		srv.Stop()
+	})
+	return c
+}
+
+// 连接测试链的客户端
+func (c *Chain) Client() *ethclient.Client {
+	return c.client
+}
+
+// 测试账户地址，即所有测试交易的发送方
+func (c *Chain) Sender() common.Address {
+	return crypto.PubkeyToAddress(c.key.PublicKey)
+}
+
+// 出一个包含 txs 的新区块并返回
+func (c *Chain) Mine(txs ...Tx) *types.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.appendBlock(txs, nil)
+}
+
+// 出一个包含信标链提款和 txs 的新区块并返回
+func (c *Chain) MineWithdrawals(withdrawals []*types.Withdrawal, txs ...Tx) *types.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.appendBlock(txs, withdrawals)
+}
+
+// 连续出 n 个空区块
+func (c *Chain) MineEmpty(n int) {
+	for i := 0; i < n; i++ {
+		c.Mine()
+	}
+}
+
+// 丢弃 number 之后的区块，之后出的区块形成一条新的分叉
+func (c *Chain) Rewind(number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocks = c.blocks[:number+1]
+	c.receipts = c.receipts[:number+1]
+	c.calls = c.calls[:number+1]
+	c.fork++
+}
+
+// 设置节点返回完整区块前的等待时间，用于模拟乱序完成的请求
+func (c *Chain) SetLatency(latency func(number uint64) time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latency = latency
+}
+
+// 让节点对 method 返回方法不存在错误，支持 eth_getBlockReceipts、debug_traceBlockByNumber 和 trace_block
+func (c *Chain) DisableMethod(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.disabled == nil {
+		c.disabled = make(map[string]bool)
+	}
+	c.disabled[method] = true
+}
+
+func (c *Chain) isDisabled(method string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.disabled[method]
+}
+
+// 设置区块是否暂时无法获取，无法获取时节点对完整区块请求返回 header not found
+func (c *Chain) SetUnavailable(number uint64, unavailable bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unavailable == nil {
+		c.unavailable = make(map[uint64]bool)
+	}
+	c.unavailable[number] = unavailable
+}
+
+// 设置 address 处合约对 eth_call 的响应，未设置的地址一律 revert
+func (c *Chain) SetContract(address common.Address, fn ContractFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.contracts == nil {
+		c.contracts = make(map[common.Address]ContractFunc)
+	}
+	c.contracts[address] = fn
+}
+
+// 设置账户的 ETH 余额
+func (c *Chain) SetBalance(address common.Address, balance *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.balances == nil {
+		c.balances = make(map[common.Address]*big.Int)
+	}
+	c.balances[address] = balance
+}
+
+// 设置合约字节码
+func (c *Chain) SetCode(address common.Address, code []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.codes == nil {
+		c.codes = make(map[common.Address][]byte)
+	}
+	c.codes[address] = code
+}
+
+// 下一笔测试交易部署的合约地址
+func (c *Chain) NextContractAddress() common.Address {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return crypto.CreateAddress(crypto.PubkeyToAddress(c.key.PublicKey), c.nonce)
+}
+
+func (c *Chain) code(address common.Address) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.codes[address]
+}
+
+func (c *Chain) balance(address common.Address) *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if balance, ok := c.balances[address]; ok {
+		return balance
+	}
+	return new(big.Int)
+}
+
+func (c *Chain) contract(address common.Address) ContractFunc {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.contracts[address]
+}
+
+// 链头区块
+func (c *Chain) Head() *types.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blocks[len(c.blocks)-1]
+}
+
+// 指定高度的区块
+func (c *Chain) Block(number uint64) *types.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blocks[number]
+}
+
+func (c *Chain) appendBlock(txs []Tx, withdrawals []*types.Withdrawal) *types.Block {
+	number := uint64(len(c.blocks))
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Time:       1700000000 + number*12,
+		GasLimit:   30_000_000,
+		BaseFee:    big.NewInt(1_000_000_000),
+		Difficulty: new(big.Int),
+		Coinbase:   common.BigToAddress(new(big.Int).SetUint64(c.fork)),
+		Extra:      new(big.Int).SetUint64(c.fork).Bytes(),
+	}
+	if number > 0 {
+		header.ParentHash = c.blocks[number-1].Hash()
+	}
+
+	signer := types.LatestSignerForChainID(ChainID)
+	var transactions []*types.Transaction
+	var receipts []*types.Receipt
+	var logIndex uint
+	for i, spec := range txs {
+		to := &spec.To
+		if spec.Deploy {
+			to = nil
+		}
+		value := spec.Value
+		if value == nil {
+			value = new(big.Int)
+		}
+		var inner types.TxData = &types.DynamicFeeTx{
+			ChainID:   ChainID,
+			Nonce:     c.nonce,
+			GasTipCap: big.NewInt(1_000_000_000),
+			GasFeeCap: big.NewInt(3_000_000_000),
+			Gas:       100_000,
+			To:        to,
+			Value:     value,
+			Data:      spec.Data,
+		}
+		switch {
+		case spec.Legacy:
+			inner = &types.LegacyTx{
+				Nonce:    c.nonce,
+				GasPrice: big.NewInt(2_000_000_000),
+				Gas:      100_000,
+				To:       to,
+				Value:    value,
+				Data:     spec.Data,
+			}
+		case spec.Blobs > 0:
+			hashes := make([]common.Hash, spec.Blobs)
+			for j := range hashes {
+				hashes[j] = common.BigToHash(big.NewInt(int64(j + 1)))
+				hashes[j][0] = 0x01 // 版本号 kzg
+			}
+			inner = &types.BlobTx{
+				ChainID:    uint256.MustFromBig(ChainID),
+				Nonce:      c.nonce,
+				GasTipCap:  uint256.NewInt(1_000_000_000),
+				GasFeeCap:  uint256.NewInt(3_000_000_000),
+				Gas:        100_000,
+				To:         spec.To,
+				Value:      uint256.MustFromBig(value),
+				Data:       spec.Data,
+				BlobFeeCap: uint256.NewInt(100),
+				BlobHashes: hashes,
+			}
+		}
+		tx := types.MustSignNewTx(c.key, signer, inner)
+
+		receipt := &types.Receipt{
+			Type:              tx.Type(),
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(i+1) * 21000,
+			TxHash:            tx.Hash(),
+			GasUsed:           21000,
+			EffectiveGasPrice: big.NewInt(2_000_000_000),
+			BlockNumber:       header.Number,
+			TransactionIndex:  uint(i),
+			Logs:              []*types.Log{},
+		}
+		if spec.Failed {
+			receipt.Status = types.ReceiptStatusFailed
+		}
+		if spec.Blobs > 0 {
+			receipt.BlobGasUsed = uint64(spec.Blobs) * params.BlobTxBlobGasPerBlob
+			receipt.BlobGasPrice = BlobGasPrice
+		}
+		if spec.Deploy {
+			receipt.ContractAddress = crypto.CreateAddress(c.Sender(), c.nonce)
+		}
+		c.nonce++
+		for _, l := range spec.Logs {
+			log := *l
+			log.TxHash = tx.Hash()
+			log.TxIndex = uint(i)
+			log.BlockNumber = number
+			log.Index = logIndex
+			logIndex++
+			receipt.Logs = append(receipt.Logs, &log)
+		}
+		receipt.Bloom = types.CreateBloom(receipt)
+
+		transactions = append(transactions, tx)
+		receipts = append(receipts, receipt)
+	}
+	header.GasUsed = uint64(len(txs)) * 21000
+	var blobGasUsed, excessBlobGas uint64
+	for _, receipt := range receipts {
+		blobGasUsed += receipt.BlobGasUsed
+	}
+	header.BlobGasUsed = &blobGasUsed
+	header.ExcessBlobGas = &excessBlobGas
+
+	body := &types.Body{Transactions: transactions, Withdrawals: withdrawals}
+	block := types.NewBlock(header, body, receipts, trie.NewStackTrie(nil))
+	for _, receipt := range receipts {
+		receipt.BlockHash = block.Hash()
+		for _, log := range receipt.Logs {
+			log.BlockHash = block.Hash()
+		}
+	}
+
+	c.blocks = append(c.blocks, block)
+	c.receipts = append(c.receipts, receipts)
+	c.calls = append(c.calls, txs)
+	return block
+}
+
+// 按区块号查找区块，负数表示 latest / safe / finalized 等标签，都返回链头
+func (c *Chain) blockByNumber(number rpc.BlockNumber) (*types.Block, []*types.Receipt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := int64(number)
+	if n < 0 {
+		n = int64(len(c.blocks) - 1)
+	}
+	if n >= int64(len(c.blocks)) {
+		return nil, nil
+	}
+	return c.blocks[n], c.receipts[n]
+}
+
+func (c *Chain) blockByHash(hash common.Hash) (*types.Block, []*types.Receipt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, block := range c.blocks {
+		if block.Hash() == hash {
+			return block, c.receipts[i]
+		}
+	}
+	return nil, nil
+}
+
+// eth 命名空间的 RPC 方法
+type ethAPI struct {
+	chain *Chain
+}
+
+// 节点不支持的方法返回的错误
+type methodNotFoundError struct {
+	method string
+}
+
+func (e methodNotFoundError) Error() string {
+	return "the method " + e.method + " does not exist/is not available"
+}
+func (methodNotFoundError) ErrorCode() int { return -32601 }
+
+// 区块暂时无法获取时返回的错误
+type headerNotFoundError struct{}
+
+func (headerNotFoundError) Error() string  { return "header not found" }
+func (headerNotFoundError) ErrorCode() int { return -32000 }
+
+// 合约调用统一返回的 revert 错误
+type revertError struct{}
+
+func (revertError) Error() string  { return "execution reverted" }
+func (revertError) ErrorCode() int { return 3 }
+
+func (api *ethAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(ChainID)
+}
+
+func (api *ethAPI) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(api.chain.Head().NumberU64())
+}
+
+func (api *ethAPI) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	block, _ := api.chain.blockByNumber(number)
+	if block == nil {
+		return nil, nil
+	}
+
+	api.chain.mu.Lock()
+	latency := api.chain.latency
+	unavailable := api.chain.unavailable[block.NumberU64()]
+	api.chain.mu.Unlock()
+	if fullTx && latency != nil {
+		time.Sleep(latency(block.NumberU64()))
+	}
+	if fullTx && unavailable {
+		return nil, headerNotFoundError{}
+	}
+	return marshalBlock(block, fullTx)
+}
+
+func (api *ethAPI) GetBlockByHash(hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	block, _ := api.chain.blockByHash(hash)
+	if block == nil {
+		return nil, nil
+	}
+	return marshalBlock(block, fullTx)
+}
+
+func (api *ethAPI) GetBlockReceipts(blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	if api.chain.isDisabled("eth_getBlockReceipts") {
+		return nil, methodNotFoundError{method: "eth_getBlockReceipts"}
+	}
+
+	var receipts []*types.Receipt
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		_, receipts = api.chain.blockByHash(hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		_, receipts = api.chain.blockByNumber(number)
+	}
+	if receipts == nil {
+		receipts = []*types.Receipt{}
+	}
+	return receipts, nil
+}
+
+func (api *ethAPI) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	api.chain.mu.Lock()
+	defer api.chain.mu.Unlock()
+
+	for _, receipts := range api.chain.receipts {
+		for _, receipt := range receipts {
+			if receipt.TxHash == hash {
+				return receipt, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (api *ethAPI) GetTransactionByHash(hash common.Hash) (map[string]interface{}, error) {
+	api.chain.mu.Lock()
+	blocks := api.chain.blocks
+	api.chain.mu.Unlock()
+
+	for _, block := range blocks {
+		for i, tx := range block.Transactions() {
+			if tx.Hash() == hash {
+				return marshalTransaction(block, i, tx)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (api *ethAPI) GetCode(address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	return api.chain.code(address), nil
+}
+
+func (api *ethAPI) GetBalance(address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	return (*hexutil.Big)(api.chain.balance(address)), nil
+}
+
+func (api *ethAPI) Call(ctx context.Context, args json.RawMessage, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	var call struct {
+		To    *common.Address `json:"to"`
+		Data  hexutil.Bytes   `json:"data"`
+		Input hexutil.Bytes   `json:"input"`
+	}
+	if err := json.Unmarshal(args, &call); err != nil || call.To == nil {
+		return nil, revertError{}
+	}
+	data := call.Input
+	if data == nil {
+		data = call.Data
+	}
+
+	fn := api.chain.contract(*call.To)
+	if fn == nil {
+		return nil, revertError{}
+	}
+	result, err := fn(data)
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, revertError{}
+	}
+	return result, nil
+}
+
+// 按节点返回格式序列化区块
+func marshalBlock(block *types.Block, fullTx bool) (map[string]interface{}, error) {
+	raw, err := json.Marshal(block.Header())
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	fields["hash"] = block.Hash()
+	fields["uncles"] = []common.Hash{}
+	fields["size"] = hexutil.Uint64(block.Size())
+	if block.Withdrawals() != nil {
+		fields["withdrawals"] = block.Withdrawals()
+	}
+
+	txs := make([]interface{}, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if !fullTx {
+			txs = append(txs, tx.Hash())
+			continue
+		}
+		txFields, err := marshalTransaction(block, i, tx)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, txFields)
+	}
+	fields["transactions"] = txs
+	return fields, nil
+}
+
+// 按节点返回格式序列化已上链的交易
+func marshalTransaction(block *types.Block, index int, tx *types.Transaction) (map[string]interface{}, error) {
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	txFields := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &txFields); err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(ChainID), tx)
+	if err != nil {
+		return nil, errors.New("恢复发送方地址失败")
+	}
+	txFields["from"] = from
+	txFields["blockHash"] = block.Hash()
+	txFields["blockNumber"] = (*hexutil.Big)(block.Number())
+	txFields["transactionIndex"] = hexutil.Uint64(index)
+	return txFields, nil
+}
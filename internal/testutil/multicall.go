@@ -0,0 +1,50 @@
+package testutil
+
+import (
+	"blockchain-asset-api/internal/util"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 在 address 部署模拟的 Multicall3：aggregate3 依次调用链上已设置的合约，getEthBalance 返回账户余额
+func (c *Chain) DeployMulticall3(address common.Address) {
+	aggregate3 := util.Multicall3ABI.Methods["aggregate3"]
+	getEthBalance := util.Multicall3ABI.Methods["getEthBalance"]
+
+	c.SetContract(address, func(data []byte) ([]byte, error) {
+		if len(data) < 4 {
+			return nil, errors.New("缺少方法选择器")
+		}
+		switch string(data[:4]) {
+		case string(aggregate3.ID):
+			values, err := aggregate3.Inputs.Unpack(data[4:])
+			if err != nil {
+				return nil, err
+			}
+			calls := *abi.ConvertType(values[0], new([]util.MulticallCall)).(*[]util.MulticallCall)
+
+			results := make([]util.MulticallResult, len(calls))
+			for i, call := range calls {
+				var output []byte
+				err := errors.New("execution reverted")
+				if fn := c.contract(call.Target); fn != nil {
+					output, err = fn(call.CallData)
+				}
+				if err != nil && !call.AllowFailure {
+					return nil, err
+				}
+				results[i] = util.MulticallResult{Success: err == nil, ReturnData: output}
+			}
+			return aggregate3.Outputs.Pack(results)
+
+		case string(getEthBalance.ID):
+			values, err := getEthBalance.Inputs.Unpack(data[4:])
+			if err != nil {
+				return nil, err
+			}
+			return getEthBalance.Outputs.Pack(c.balance(values[0].(common.Address)))
+		}
+		return nil, errors.New("未实现的方法")
+	})
+}
@@ -0,0 +1,42 @@
+package testutil
+
+import (
+	"fmt"
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/logger"
+	"net"
+	"testing"
+)
+
+// 启动进程内的 MySQL 兼容数据库（go-mysql-server 内存库），返回连接 DSN，测试结束时自动关闭
+func StartMySQL(t testing.TB) string {
+	t.Helper()
+
+	// go-mysql-server 通过 logrus 全局实例输出查询日志，gorm 默认打印慢查询和未找到记录
+	logrus.SetLevel(logrus.WarnLevel)
+	logger.Default = logger.Discard
+
+	db := memory.NewDatabase("test")
+	db.BaseDatabase.EnablePrimaryKeyIndexes()
+	provider := memory.NewDBProvider(db)
+	engine := sqle.NewDefault(provider)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听端口失败: %v", err)
+	}
+	addr := listener.Addr().String()
+
+	srv, err := server.NewServer(server.Config{Protocol: "tcp", Listener: listener}, engine, sql.NewContext, memory.NewSessionBuilder(provider), nil)
+	if err != nil {
+		t.Fatalf("启动测试数据库失败: %v", err)
+	}
+	go srv.Start()
+	t.Cleanup(func() { srv.Close() })
+
+	return fmt.Sprintf("root@tcp(%s)/test?charset=utf8mb4&parseTime=True&loc=Local", addr)
+}
@@ -0,0 +1,160 @@
+package testutil
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"strings"
+)
+
+// 按区块号查找区块、回执和其中的测试交易
+func (c *Chain) blockCalls(number rpc.BlockNumber) (*types.Block, []*types.Receipt, []Tx) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := int64(number)
+	if n < 0 {
+		n = int64(len(c.blocks) - 1)
+	}
+	if n >= int64(len(c.blocks)) {
+		return nil, nil, nil
+	}
+	return c.blocks[n], c.receipts[n], c.calls[n]
+}
+
+// 交易的顶层调用，合约创建交易为 CREATE
+func (c *Chain) topCall(tx *types.Transaction, receipt *types.Receipt, spec Tx) Call {
+	call := Call{Type: "CALL", From: c.Sender(), Value: tx.Value(), Calls: spec.Calls}
+	if tx.To() != nil {
+		call.To = *tx.To()
+	} else {
+		call.Type, call.To = "CREATE", receipt.ContractAddress
+	}
+	if spec.Failed {
+		call.Error = "execution reverted"
+	}
+	return call
+}
+
+// debug 命名空间的 RPC 方法
+type debugAPI struct {
+	chain *Chain
+}
+
+// callTracer 格式的追踪结果
+func (api *debugAPI) TraceBlockByNumber(number rpc.BlockNumber, config map[string]interface{}) ([]map[string]interface{}, error) {
+	if api.chain.isDisabled("debug_traceBlockByNumber") {
+		return nil, methodNotFoundError{method: "debug_traceBlockByNumber"}
+	}
+	block, receipts, specs := api.chain.blockCalls(number)
+	if block == nil {
+		return nil, headerNotFoundError{}
+	}
+
+	results := make([]map[string]interface{}, 0, len(specs))
+	for i, tx := range block.Transactions() {
+		results = append(results, map[string]interface{}{
+			"txHash": tx.Hash(),
+			"result": callFrame(api.chain.topCall(tx, receipts[i], specs[i])),
+		})
+	}
+	return results, nil
+}
+
+func callFrame(call Call) map[string]interface{} {
+	frame := map[string]interface{}{
+		"type": call.Type,
+		"from": call.From,
+		"to":   call.To,
+	}
+	if call.Value != nil {
+		frame["value"] = (*hexutil.Big)(call.Value)
+	}
+	if call.Error != "" {
+		frame["error"] = call.Error
+	}
+	if len(call.Calls) > 0 {
+		calls := make([]map[string]interface{}, 0, len(call.Calls))
+		for _, child := range call.Calls {
+			calls = append(calls, callFrame(child))
+		}
+		frame["calls"] = calls
+	}
+	return frame
+}
+
+// trace 命名空间的 RPC 方法
+type traceAPI struct {
+	chain *Chain
+}
+
+// trace_block 格式的追踪结果，最后附加一条不属于任何交易的区块奖励记录
+func (api *traceAPI) Block(number rpc.BlockNumber) ([]map[string]interface{}, error) {
+	if api.chain.isDisabled("trace_block") {
+		return nil, methodNotFoundError{method: "trace_block"}
+	}
+	block, receipts, specs := api.chain.blockCalls(number)
+	if block == nil {
+		return nil, headerNotFoundError{}
+	}
+
+	var traces []map[string]interface{}
+	for i, tx := range block.Transactions() {
+		traces = appendParityTraces(traces, tx.Hash(), api.chain.topCall(tx, receipts[i], specs[i]), []int{})
+	}
+	traces = append(traces, map[string]interface{}{
+		"action":      map[string]interface{}{"author": block.Coinbase(), "rewardType": "block", "value": "0x0"},
+		"blockNumber": block.NumberU64(),
+		"type":        "reward",
+	})
+	return traces, nil
+}
+
+func appendParityTraces(traces []map[string]interface{}, txHash common.Hash, call Call, traceAddress []int) []map[string]interface{} {
+	value := call.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	trace := map[string]interface{}{
+		"traceAddress":    traceAddress,
+		"transactionHash": txHash,
+		"subtraces":       len(call.Calls),
+	}
+	switch call.Type {
+	case "CREATE", "CREATE2":
+		trace["type"] = "create"
+		trace["action"] = map[string]interface{}{
+			"from":           call.From,
+			"value":          (*hexutil.Big)(value),
+			"creationMethod": strings.ToLower(call.Type),
+		}
+		trace["result"] = map[string]interface{}{"address": call.To}
+	case "SELFDESTRUCT":
+		trace["type"] = "suicide"
+		trace["action"] = map[string]interface{}{
+			"address":       call.From,
+			"refundAddress": call.To,
+			"balance":       (*hexutil.Big)(value),
+		}
+	default:
+		trace["type"] = "call"
+		trace["action"] = map[string]interface{}{
+			"callType": strings.ToLower(call.Type),
+			"from":     call.From,
+			"to":       call.To,
+			"value":    (*hexutil.Big)(value),
+		}
+	}
+	if call.Error != "" {
+		trace["error"] = call.Error
+	}
+	traces = append(traces, trace)
+
+	for i, child := range call.Calls {
+		traces = appendParityTraces(traces, txHash, child, append(append([]int{}, traceAddress...), i))
+	}
+	return traces
+}
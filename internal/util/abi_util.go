@@ -0,0 +1,256 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodedEvent 按 ABI 解码的事件日志
+type DecodedEvent struct {
+	Name      string
+	Signature string                 // 规范化签名，如 Transfer(address,address,uint256)
+	Args      map[string]interface{} // 参数名到值，数值统一为十进制字符串，字节为 0x 开头的十六进制
+}
+
+// 按事件定义解码日志：indexed 参数取自 topics，其余参数从 data 解码。
+// 动态类型（string、bytes、数组、结构体）的 indexed 参数在日志中只有哈希，解码为该哈希
+func DecodeEventLog(event *abi.Event, topics []common.Hash, data []byte) (*DecodedEvent, error) {
+	if !event.Anonymous {
+		if len(topics) == 0 || topics[0] != event.ID {
+			return nil, errors.New("事件签名不匹配")
+		}
+		topics = topics[1:]
+	}
+
+	args := namedArguments(event.Inputs)
+	indexedCount := 0
+	for _, arg := range args {
+		if arg.Indexed {
+			indexedCount++
+		}
+	}
+	if indexedCount != len(topics) {
+		return nil, fmt.Errorf("indexed 参数数量 %d 与 topic 数量 %d 不一致", indexedCount, len(topics))
+	}
+
+	values := make(map[string]interface{}, len(args))
+	if err := args.NonIndexed().UnpackIntoMap(values, data); err != nil {
+		return nil, fmt.Errorf("解码事件数据失败: %v", err)
+	}
+
+	i := 0
+	for _, arg := range args {
+		if !arg.Indexed {
+			continue
+		}
+		topic := topics[i]
+		i++
+		if isHashedTopic(arg.Type) {
+			values[arg.Name] = topic
+			continue
+		}
+		if err := abi.ParseTopicsIntoMap(values, abi.Arguments{arg}, []common.Hash{topic}); err != nil {
+			return nil, fmt.Errorf("解码 indexed 参数 %s 失败: %v", arg.Name, err)
+		}
+	}
+
+	for name, value := range values {
+		values[name] = NormalizeABIValue(value)
+	}
+	return &DecodedEvent{Name: event.RawName, Signature: event.Sig, Args: values}, nil
+}
+
+// DecodedCall 按 ABI 解码的函数调用
+type DecodedCall struct {
+	Name      string
+	Signature string                 // 规范化签名，如 transfer(address,uint256)
+	Args      map[string]interface{} // 参数名到值，取值规则同 DecodedEvent
+}
+
+// 按函数定义解码交易调用数据（4 字节选择器 + ABI 编码的参数）
+func DecodeMethodInput(method *abi.Method, input []byte) (*DecodedCall, error) {
+	if len(input) < 4 || !bytes.Equal(input[:4], method.ID) {
+		return nil, errors.New("函数选择器不匹配")
+	}
+
+	values := make(map[string]interface{}, len(method.Inputs))
+	if err := namedArguments(method.Inputs).UnpackIntoMap(values, input[4:]); err != nil {
+		return nil, fmt.Errorf("解码调用参数失败: %v", err)
+	}
+	for name, value := range values {
+		values[name] = NormalizeABIValue(value)
+	}
+	return &DecodedCall{Name: method.RawName, Signature: method.Sig, Args: values}, nil
+}
+
+// 未命名参数按位置命名为 arg0、arg1……，避免解码结果中键名冲突
+func namedArguments(inputs abi.Arguments) abi.Arguments {
+	args := make(abi.Arguments, len(inputs))
+	for i, arg := range inputs {
+		if arg.Name == "" {
+			arg.Name = "arg" + strconv.Itoa(i)
+		}
+		args[i] = arg
+	}
+	return args
+}
+
+// 日志 topic 中只保存哈希的参数类型
+func isHashedTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// 查找事件的 indexed 参数（未命名参数按 argN 匹配），返回参数及其在 topics 中的位置（从 1 开始）
+func IndexedArgument(event *abi.Event, name string) (abi.Argument, int, error) {
+	position := 1
+	if event.Anonymous {
+		position = 0
+	}
+	for _, arg := range namedArguments(event.Inputs) {
+		if !arg.Indexed {
+			continue
+		}
+		if arg.Name == name {
+			return arg, position, nil
+		}
+		position++
+	}
+	return abi.Argument{}, 0, fmt.Errorf("事件 %s 没有 indexed 参数 %s", event.RawName, name)
+}
+
+// 把参数值编码为日志 topic：地址、整数、布尔值和定长字节按 ABI 编码，
+// 动态类型取 keccak256（string 按原文，bytes 按十六进制），也可以直接传入 32 字节的 topic
+func EncodeTopic(arg abi.Argument, value string) (common.Hash, error) {
+	if isHashedTopic(arg.Type) {
+		if arg.Type.T == abi.StringTy {
+			return crypto.Keccak256Hash([]byte(value)), nil
+		}
+		data, err := hexutil.Decode(value)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("参数 %s 不是十六进制: %v", arg.Name, err)
+		}
+		if arg.Type.T == abi.BytesTy {
+			return crypto.Keccak256Hash(data), nil
+		}
+		if len(data) != common.HashLength {
+			return common.Hash{}, fmt.Errorf("参数 %s 需要传入 32 字节的 topic", arg.Name)
+		}
+		return common.BytesToHash(data), nil
+	}
+
+	// 已编码的 topic 原样使用
+	if strings.HasPrefix(value, "0x") && len(value) == 66 && arg.Type.T != abi.FixedBytesTy {
+		return common.HexToHash(value), nil
+	}
+
+	switch arg.Type.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(value) {
+			return common.Hash{}, fmt.Errorf("参数 %s 不是有效地址", arg.Name)
+		}
+		return common.BytesToHash(common.HexToAddress(value).Bytes()), nil
+	case abi.BoolTy:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("参数 %s 不是布尔值", arg.Name)
+		}
+		if b {
+			return common.BigToHash(big.NewInt(1)), nil
+		}
+		return common.Hash{}, nil
+	case abi.UintTy, abi.IntTy:
+		n, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return common.Hash{}, fmt.Errorf("参数 %s 不是整数", arg.Name)
+		}
+		if n.Sign() < 0 {
+			if arg.Type.T == abi.UintTy {
+				return common.Hash{}, fmt.Errorf("参数 %s 不能为负数", arg.Name)
+			}
+			// 负数按 256 位补码编码
+			n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		if n.BitLen() > 256 {
+			return common.Hash{}, fmt.Errorf("参数 %s 超出范围", arg.Name)
+		}
+		return common.BigToHash(n), nil
+	case abi.FixedBytesTy:
+		data, err := hexutil.Decode(value)
+		if err != nil || len(data) > arg.Type.Size {
+			return common.Hash{}, fmt.Errorf("参数 %s 不是 bytes%d", arg.Name, arg.Type.Size)
+		}
+		return common.BytesToHash(common.RightPadBytes(data, common.HashLength)), nil
+	}
+	return common.Hash{}, fmt.Errorf("不支持按 %s 类型的参数 %s 筛选", arg.Type.String(), arg.Name)
+}
+
+// 把 ABI 解码结果转换为便于 JSON 序列化的值：整数转为十进制字符串（避免前端精度丢失），
+// 地址和哈希转为十六进制，字节转为 0x 开头的十六进制，结构体转为按字段名索引的对象
+func NormalizeABIValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case string, bool:
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return NormalizeABIValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Array, reflect.Slice:
+		// 定长字节数组（bytesN、function）
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, rv.Len())
+			for i := range data {
+				data[i] = byte(rv.Index(i).Uint())
+			}
+			return hexutil.Encode(data)
+		}
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = NormalizeABIValue(rv.Index(i).Interface())
+		}
+		return items
+	case reflect.Struct:
+		fields := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			name := field.Tag.Get("json")
+			if name == "" {
+				name = field.Name
+			}
+			fields[name] = NormalizeABIValue(rv.Field(i).Interface())
+		}
+		return fields
+	}
+	return value
+}
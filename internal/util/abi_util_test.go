@@ -0,0 +1,54 @@
+package util
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"testing"
+)
+
+func TestEncodeTopic(t *testing.T) {
+	newArg := func(typ string) abi.Argument {
+		abiType, err := abi.NewType(typ, "", nil)
+		if err != nil {
+			t.Fatalf("创建类型 %s 失败: %v", typ, err)
+		}
+		return abi.Argument{Name: "v", Type: abiType, Indexed: true}
+	}
+
+	cases := []struct {
+		typ   string
+		value string
+		want  string
+	}{
+		{"address", "0x00000000000000000000000000000000000000a1", "0x00000000000000000000000000000000000000000000000000000000000000a1"},
+		{"uint256", "255", "0x00000000000000000000000000000000000000000000000000000000000000ff"},
+		{"uint256", "0xff", "0x00000000000000000000000000000000000000000000000000000000000000ff"},
+		{"int256", "-1", "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+		{"bool", "true", "0x0000000000000000000000000000000000000000000000000000000000000001"},
+		{"bytes4", "0xa9059cbb", "0xa9059cbb00000000000000000000000000000000000000000000000000000000"},
+		{"string", "gold", crypto.Keccak256Hash([]byte("gold")).Hex()},
+		{"bytes", "0x0102", crypto.Keccak256Hash([]byte{0x01, 0x02}).Hex()},
+	}
+	for _, c := range cases {
+		got, err := EncodeTopic(newArg(c.typ), c.value)
+		if err != nil {
+			t.Errorf("EncodeTopic(%s, %q) 失败: %v", c.typ, c.value, err)
+			continue
+		}
+		if got != common.HexToHash(c.want) {
+			t.Errorf("EncodeTopic(%s, %q) = %s，期望 %s", c.typ, c.value, got.Hex(), c.want)
+		}
+	}
+
+	for _, c := range []struct{ typ, value string }{
+		{"address", "0x1234"},
+		{"uint256", "-1"},
+		{"uint256", "abc"},
+		{"bool", "yes"},
+	} {
+		if _, err := EncodeTopic(newArg(c.typ), c.value); err == nil {
+			t.Errorf("EncodeTopic(%s, %q) 应返回错误", c.typ, c.value)
+		}
+	}
+}
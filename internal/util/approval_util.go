@@ -0,0 +1,65 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+)
+
+// 授权查询方法选择器
+var (
+	allowanceSelector        = crypto.Keccak256([]byte("allowance(address,address)"))[:4]
+	isApprovedForAllSelector = crypto.Keccak256([]byte("isApprovedForAll(address,address)"))[:4]
+)
+
+// AllowanceQuery 一个授权查询，ForAll 时查询 isApprovedForAll，否则查询 ERC20 allowance
+type AllowanceQuery struct {
+	Token   common.Address
+	Owner   common.Address
+	Spender common.Address
+	ForAll  bool
+}
+
+// 在一个批量 JSON-RPC 请求中通过 eth_call 查询当前授权，结果顺序与 queries 一致。
+// isApprovedForAll 的结果为 0 或 1；单个调用失败时对应结果为 nil，整个批量请求失败才返回错误。
+func GetAllowances(ctx context.Context, queries []AllowanceQuery) ([]*big.Int, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+
+	results := make([]hexutil.Bytes, len(queries))
+	batch := make([]rpc.BatchElem, len(queries))
+	for i, q := range queries {
+		selector := allowanceSelector
+		if q.ForAll {
+			selector = isApprovedForAllSelector
+		}
+		input := append(append(append([]byte{}, selector...),
+			common.LeftPadBytes(q.Owner.Bytes(), 32)...), common.LeftPadBytes(q.Spender.Bytes(), 32)...)
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":    q.Token,
+				"input": hexutil.Bytes(input),
+			}, "latest"},
+			Result: &results[i],
+		}
+	}
+
+	if err := EthClient.Client().BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("查询授权额度失败: %v", err)
+	}
+
+	allowances := make([]*big.Int, len(queries))
+	for i := range batch {
+		if batch[i].Error != nil || len(results[i]) < 32 {
+			continue
+		}
+		allowances[i] = new(big.Int).SetBytes(results[i][:32])
+	}
+	return allowances, nil
+}
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+)
+
+// 合约实现的代币标准
+const (
+	StandardERC20   = "erc20"
+	StandardERC721  = "erc721"
+	StandardERC1155 = "erc1155"
+)
+
+// ERC-165 supportsInterface(bytes4) 的接口 ID
+var (
+	interfaceIDERC721  = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	interfaceIDERC1155 = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+// 按字节码中的函数选择器识别代币标准，需要包含全部选择器
+var standardSelectors = []struct {
+	standard  string
+	selectors []string
+}{
+	// transfer、transferFrom、approve、balanceOf、totalSupply、allowance
+	{StandardERC20, []string{"a9059cbb", "23b872dd", "095ea7b3", "70a08231", "18160ddd", "dd62ed3e"}},
+	// ownerOf、safeTransferFrom(address,address,uint256)、setApprovalForAll、getApproved
+	{StandardERC721, []string{"6352211e", "42842e0e", "a22cb465", "081812fc"}},
+	// balanceOfBatch、safeBatchTransferFrom、setApprovalForAll
+	{StandardERC1155, []string{"4e1273f4", "2eb2c2d6", "a22cb465"}},
+}
+
+// ContractInfo 合约运行时字节码信息
+type ContractInfo struct {
+	BytecodeHash common.Hash
+	CodeSize     int
+	Standards    []string // 检测到的代币标准
+}
+
+// 查询合约在 blockNumber 时的字节码并检测代币标准：优先通过 ERC-165 supportsInterface，
+// 其次按字节码中的函数选择器判断。节点已裁剪该区块状态时改为查询最新区块
+func InspectContract(ctx context.Context, address common.Address, blockNumber *big.Int) (*ContractInfo, error) {
+	block := "latest"
+	if blockNumber != nil {
+		block = hexutil.EncodeBig(blockNumber)
+	}
+	info, err := inspectContractAt(ctx, address, block)
+	if err != nil && blockNumber != nil && IsStateUnavailable(err) {
+		return inspectContractAt(ctx, address, "latest")
+	}
+	return info, err
+}
+
+// 在一个批量 JSON-RPC 请求中查询字节码和 ERC-165 接口支持情况
+func inspectContractAt(ctx context.Context, address common.Address, block string) (*ContractInfo, error) {
+	interfaceIDs := [][4]byte{interfaceIDERC721, interfaceIDERC1155}
+
+	var code hexutil.Bytes
+	results := make([]hexutil.Bytes, len(interfaceIDs))
+	batch := []rpc.BatchElem{{
+		Method: "eth_getCode",
+		Args:   []interface{}{address, block},
+		Result: &code,
+	}}
+	for i, id := range interfaceIDs {
+		// supportsInterface(bytes4)
+		data := append(common.FromHex("01ffc9a7"), common.RightPadBytes(id[:], 32)...)
+		batch = append(batch, rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":    address,
+				"input": hexutil.Bytes(data),
+			}, block},
+			Result: &results[i],
+		})
+	}
+
+	if err := EthClient.Client().BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("查询合约 %s 失败: %v", address.Hex(), err)
+	}
+	if batch[0].Error != nil {
+		return nil, fmt.Errorf("查询合约 %s 字节码失败: %w", address.Hex(), batch[0].Error)
+	}
+
+	info := &ContractInfo{BytecodeHash: crypto.Keccak256Hash(code), CodeSize: len(code)}
+	supports := make([]bool, len(interfaceIDs))
+	for i := range interfaceIDs {
+		// 未实现 ERC-165 的合约会 revert 或返回空数据
+		supports[i] = batch[i+1].Error == nil && len(results[i]) == 32 && results[i][31] == 1
+	}
+
+	for _, s := range standardSelectors {
+		switch {
+		case s.standard == StandardERC721 && supports[0],
+			s.standard == StandardERC1155 && supports[1],
+			hasSelectors(code, s.selectors):
+			info.Standards = append(info.Standards, s.standard)
+		}
+	}
+	return info, nil
+}
+
+// 判断字节码中是否包含全部函数选择器（PUSH4 <selector>）
+func hasSelectors(code []byte, selectors []string) bool {
+	if len(code) == 0 {
+		return false
+	}
+	for _, selector := range selectors {
+		if !bytes.Contains(code, append([]byte{0x63}, common.FromHex(selector)...)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+)
+
+// Uniswap 交易池的只读方法选择器
+var (
+	token0Selector = crypto.Keccak256([]byte("token0()"))[:4]
+	token1Selector = crypto.Keccak256([]byte("token1()"))[:4]
+	feeSelector    = crypto.Keccak256([]byte("fee()"))[:4]
+)
+
+// PoolTokens DEX 交易池的代币对，Fee 为 V3 池的手续费率（百万分之一）
+type PoolTokens struct {
+	Token0 common.Address
+	Token1 common.Address
+	Fee    *int
+}
+
+// 在一个批量 JSON-RPC 请求中查询交易池的 token0、token1，withFee 时同时查询 V3 池的 fee。
+// 合约未实现 token0 / token1（不是交易池）时返回 nil，整个批量请求失败才返回错误。
+func GetPoolTokens(ctx context.Context, pool common.Address, withFee bool) (*PoolTokens, error) {
+	selectors := [][]byte{token0Selector, token1Selector}
+	if withFee {
+		selectors = append(selectors, feeSelector)
+	}
+	results := make([]hexutil.Bytes, len(selectors))
+	batch := make([]rpc.BatchElem, len(selectors))
+	for i, selector := range selectors {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":    pool,
+				"input": hexutil.Bytes(selector),
+			}, "latest"},
+			Result: &results[i],
+		}
+	}
+
+	if err := EthClient.Client().BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("查询交易池 %s 代币对失败: %v", pool.Hex(), err)
+	}
+
+	// 只有 revert 或返回空数据才说明合约不是交易池，限流、超时等节点错误返回错误，由调用方重试
+	for i := range batch {
+		if batch[i].Error != nil && !IsExecutionReverted(batch[i].Error) {
+			return nil, fmt.Errorf("查询交易池 %s 代币对失败: %v", pool.Hex(), batch[i].Error)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if batch[i].Error != nil || len(results[i]) < 32 {
+			return nil, nil
+		}
+	}
+	tokens := &PoolTokens{
+		Token0: common.BytesToAddress(results[0][:32]),
+		Token1: common.BytesToAddress(results[1][:32]),
+	}
+	if withFee && batch[2].Error == nil && len(results[2]) >= 32 {
+		// fee 为 uint24
+		if fee := new(big.Int).SetBytes(results[2][:32]); fee.IsInt64() && fee.Int64() < 1<<24 {
+			f := int(fee.Int64())
+			tokens.Fee = &f
+		}
+	}
+	return tokens, nil
+}
@@ -10,9 +10,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"math/big"
-	"reflect"
-	"strconv"
+	"regexp"
 	"strings"
 )
 
@@ -41,6 +41,99 @@ func InitEthClient(nodeURL string) error {
 	return nil
 }
 
+// 判断节点地址是否支持订阅（WebSocket 或 IPC）
+func IsStreamingEndpoint(nodeURL string) bool {
+	if strings.HasPrefix(nodeURL, "ws://") || strings.HasPrefix(nodeURL, "wss://") {
+		return true
+	}
+	// 非 http(s) 地址按 IPC 路径处理
+	return !strings.HasPrefix(nodeURL, "http://") && !strings.HasPrefix(nodeURL, "https://")
+}
+
+// 按标签查询链头区块号，支持 latest / safe / finalized
+func GetHeadNumber(ctx context.Context, tag string) (int64, error) {
+	var number *big.Int
+	switch tag {
+	case "", "latest":
+		number = nil
+	case "safe":
+		number = big.NewInt(int64(rpc.SafeBlockNumber))
+	case "finalized":
+		number = big.NewInt(int64(rpc.FinalizedBlockNumber))
+	default:
+		return 0, fmt.Errorf("不支持的区块标签: %s", tag)
+	}
+
+	header, err := EthClient.HeaderByNumber(ctx, number)
+	if err != nil {
+		return 0, fmt.Errorf("查询 %s 区块头失败: %v", tag, err)
+	}
+	return header.Number.Int64(), nil
+}
+
+// 通过 eth_getBlockReceipts 一次获取区块内全部交易回执
+func GetBlockReceipts(ctx context.Context, blockHash common.Hash) ([]*types.Receipt, error) {
+	return EthClient.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(blockHash, false))
+}
+
+// 在一个批量 JSON-RPC 请求中获取多笔交易回执，返回顺序与 txHashes 一致
+func BatchGetReceipts(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(txHashes))
+	batch := make([]rpc.BatchElem, len(txHashes))
+	for i, hash := range txHashes {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+
+	if err := EthClient.Client().BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("批量查询交易回执失败: %v", err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("查询交易 %s 回执失败: %v", txHashes[i].Hex(), elem.Error)
+		}
+		if receipts[i] == nil {
+			return nil, fmt.Errorf("交易 %s 回执不存在", txHashes[i].Hex())
+		}
+	}
+	return receipts, nil
+}
+
+// 节点不支持 RPC 方法时的错误信息：geth 为 "the method X does not exist/is not available"，其他客户端多为 "method not found"
+var methodNotFoundMessage = regexp.MustCompile(`^(method not found|the method \S+ does not exist/is not available)$`)
+
+// 判断节点返回的错误是否表示不支持该 RPC 方法：只认 JSON-RPC -32601 或完全匹配的错误信息，
+// 避免把 "header for hash does not exist" 之类的临时错误当作不支持，永久切换到备用方法
+func IsMethodNotSupported(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		if rpcErr.ErrorCode() == -32601 {
+			return true
+		}
+		err = rpcErr
+	}
+	return methodNotFoundMessage.MatchString(strings.ToLower(strings.TrimSpace(err.Error())))
+}
+
+// 判断 eth_call 返回的错误是否为合约执行 revert：geth 对带原因的 revert 返回错误码 3，
+// 不带原因时返回 "execution reverted"。限流、超时等节点错误不属于 revert
+func IsExecutionReverted(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == 3 {
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(err.Error())), "execution reverted")
+}
+
 // 转换余额单位（Wei -> ETH）
 func WeiToEth(wei *big.Int) string {
 	if wei == nil {
@@ -53,22 +146,47 @@ func WeiToEth(wei *big.Int) string {
 	return ethFloat.Text('f', -1)
 }
 
-// 查询 ETH 余额
-func GetEthBalance(address string) (string, error) {
+// ErrStateUnavailable 节点已裁剪该区块的状态（非归档节点只保留最近区块的状态）
+var ErrStateUnavailable = errors.New("节点已裁剪该区块的历史状态，请改用归档节点")
+
+// 判断节点返回的错误是否表示历史状态已被裁剪
+func IsStateUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "missing trie node") ||
+		strings.Contains(msg, "state not available") ||
+		strings.Contains(msg, "state is not available") ||
+		strings.Contains(msg, "historical state") ||
+		strings.Contains(msg, "state histories haven't been fully indexed") ||
+		strings.Contains(msg, "pruned")
+}
+
+// 包装历史状态查询的错误，状态被裁剪时返回 ErrStateUnavailable
+func wrapStateError(what string, blockNumber *big.Int, err error) error {
+	if blockNumber != nil && IsStateUnavailable(err) {
+		return fmt.Errorf("%w: 区块 %s (%v)", ErrStateUnavailable, blockNumber, err)
+	}
+	return fmt.Errorf("%s失败: %v", what, err)
+}
+
+// 查询 ETH 余额，blockNumber 为 nil 时查询最新区块
+func GetEthBalance(address string, blockNumber *big.Int) (string, error) {
 	if !common.IsHexAddress(address) {
 		return "", fmt.Errorf("无效的以太坊地址: %s", address)
 	}
 	addr := common.HexToAddress(address)
-	balance, err := EthClient.BalanceAt(context.Background(), addr, nil) // nil 表示最新区块
+	balance, err := EthClient.BalanceAt(context.Background(), addr, blockNumber)
 	if err != nil {
-		return "", fmt.Errorf("查询 ETH 余额失败: %v", err)
+		return "", wrapStateError("查询 ETH 余额", blockNumber, err)
 	}
 
 	return WeiToEth(balance), nil
 }
 
-// 查询 ERC20 代币余额
-func GetErc20Balance(address, contractAddress string) (string, error) {
+// 查询 ERC20 代币余额，blockNumber 为 nil 时查询最新区块
+func GetErc20Balance(address, contractAddress string, blockNumber *big.Int) (string, error) {
 
 	if !common.IsHexAddress(address) || !common.IsHexAddress(contractAddress) {
 		return "", fmt.Errorf("无效的地址: address=%s, contract=%s", address, contractAddress)
@@ -91,9 +209,9 @@ func GetErc20Balance(address, contractAddress string) (string, error) {
 	result, err := EthClient.CallContract(context.Background(), ethereum.CallMsg{
 		To:   &contractAddr,
 		Data: data,
-	}, nil)
+	}, blockNumber)
 	if err != nil {
-		return "", fmt.Errorf("调用 ERC20 balanceOf 失败: %v", err)
+		return "", wrapStateError("调用 ERC20 balanceOf ", blockNumber, err)
 	}
 
 	// 解析返回结果（uint256 -> 字符串）
@@ -152,25 +270,3 @@ func GetBlockByNumber(blockNum string) (*types.Block, error) {
 
 	return block, nil
 }
-
-func LogDtaUnpack(start, end int, val interface{}, data []byte) (err error) {
-	length := len(data)
-	fmt.Println("call---- LogDataUnpack begin", reflect.TypeOf(val).String(), length)
-	if start >= length || end > length {
-		return errors.New("LogDataUnpack: start or end out of rang e")
-	}
-	pdata := data[start:end]
-
-	fmt.Println(string(data), string(pdata))
-	if reflect.TypeOf(val).String() == "int64" || reflect.TypeOf(val).String() == "*int64" {
-		var tmpval *int64 = val.(*int64)
-		*tmpval, err = strconv.ParseInt(string(pdata), 16, 32)
-		fmt.Println("call ParseInt", val)
-	} else if reflect.TypeOf(val).String() == "string" || reflect.TypeOf(val).String() == "*string" {
-		var tmpval *string = val.(*string)
-		*tmpval = string(pdata)
-		fmt.Println("call ParseInt", val)
-	}
-	fmt.Println("call---- LogDtaUnpack end", val)
-	return nil
-}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// 模拟节点返回的 JSON-RPC 错误
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e rpcError) Error() string  { return e.msg }
+func (e rpcError) ErrorCode() int { return e.code }
+
+func TestIsMethodNotSupported(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{rpcError{-32601, "whatever"}, true},
+		{rpcError{-32000, "the method eth_getBlockReceipts does not exist/is not available"}, true},
+		{fmt.Errorf("获取回执失败: %w", rpcError{-32601, "Method not found"}), true},
+		{errors.New("Method not found"), true},
+		{rpcError{-32000, "header for hash does not exist"}, false},
+		{rpcError{-32000, "resource not available"}, false},
+		{rpcError{-32000, "historical state not available"}, false},
+		{errors.New("request timed out: method not found in cache"), false},
+		{nil, false},
+	}
+	for _, c := range cases {
+		if got := IsMethodNotSupported(c.err); got != c.want {
+			t.Errorf("IsMethodNotSupported(%v) = %v，期望 %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestIsExecutionReverted(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{rpcError{3, "execution reverted: not a pool"}, true},
+		{rpcError{-32000, "execution reverted"}, true},
+		{fmt.Errorf("查询交易池失败: %w", rpcError{3, "whatever"}), true},
+		{rpcError{-32005, "limit exceeded"}, false},
+		{errors.New("context deadline exceeded"), false},
+		{nil, false},
+	}
+	for _, c := range cases {
+		if got := IsExecutionReverted(c.err); got != c.want {
+			t.Errorf("IsExecutionReverted(%v) = %v，期望 %v", c.err, got, c.want)
+		}
+	}
+}
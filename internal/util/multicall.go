@@ -0,0 +1,132 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"strings"
+)
+
+// Multicall3 合约 ABI（仅包含 aggregate3 和 getEthBalance）
+const multicall3ABI = `[
+    {
+        "inputs": [{"components": [
+            {"name": "target", "type": "address"},
+            {"name": "allowFailure", "type": "bool"},
+            {"name": "callData", "type": "bytes"}
+        ], "name": "calls", "type": "tuple[]"}],
+        "name": "aggregate3",
+        "outputs": [{"components": [
+            {"name": "success", "type": "bool"},
+            {"name": "returnData", "type": "bytes"}
+        ], "name": "returnData", "type": "tuple[]"}],
+        "stateMutability": "payable",
+        "type": "function"
+    },
+    {
+        "inputs": [{"name": "addr", "type": "address"}],
+        "name": "getEthBalance",
+        "outputs": [{"name": "balance", "type": "uint256"}],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]`
+
+// Multicall3ABI 解析后的 Multicall3 ABI
+var Multicall3ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		panic(fmt.Sprintf("解析 Multicall3 ABI 失败: %v", err))
+	}
+	return parsed
+}()
+
+// MulticallCall Multicall3 中的一个子调用
+type MulticallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// MulticallResult 子调用结果
+type MulticallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// 通过 Multicall3.aggregate3 在一次 eth_call 中执行全部子调用，结果顺序与 calls 一致
+func Multicall(ctx context.Context, multicall common.Address, calls []MulticallCall) ([]MulticallResult, error) {
+	data, err := Multicall3ABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, fmt.Errorf("编码 aggregate3 调用失败: %v", err)
+	}
+
+	output, err := EthClient.CallContract(ctx, ethereum.CallMsg{To: &multicall, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用 Multicall3 失败: %v", err)
+	}
+
+	values, err := Multicall3ABI.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, fmt.Errorf("解析 aggregate3 返回值失败: %v", err)
+	}
+	results := *abi.ConvertType(values[0], new([]MulticallResult)).(*[]MulticallResult)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("aggregate3 返回 %d 个结果，期望 %d 个", len(results), len(calls))
+	}
+	return results, nil
+}
+
+// 按每批 batchSize 个子调用分多次执行 aggregate3，避免子调用过多时单次 eth_call 超出节点的 gas 或响应大小限制。
+// batchSize 不大于 0 时在一次调用中执行全部子调用，结果顺序与 calls 一致
+func MulticallBatched(ctx context.Context, multicall common.Address, calls []MulticallCall, batchSize int) ([]MulticallResult, error) {
+	if batchSize <= 0 || len(calls) <= batchSize {
+		return Multicall(ctx, multicall, calls)
+	}
+
+	results := make([]MulticallResult, 0, len(calls))
+	for start := 0; start < len(calls); start += batchSize {
+		end := min(start+batchSize, len(calls))
+		batch, err := Multicall(ctx, multicall, calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+	}
+	return results, nil
+}
+
+// 构造通过 Multicall3.getEthBalance 查询 ETH 余额的子调用
+func EthBalanceCall(multicall, address common.Address) MulticallCall {
+	data, _ := Multicall3ABI.Pack("getEthBalance", address)
+	return MulticallCall{Target: multicall, AllowFailure: true, CallData: data}
+}
+
+// 构造查询 address 在各代币合约中 balanceOf 的子调用，单个代币失败不影响其他代币
+func BalanceOfCalls(tokens []common.Address, address common.Address) ([]MulticallCall, error) {
+	parsedAbi, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 ERC20 ABI 失败: %v", err)
+	}
+	data, err := parsedAbi.Pack("balanceOf", address)
+	if err != nil {
+		return nil, err
+	}
+
+	calls := make([]MulticallCall, 0, len(tokens))
+	for _, token := range tokens {
+		calls = append(calls, MulticallCall{Target: token, AllowFailure: true, CallData: data})
+	}
+	return calls, nil
+}
+
+// 解析返回单个 uint256 的子调用结果，调用失败或返回值无效时返回 nil
+func DecodeUint256Result(result MulticallResult) *big.Int {
+	if !result.Success || len(result.ReturnData) < 32 {
+		return nil
+	}
+	return new(big.Int).SetBytes(result.ReturnData[:32])
+}
@@ -0,0 +1,191 @@
+package util
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"strings"
+	"sync"
+)
+
+// 随服务发布的常用函数和事件签名
+//
+//go:embed signatures.txt
+var builtinSignatureText string
+
+// 内置签名库：按 4 字节选择器索引函数，按 topic0 索引事件
+type signatureDB struct {
+	methods map[[4]byte][]*abi.Method
+	events  map[common.Hash][]*abi.Event
+}
+
+// 首次使用时解析内置签名库，签名文件随代码发布并由测试校验，解析失败属于程序错误
+var builtinSignatures = sync.OnceValue(func() *signatureDB {
+	db, err := parseSignatureDB(builtinSignatureText)
+	if err != nil {
+		panic(fmt.Sprintf("内置签名库无法解析: %v", err))
+	}
+	return db
+})
+
+// 按调用数据的前 4 字节查找内置签名库中的函数，可能有多个候选
+func BuiltinMethods(input []byte) []*abi.Method {
+	if len(input) < 4 {
+		return nil
+	}
+	return builtinSignatures().methods[[4]byte(input[:4])]
+}
+
+// 按 topic0 查找内置签名库中的事件，indexed 参数不同的同名事件（如 ERC20 和 ERC721 的 Transfer）都会返回
+func BuiltinEvents(topic0 common.Hash) []*abi.Event {
+	return builtinSignatures().events[topic0]
+}
+
+// 解析签名库文本：每行一个 function 或 event 签名，# 开头为注释
+func parseSignatureDB(text string) (*signatureDB, error) {
+	db := &signatureDB{
+		methods: make(map[[4]byte][]*abi.Method),
+		events:  make(map[common.Hash][]*abi.Event),
+	}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, signature, _ := strings.Cut(line, " ")
+		name, inputs, err := parseSignature(strings.TrimSpace(signature))
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行 %q: %v", i+1, line, err)
+		}
+
+		switch kind {
+		case "function":
+			for _, input := range inputs {
+				if input.Indexed {
+					return nil, fmt.Errorf("第 %d 行 %q: 函数参数不能是 indexed", i+1, line)
+				}
+			}
+			method := abi.NewMethod(name, name, abi.Function, "nonpayable", false, false, inputs, nil)
+			selector := [4]byte(method.ID)
+			db.methods[selector] = append(db.methods[selector], &method)
+		case "event":
+			event := abi.NewEvent(name, name, false, inputs)
+			db.events[event.ID] = append(db.events[event.ID], &event)
+		default:
+			return nil, fmt.Errorf("第 %d 行 %q: 未知的签名类型 %s", i+1, line, kind)
+		}
+	}
+	return db, nil
+}
+
+// 解析 name(类型 [indexed] 参数名, ...) 形式的签名
+func parseSignature(signature string) (string, abi.Arguments, error) {
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return "", nil, fmt.Errorf("签名格式错误")
+	}
+	name := signature[:open]
+
+	var inputs abi.Arguments
+	for _, field := range splitParams(signature[open+1 : len(signature)-1]) {
+		param, err := parseParam(field)
+		if err != nil {
+			return "", nil, err
+		}
+		typ, err := abi.NewType(param.Type, "", param.Components)
+		if err != nil {
+			return "", nil, fmt.Errorf("参数类型 %s 无效: %v", param.Type, err)
+		}
+		inputs = append(inputs, abi.Argument{Name: param.Name, Type: typ, Indexed: param.Indexed})
+	}
+	return name, inputs, nil
+}
+
+// 解析单个参数，结构体类型写作 (类型 字段名, ...)，后面可以跟数组后缀
+func parseParam(field string) (abi.ArgumentMarshaling, error) {
+	field = strings.TrimSpace(field)
+	var param abi.ArgumentMarshaling
+	var rest string
+
+	if strings.HasPrefix(field, "(") {
+		end := closingParen(field)
+		if end < 0 {
+			return param, fmt.Errorf("参数 %q 括号不匹配", field)
+		}
+		for _, componentField := range splitParams(field[1:end]) {
+			component, err := parseParam(componentField)
+			if err != nil {
+				return param, err
+			}
+			if component.Name == "" {
+				return param, fmt.Errorf("结构体 %q 的字段必须命名", field)
+			}
+			param.Components = append(param.Components, component)
+		}
+		suffix, remainder, _ := strings.Cut(field[end+1:], " ")
+		param.Type = "tuple" + suffix
+		rest = remainder
+	} else {
+		typ, remainder, _ := strings.Cut(field, " ")
+		if typ == "" {
+			return param, fmt.Errorf("参数类型为空")
+		}
+		param.Type = typ
+		rest = remainder
+	}
+
+	words := strings.Fields(rest)
+	if len(words) > 0 && words[0] == "indexed" {
+		param.Indexed = true
+		words = words[1:]
+	}
+	switch len(words) {
+	case 0:
+	case 1:
+		param.Name = words[0]
+	default:
+		return param, fmt.Errorf("参数 %q 格式错误", field)
+	}
+	return param, nil
+}
+
+// 按最外层的逗号拆分参数列表
+func splitParams(params string) []string {
+	if strings.TrimSpace(params) == "" {
+		return nil
+	}
+	var fields []string
+	depth, start := 0, 0
+	for i, ch := range params {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				fields = append(fields, params[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, params[start:])
+}
+
+// 与开头的左括号匹配的右括号位置，不匹配时返回 -1
+func closingParen(s string) int {
+	depth := 0
+	for i, ch := range s {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
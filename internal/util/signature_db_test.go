@@ -0,0 +1,88 @@
+package util
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinSignatures(t *testing.T) {
+	if _, err := parseSignatureDB(builtinSignatureText); err != nil {
+		t.Fatalf("内置签名库无法解析: %v", err)
+	}
+
+	methods := BuiltinMethods(common.FromHex("0xa9059cbb"))
+	if len(methods) != 1 || methods[0].Sig != "transfer(address,uint256)" {
+		t.Errorf("选择器 0xa9059cbb 匹配到 %v", methods)
+	}
+	// ERC20 和 ERC721 的 Transfer 共用 topic0
+	if events := BuiltinEvents(crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))); len(events) != 2 {
+		t.Errorf("Transfer 匹配到 %d 个事件，期望 2 个", len(events))
+	}
+
+	// 结构体参数：Uniswap V3 exactInputSingle
+	methods = BuiltinMethods(common.FromHex("0x414bf389"))
+	if len(methods) != 1 {
+		t.Fatalf("选择器 0x414bf389 匹配到 %d 个函数，期望 1 个", len(methods))
+	}
+	tokenIn := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	tokenOut := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	params := struct {
+		TokenIn           common.Address
+		TokenOut          common.Address
+		Fee               *big.Int
+		Recipient         common.Address
+		Deadline          *big.Int
+		AmountIn          *big.Int
+		AmountOutMinimum  *big.Int
+		SqrtPriceLimitX96 *big.Int
+	}{tokenIn, tokenOut, big.NewInt(3000), tokenOut, big.NewInt(99), big.NewInt(1000), big.NewInt(990), big.NewInt(0)}
+	packed, err := methods[0].Inputs.Pack(params)
+	if err != nil {
+		t.Fatalf("编码调用参数失败: %v", err)
+	}
+	decoded, err := DecodeMethodInput(methods[0], append(methods[0].ID, packed...))
+	if err != nil {
+		t.Fatalf("解码调用数据失败: %v", err)
+	}
+	want := map[string]interface{}{"params": map[string]interface{}{
+		"tokenIn":           tokenIn.Hex(),
+		"tokenOut":          tokenOut.Hex(),
+		"fee":               "3000",
+		"recipient":         tokenOut.Hex(),
+		"deadline":          "99",
+		"amountIn":          "1000",
+		"amountOutMinimum":  "990",
+		"sqrtPriceLimitX96": "0",
+	}}
+	if !reflect.DeepEqual(decoded.Args, want) {
+		t.Errorf("exactInputSingle 解码为 %v，期望 %v", decoded.Args, want)
+	}
+}
+
+func TestParseSignatureDBRejectsMalformedLines(t *testing.T) {
+	for _, line := range []string{
+		"function transfer(address to, uint256 value",
+		"function transfer(address indexed to)",
+		"function aggregate((address, bytes)[] calls)",
+		"event Transfer(address from to)",
+		"error Unauthorized()",
+		"function foo(uint256[ x)",
+	} {
+		if _, err := parseSignatureDB(line); err == nil {
+			t.Errorf("签名 %q 应解析失败", line)
+		}
+	}
+
+	db, err := parseSignatureDB("# 注释\n\nevent Swap(address indexed sender, (uint256 a, int24 b)[] legs)\n")
+	if err != nil {
+		t.Fatalf("解析签名失败: %v", err)
+	}
+	for _, events := range db.events {
+		if len(events) != 1 || events[0].Sig != "Swap(address,(uint256,int24)[])" {
+			t.Errorf("事件签名为 %v", events)
+		}
+	}
+}
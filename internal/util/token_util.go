@@ -0,0 +1,131 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+// ERC20 元数据方法的 ABI，name / symbol 按 string 声明，bytes32 变体在解码时单独处理
+const erc20MetadataABI = `[
+    {"constant": true, "inputs": [], "name": "name", "outputs": [{"name": "", "type": "string"}], "type": "function"},
+    {"constant": true, "inputs": [], "name": "symbol", "outputs": [{"name": "", "type": "string"}], "type": "function"},
+    {"constant": true, "inputs": [], "name": "decimals", "outputs": [{"name": "", "type": "uint8"}], "type": "function"},
+    {"constant": true, "inputs": [], "name": "totalSupply", "outputs": [{"name": "", "type": "uint256"}], "type": "function"}
+]`
+
+// TokenMetadata 代币元数据，合约未实现的方法对应字段为空
+type TokenMetadata struct {
+	Name        string
+	Symbol      string
+	Decimals    *int
+	TotalSupply *big.Int
+}
+
+// 在一个批量 JSON-RPC 请求中查询代币的 name、symbol、decimals 和 totalSupply。
+// 单个方法调用失败（合约未实现或 revert）只会让对应字段为空，整个批量请求失败才返回错误。
+func GetTokenMetadata(ctx context.Context, contract common.Address) (*TokenMetadata, error) {
+	parsedAbi, err := abi.JSON(strings.NewReader(erc20MetadataABI))
+	if err != nil {
+		return nil, fmt.Errorf("解析 ERC20 ABI 失败: %v", err)
+	}
+
+	methods := []string{"name", "symbol", "decimals", "totalSupply"}
+	results := make([]hexutil.Bytes, len(methods))
+	batch := make([]rpc.BatchElem, len(methods))
+	for i, method := range methods {
+		data, err := parsedAbi.Pack(method)
+		if err != nil {
+			return nil, err
+		}
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":    contract,
+				"input": hexutil.Bytes(data),
+			}, "latest"},
+			Result: &results[i],
+		}
+	}
+
+	if err := EthClient.Client().BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("查询代币 %s 元数据失败: %v", contract.Hex(), err)
+	}
+
+	meta := &TokenMetadata{}
+	if batch[0].Error == nil {
+		meta.Name = decodeTokenString(results[0])
+	}
+	if batch[1].Error == nil {
+		meta.Symbol = decodeTokenString(results[1])
+	}
+	if batch[2].Error == nil && len(results[2]) >= 32 {
+		// decimals 按 uint8 声明，但少数合约返回 uint256，超出范围视为无效
+		if d := new(big.Int).SetBytes(results[2][:32]); d.IsUint64() && d.Uint64() <= 255 {
+			decimals := int(d.Uint64())
+			meta.Decimals = &decimals
+		}
+	}
+	if batch[3].Error == nil && len(results[3]) >= 32 {
+		meta.TotalSupply = new(big.Int).SetBytes(results[3][:32])
+	}
+	return meta, nil
+}
+
+// 解码 name / symbol 返回值：标准合约返回 ABI 编码的 string，早期合约（如 MKR）返回 bytes32
+func decodeTokenString(data []byte) string {
+	var s string
+	switch {
+	case len(data) == 32:
+		s = strings.TrimRight(string(data), "\x00")
+	case len(data) >= 64:
+		stringType, _ := abi.NewType("string", "", nil)
+		values, err := abi.Arguments{{Type: stringType}}.Unpack(data)
+		if err != nil {
+			return ""
+		}
+		s, _ = values[0].(string)
+	}
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+	return strings.TrimSpace(s)
+}
+
+// 按小数位数把最小单位的整数转换为十进制字符串，例如 FormatUnits("1500000", 6) = "1.5"。
+// amount 可以带小数部分（decimal 列读出的值），小数部分会被忽略。
+func FormatUnits(amount string, decimals int) string {
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		amount = amount[:i]
+	}
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return amount
+	}
+	if decimals <= 0 {
+		return value.String()
+	}
+
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+		value.Neg(value)
+	}
+	digits := value.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	intPart := digits[:len(digits)-decimals]
+	fracPart := strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + "." + fracPart
+}
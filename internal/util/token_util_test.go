@@ -0,0 +1,48 @@
+package util
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"testing"
+)
+
+func TestFormatUnits(t *testing.T) {
+	cases := []struct {
+		amount   string
+		decimals int
+		want     string
+	}{
+		{"1500000", 6, "1.5"},
+		{"1000000000000000000", 18, "1"},
+		{"42", 18, "0.000000000000000042"},
+		{"1000.000000000000000000000000000000", 2, "10"},
+		{"0", 18, "0"},
+		{"123", 0, "123"},
+		{"-2500", 3, "-2.5"},
+	}
+	for _, c := range cases {
+		if got := FormatUnits(c.amount, c.decimals); got != c.want {
+			t.Errorf("FormatUnits(%q, %d) = %q，期望 %q", c.amount, c.decimals, got, c.want)
+		}
+	}
+}
+
+func TestDecodeTokenString(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	encoded, err := abi.Arguments{{Type: stringType}}.Pack("USD Coin")
+	if err != nil {
+		t.Fatalf("编码字符串失败: %v", err)
+	}
+	if got := decodeTokenString(encoded); got != "USD Coin" {
+		t.Errorf("解码 string 返回值为 %q，期望 USD Coin", got)
+	}
+
+	var bytes32 [32]byte
+	copy(bytes32[:], "MKR")
+	if got := decodeTokenString(bytes32[:]); got != "MKR" {
+		t.Errorf("解码 bytes32 返回值为 %q，期望 MKR", got)
+	}
+
+	if got := decodeTokenString(nil); got != "" {
+		t.Errorf("解码空返回值为 %q，期望空字符串", got)
+	}
+}
@@ -0,0 +1,195 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"strings"
+)
+
+// TraceCall 交易调用树中的一个调用帧，callTracer 和 trace_block 的结果统一转换为该格式
+type TraceCall struct {
+	TxHash       common.Hash
+	TraceAddress []int  // 在交易调用树中的路径，顶层调用为空
+	Type         string // call / delegatecall / staticcall / callcode / create / create2 / selfdestruct
+	From         common.Address
+	To           common.Address // create 为新合约地址，selfdestruct 为接收余额的地址
+	Value        *big.Int
+	// 该调用或其上层调用失败，状态变更已回滚
+	Reverted bool
+}
+
+// debug_traceBlockByNumber 返回的单笔交易结果
+type txTraceResult struct {
+	TxHash common.Hash `json:"txHash"`
+	Result *callFrame  `json:"result"`
+	Error  string      `json:"error"`
+}
+
+// callTracer 的调用帧
+type callFrame struct {
+	Type  string          `json:"type"`
+	From  common.Address  `json:"from"`
+	To    *common.Address `json:"to"`
+	Value *hexutil.Big    `json:"value"`
+	Error string          `json:"error"`
+	Calls []callFrame     `json:"calls"`
+}
+
+// 通过 debug_traceBlockByNumber（callTracer）获取区块内全部调用帧。
+// 旧版本节点的结果不带 txHash，按顺序与 txHashes 对应
+func TraceBlockByCallTracer(ctx context.Context, number *big.Int, txHashes []common.Hash) ([]TraceCall, error) {
+	var results []txTraceResult
+	err := EthClient.Client().CallContext(ctx, &results, "debug_traceBlockByNumber",
+		hexutil.EncodeBig(number), map[string]interface{}{"tracer": "callTracer"})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(txHashes) {
+		return nil, fmt.Errorf("追踪结果数量 %d 与交易数量 %d 不一致", len(results), len(txHashes))
+	}
+
+	var calls []TraceCall
+	for i, result := range results {
+		if result.Error != "" || result.Result == nil {
+			return nil, fmt.Errorf("追踪交易 %s 失败: %s", txHashes[i].Hex(), result.Error)
+		}
+		calls = flattenCallFrame(calls, txHashes[i], result.Result, nil, false)
+	}
+	return calls, nil
+}
+
+// 按深度优先顺序展开调用帧，失败调用的子调用同样标记为已回滚
+func flattenCallFrame(calls []TraceCall, txHash common.Hash, frame *callFrame, traceAddress []int, reverted bool) []TraceCall {
+	reverted = reverted || frame.Error != ""
+	call := TraceCall{
+		TxHash:       txHash,
+		TraceAddress: traceAddress,
+		Type:         strings.ToLower(frame.Type),
+		From:         frame.From,
+		Value:        new(big.Int),
+		Reverted:     reverted,
+	}
+	if frame.To != nil {
+		call.To = *frame.To
+	}
+	if frame.Value != nil {
+		call.Value = frame.Value.ToInt()
+	}
+	calls = append(calls, call)
+
+	for i := range frame.Calls {
+		child := append(append([]int{}, traceAddress...), i)
+		calls = flattenCallFrame(calls, txHash, &frame.Calls[i], child, reverted)
+	}
+	return calls
+}
+
+// trace_block 返回的单条追踪记录
+type parityTrace struct {
+	Action struct {
+		CallType       string          `json:"callType"`
+		CreationMethod string          `json:"creationMethod"`
+		From           common.Address  `json:"from"`
+		To             *common.Address `json:"to"`
+		Value          *hexutil.Big    `json:"value"`
+		// selfdestruct（suicide）的合约地址、接收地址和余额
+		Address       common.Address `json:"address"`
+		RefundAddress common.Address `json:"refundAddress"`
+		Balance       *hexutil.Big   `json:"balance"`
+	} `json:"action"`
+	Result *struct {
+		Address *common.Address `json:"address"`
+	} `json:"result"`
+	Error           string       `json:"error"`
+	TraceAddress    []int        `json:"traceAddress"`
+	TransactionHash *common.Hash `json:"transactionHash"`
+	Type            string       `json:"type"`
+}
+
+// 通过 trace_block（Erigon / Nethermind / Reth）获取区块内全部调用帧
+func TraceBlockByParity(ctx context.Context, number *big.Int) ([]TraceCall, error) {
+	var traces []parityTrace
+	if err := EthClient.Client().CallContext(ctx, &traces, "trace_block", hexutil.EncodeBig(number)); err != nil {
+		return nil, err
+	}
+
+	var calls []TraceCall
+	// 同一交易中已失败调用的路径，追踪记录按深度优先排列，子调用总在父调用之后
+	var failed [][]int
+	var lastTx common.Hash
+	for _, trace := range traces {
+		// 区块奖励等记录不属于任何交易
+		if trace.TransactionHash == nil {
+			continue
+		}
+		if *trace.TransactionHash != lastTx {
+			lastTx, failed = *trace.TransactionHash, nil
+		}
+
+		call := TraceCall{
+			TxHash:       *trace.TransactionHash,
+			TraceAddress: trace.TraceAddress,
+			Value:        new(big.Int),
+		}
+		switch trace.Type {
+		case "call":
+			call.Type = trace.Action.CallType
+			call.From = trace.Action.From
+			if trace.Action.To != nil {
+				call.To = *trace.Action.To
+			}
+			if trace.Action.Value != nil {
+				call.Value = trace.Action.Value.ToInt()
+			}
+		case "create":
+			call.Type = "create"
+			if trace.Action.CreationMethod != "" {
+				call.Type = trace.Action.CreationMethod
+			}
+			call.From = trace.Action.From
+			if trace.Result != nil && trace.Result.Address != nil {
+				call.To = *trace.Result.Address
+			}
+			if trace.Action.Value != nil {
+				call.Value = trace.Action.Value.ToInt()
+			}
+		case "suicide":
+			call.Type = "selfdestruct"
+			call.From = trace.Action.Address
+			call.To = trace.Action.RefundAddress
+			if trace.Action.Balance != nil {
+				call.Value = trace.Action.Balance.ToInt()
+			}
+		default:
+			continue
+		}
+
+		if trace.Error != "" {
+			failed = append(failed, trace.TraceAddress)
+		}
+		for _, prefix := range failed {
+			if isTracePrefix(prefix, trace.TraceAddress) {
+				call.Reverted = true
+				break
+			}
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// 判断 prefix 是否为 traceAddress 的前缀（含相等）
+func isTracePrefix(prefix, traceAddress []int) bool {
+	if len(prefix) > len(traceAddress) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != traceAddress[i] {
+			return false
+		}
+	}
+	return true
+}
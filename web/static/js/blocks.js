@@ -50,10 +50,10 @@ class BlockExplorer {
                 <td><a href="#" class="tx-hash" onclick="blockExplorer.showTransactionDetail('${tx.tx_hash}')">${this.formatHash(tx.tx_hash)}</a></td>
                 <td>${tx.block_number}</td>
                 <td><span class="address-hash" title="${tx.from_address}">${this.formatHash(tx.from_address)}</span></td>
-                <td><span class="address-hash" title="${tx.to_address ? tx.to_address : '合约创建'}">${tx.to_address ? this.formatHash(tx.to_address) : '合约创建'}</span></td>
-                <td class="amount-value">${tx.tx_type === 'erc20_transfer' ?  (tx.erc20_amount + 'Token') : (tx.value + 'ETH')}</td>
-                <td class="gas-fee">${this.calculateGasFee(tx.gas_price, tx.gas_used)} ETH</td>
-                <td><span class="transaction-type ${this.getTxTypeClass(tx.tx_type)}">${this.getTxTypeText(tx.tx_type)}</span></td>
+                <td>${this.formatToAddress(tx)}</td>
+                <td class="amount-value">${tx.tx_type === 'erc20_transfer' ? this.formatTokenAmount(tx) : (tx.value + 'ETH')}</td>
+                <td class="gas-fee">${this.formatWei(tx.fee)} ETH</td>
+                <td><span class="transaction-type ${this.getTxTypeClass(tx.tx_type)}">${this.getTxTypeText(tx.tx_type)}</span>${this.formatLabels(tx.labels)}</td>
                 <td><span class="${tx.status === 'success' ? 'status-success' : 'status-failed'}">${tx.status === 'success' ? '成功' : '失败'}</span></td>
                 <td>${tx.created_at ? this.formatDate(tx.created_at) : ''}</td>
             `;
@@ -116,17 +116,46 @@ class BlockExplorer {
         return `${hash.substring(0, 6)}...${hash.substring(hash.length - 4)}`;
     }
 
-    calculateGasFee(gasPrice, gasUsed) {
-        if (!gasPrice || !gasUsed) return '0';
-        // 简化处理，实际应该进行精确计算
-        return (parseFloat(gasPrice) * parseInt(gasUsed)).toFixed(8);
+    // 合约创建交易显示部署的合约地址
+    formatToAddress(tx) {
+        if (tx.to_address) {
+            return `<span class="address-hash" title="${tx.to_address}">${this.formatHash(tx.to_address)}</span>`;
+        }
+        if (tx.contract_address) {
+            return `<span class="address-hash" title="${tx.contract_address}">合约创建 ${this.formatHash(tx.contract_address)}</span>`;
+        }
+        return '<span class="address-hash">合约创建</span>';
+    }
+
+    // wei 金额换算为 ETH，保留 8 位小数
+    formatWei(wei) {
+        if (!wei) return '0';
+        const value = BigInt(wei.split('.')[0]);
+        const unit = 10n ** 18n;
+        const fraction = ((value % unit) * 10n ** 8n / unit).toString().padStart(8, '0');
+        return `${value / unit}.${fraction}`;
+    }
+
+    // 已登记代币元数据时显示换算后的金额和符号，否则显示原始金额
+    formatTokenAmount(tx) {
+        if (tx.erc20_formatted_amount) {
+            return `${tx.erc20_formatted_amount} ${tx.erc20_symbol || 'Token'}`;
+        }
+        return tx.erc20_amount + 'Token';
     }
 
     getTxTypeClass(type) {
         switch(type) {
             case 'eth_transfer': return 'eth-transfer';
             case 'erc20_transfer': return 'erc20-transfer';
+            case 'nft_transfer': return 'nft-transfer';
             case 'contract_call': return 'contract-call';
+            case 'weth_wrap':
+            case 'weth_unwrap': return 'weth-label';
+            case 'token_mint':
+            case 'nft_mint': return 'mint-label';
+            case 'token_burn': return 'burn-label';
+            case 'approval': return 'approval-label';
             default: return '';
         }
     }
@@ -135,11 +164,26 @@ class BlockExplorer {
         switch(type) {
             case 'eth_transfer': return 'ETH转账';
             case 'erc20_transfer': return 'ERC20转账';
+            case 'nft_transfer': return 'NFT转移';
             case 'contract_call': return '合约调用';
+            case 'weth_wrap': return 'WETH包装';
+            case 'weth_unwrap': return 'WETH解包';
+            case 'token_mint': return '代币铸造';
+            case 'token_burn': return '代币销毁';
+            case 'approval': return '授权';
+            case 'nft_mint': return 'NFT铸造';
             default: return type;
         }
     }
 
+    // 交易类型后依次显示交易标签
+    formatLabels(labels) {
+        if (!labels || labels.length === 0) return '';
+        return labels.map(label =>
+            ` <span class="transaction-type ${this.getTxTypeClass(label)}">${this.getTxTypeText(label)}</span>`
+        ).join('');
+    }
+
     formatDate(dateStr) {
         if (!dateStr) return '';
         const date = new Date(dateStr);